@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// clientAssertionType identifies a JWT client assertion (RFC 7523)
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// clientAssertionLifetime bounds how long a signed assertion is accepted
+const clientAssertionLifetime = 5 * time.Minute
+
+// WithPrivateKeyJWT authenticates to the token endpoint with a JWT signed by
+// the PEM-encoded RSA or P-256 key (private_key_jwt) instead of the client
+// secret. kid, when set, tells the provider which registered key to verify
+// with.
+func WithPrivateKeyJWT(keyPEM []byte, kid string) OAuth2Option {
+	return func(o *OAuth2ClientCredentials) {
+		key, err := parseAssertionKey(keyPEM)
+		if err != nil {
+			o.optionErr = err
+			return
+		}
+		o.assertionKey = key
+		o.assertionKID = kid
+	}
+}
+
+func parseAssertionKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse client assertion key: no PEM block found")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client assertion key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported client assertion key: only P-256 EC keys can sign ES256")
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported client assertion key type %T (use RSA or P-256)", key)
+}
+
+// buildClientAssertion signs a JWT naming clientID as issuer and subject and
+// the token endpoint as audience, with RS256 or ES256 depending on the key
+func buildClientAssertion(key crypto.Signer, kid, clientID, tokenURL string, now time.Time) (string, error) {
+	alg := "RS256"
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		alg = "ES256"
+	}
+
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("failed to generate assertion ID: %w", err)
+	}
+
+	claims := map[string]any{
+		"iss": clientID,
+		"sub": clientID,
+		"aud": tokenURL,
+		"iat": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+		"jti": hex.EncodeToString(jti),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode assertion header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode assertion claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		// JWS wants the raw r || s form, not ASN.1
+		r, s, signErr := ecdsa.Sign(rand.Reader, k, digest[:])
+		err = signErr
+		if err == nil {
+			signature = make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign client assertion: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifyJWT checks the signature of token against pub and returns its
+// decoded header and claims
+func verifyJWT(t *testing.T, token string, pub crypto.PublicKey) (map[string]any, map[string]any) {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a three-part JWT, got %q", token)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("Invalid signature encoding: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature); err != nil {
+			t.Fatalf("RS256 signature does not verify: %v", err)
+		}
+	case *ecdsa.PublicKey:
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if len(signature) != 64 || !ecdsa.Verify(k, digest[:], r, s) {
+			t.Fatal("ES256 signature does not verify")
+		}
+	}
+
+	decode := func(segment string) map[string]any {
+		data, err := base64.RawURLEncoding.DecodeString(segment)
+		if err != nil {
+			t.Fatalf("Invalid segment encoding: %v", err)
+		}
+		var out map[string]any
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Invalid segment JSON: %v", err)
+		}
+		return out
+	}
+	return decode(parts[0]), decode(parts[1])
+}
+
+func TestPrivateKeyJWT(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+
+	tests := []struct {
+		name   string
+		keyPEM []byte
+		pub    crypto.PublicKey
+		alg    string
+	}{
+		{"RS256", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), &rsaKey.PublicKey, "RS256"},
+		{"ES256", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), &ecKey.PublicKey, "ES256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				form = r.PostForm
+				json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+			}))
+			defer server.Close()
+
+			authenticator, err := NewOAuth2ClientCredentials("client-1", "", server.URL, nil, WithPrivateKeyJWT(tt.keyPEM, "key-7"))
+			if err != nil {
+				t.Fatalf("Failed to create authenticator: %v", err)
+			}
+
+			req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+			if err := authenticator.Apply(req); err != nil {
+				t.Fatalf("Failed to apply authentication: %v", err)
+			}
+
+			if got := form.Get("client_assertion_type"); got != clientAssertionType {
+				t.Errorf("Expected assertion type %q, got %q", clientAssertionType, got)
+			}
+			if form.Has("client_secret") {
+				t.Error("Expected no client_secret alongside the assertion")
+			}
+
+			header, claims := verifyJWT(t, form.Get("client_assertion"), tt.pub)
+			if header["alg"] != tt.alg || header["kid"] != "key-7" {
+				t.Errorf("Expected alg %s and kid key-7, got %v", tt.alg, header)
+			}
+			if claims["iss"] != "client-1" || claims["sub"] != "client-1" || claims["aud"] != server.URL {
+				t.Errorf("Expected iss/sub client-1 and aud %s, got %v", server.URL, claims)
+			}
+			if jti, _ := claims["jti"].(string); jti == "" {
+				t.Error("Expected a jti claim")
+			}
+			exp, _ := claims["exp"].(float64)
+			if remaining := time.Until(time.Unix(int64(exp), 0)); remaining <= 0 || remaining > clientAssertionLifetime {
+				t.Errorf("Expected exp within %s, got %s", clientAssertionLifetime, remaining)
+			}
+		})
+	}
+}
+
+func TestPrivateKeyJWTRejectsBadKeys(t *testing.T) {
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(p384)
+
+	for name, keyPEM := range map[string][]byte{
+		"Not PEM":     []byte("not a key"),
+		"Wrong curve": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+	} {
+		if _, err := NewOAuth2ClientCredentials("client", "", "https://auth.example.com/token", nil, WithPrivateKeyJWT(keyPEM, "")); err == nil {
+			t.Errorf("%s: expected the key to be rejected", name)
+		}
+	}
+}
@@ -1,7 +1,14 @@
 package auth
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 )
 
 type Authenticator interface {
@@ -9,18 +16,37 @@ type Authenticator interface {
 }
 
 type Config struct {
-	Username     string
-	Password     string
-	BearerToken  string
-	ClientID     string
-	ClientSecret string
-	TokenURL     string
-	Scopes       []string
-	CustomHeader string
-	CustomValue  string
+	Username         string
+	Password         string
+	Digest           bool
+	BearerToken      string
+	BearerCommand    string
+	ClientID         string
+	ClientSecret     string
+	TokenURL         string
+	AuthCode         bool
+	AuthURL          string
+	RedirectPort     int
+	TokenCache       string
+	Prompt           io.Writer
+	TokenCertFile    string
+	TokenKeyFile     string
+	TokenCAFile      string
+	TokenInsecure    bool
+	AssertionKeyFile string
+	AssertionKID     string
+	Scopes           []string
+	Audience         string
+	TokenParams      []string
+	CustomHeader     string
+	CustomValue      string
 }
 
 func NewAuthenticator(config Config) (Authenticator, error) {
+	if config.Digest {
+		return NewDigestAuth(config.Username, config.Password), nil
+	}
+
 	if config.Username != "" || config.Password != "" {
 		return NewBasicAuth(config.Username, config.Password), nil
 	}
@@ -29,8 +55,73 @@ func NewAuthenticator(config Config) (Authenticator, error) {
 		return NewBearerAuth(config.BearerToken), nil
 	}
 	
-	if config.ClientID != "" && config.ClientSecret != "" && config.TokenURL != "" {
-		return NewOAuth2ClientCredentials(config.ClientID, config.ClientSecret, config.TokenURL, config.Scopes)
+	if config.BearerCommand != "" {
+		return NewCommandBearerAuth(config.BearerCommand), nil
+	}
+	
+	// The token endpoint is verified like the server: against --cacert
+	// when it's given, and not at all with --insecure
+	var tokenCAs *x509.CertPool
+	if config.TokenCAFile != "" {
+		caPEM, err := os.ReadFile(config.TokenCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token endpoint CA file: %w", err)
+		}
+		tokenCAs = x509.NewCertPool()
+		if !tokenCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", config.TokenCAFile)
+		}
+	}
+
+	if config.AuthCode {
+		opts := []AuthCodeOption{WithRedirectPort(config.RedirectPort), WithTokenCache(config.TokenCache), WithPrompt(config.Prompt)}
+		if tokenCAs != nil || config.TokenInsecure {
+			opts = append(opts, WithAuthCodeTLS(&tls.Config{RootCAs: tokenCAs, InsecureSkipVerify: config.TokenInsecure}))
+		}
+		return NewOAuth2AuthCode(config.ClientID, config.ClientSecret, config.AuthURL, config.TokenURL, config.Scopes, opts...)
+	}
+
+	if config.ClientID != "" && (config.ClientSecret != "" || config.TokenCertFile != "" || config.AssertionKeyFile != "") && config.TokenURL != "" {
+		var opts []OAuth2Option
+		if config.Audience != "" {
+			opts = append(opts, WithAudience(config.Audience))
+		}
+		if len(config.TokenParams) > 0 {
+			params := url.Values{}
+			for _, param := range config.TokenParams {
+				key, value, found := strings.Cut(param, "=")
+				if !found || key == "" {
+					return nil, fmt.Errorf("invalid OAuth2 token parameter %q (use key=value)", param)
+				}
+				params.Add(key, value)
+			}
+			opts = append(opts, WithTokenParams(params))
+		}
+		if config.TokenCertFile != "" {
+			keyFile := config.TokenKeyFile
+			if keyFile == "" {
+				keyFile = config.TokenCertFile
+			}
+			cert, err := tls.LoadX509KeyPair(config.TokenCertFile, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load token client certificate: %w", err)
+			}
+			opts = append(opts, WithTokenClientCert(cert))
+		}
+		if tokenCAs != nil {
+			opts = append(opts, WithTokenRootCAs(tokenCAs))
+		}
+		if config.TokenInsecure {
+			opts = append(opts, WithTokenInsecure())
+		}
+		if config.AssertionKeyFile != "" {
+			keyPEM, err := os.ReadFile(config.AssertionKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read client assertion key: %w", err)
+			}
+			opts = append(opts, WithPrivateKeyJWT(keyPEM, config.AssertionKID))
+		}
+		return NewOAuth2ClientCredentials(config.ClientID, config.ClientSecret, config.TokenURL, config.Scopes, opts...)
 	}
 	
 	if config.CustomHeader != "" && config.CustomValue != "" {
@@ -0,0 +1,386 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2AuthCode gets a token with the authorization code flow (RFC 6749
+// section 4.1) and PKCE (RFC 7636): the user signs in through the browser,
+// which is sent back to a temporary listener on localhost with the code.
+// Tokens are cached on disk and refreshed with the refresh token, so the
+// browser is only needed when there is no usable token.
+type OAuth2AuthCode struct {
+	clientID     string
+	clientSecret string
+	authURL      string
+	tokenURL     string
+	scopes       []string
+	redirectPort int
+	cacheFile    string
+	timeout      time.Duration
+	prompt       io.Writer
+	openBrowser  func(string) error
+	client       *http.Client
+
+	mutex sync.Mutex
+	token *cachedToken
+}
+
+// cachedToken is a token as stored in the cache file
+type cachedToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+func (t *cachedToken) valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
+
+type AuthCodeOption func(*OAuth2AuthCode)
+
+// WithRedirectPort listens for the callback on port instead of a free one,
+// for providers that only accept a registered redirect URI
+func WithRedirectPort(port int) AuthCodeOption {
+	return func(o *OAuth2AuthCode) {
+		o.redirectPort = port
+	}
+}
+
+// WithTokenCache stores tokens in path rather than the default cache file.
+// An empty path turns caching off.
+func WithTokenCache(path string) AuthCodeOption {
+	return func(o *OAuth2AuthCode) {
+		o.cacheFile = path
+	}
+}
+
+// WithBrowser replaces how the authorization URL is opened
+func WithBrowser(open func(authURL string) error) AuthCodeOption {
+	return func(o *OAuth2AuthCode) {
+		o.openBrowser = open
+	}
+}
+
+// WithPrompt writes the sign-in instructions to w instead of os.Stderr
+func WithPrompt(w io.Writer) AuthCodeOption {
+	return func(o *OAuth2AuthCode) {
+		if w != nil {
+			o.prompt = w
+		}
+	}
+}
+
+// WithAuthCodeTLS connects to the token endpoint with config, e.g. to trust
+// a private CA
+func WithAuthCodeTLS(config *tls.Config) AuthCodeOption {
+	return func(o *OAuth2AuthCode) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config
+		o.client.Transport = transport
+	}
+}
+
+// DefaultTokenCache returns ~/.go-http-client/tokens.json
+func DefaultTokenCache() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".go-http-client", "tokens.json")
+}
+
+func NewOAuth2AuthCode(clientID, clientSecret, authURL, tokenURL string, scopes []string, opts ...AuthCodeOption) (*OAuth2AuthCode, error) {
+	if clientID == "" || authURL == "" || tokenURL == "" {
+		return nil, fmt.Errorf("clientID, authURL and tokenURL are required for the authorization code flow")
+	}
+
+	o := &OAuth2AuthCode{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		authURL:      authURL,
+		tokenURL:     tokenURL,
+		scopes:       scopes,
+		cacheFile:    DefaultTokenCache(),
+		timeout:      5 * time.Minute,
+		prompt:       os.Stderr,
+		openBrowser:  openBrowser,
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o, nil
+}
+
+// Apply signs in or refreshes the token when needed. Both are bound to the
+// request's context, so cancelling it abandons the browser sign-in.
+func (o *OAuth2AuthCode) Apply(req *http.Request) error {
+	token, err := o.getValidToken(req.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get OAuth2 token: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// getValidToken returns the access token, trying in turn the one in
+// memory, the cache file, the refresh token and finally the browser
+func (o *OAuth2AuthCode) getValidToken(ctx context.Context) (string, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.token == nil {
+		o.token = o.loadCachedToken()
+	}
+	if o.token.valid() {
+		return o.token.AccessToken, nil
+	}
+
+	var token *cachedToken
+	if o.token != nil && o.token.RefreshToken != "" {
+		// A refresh token the server rejects means signing in again
+		token, _ = o.refresh(ctx, o.token.RefreshToken)
+	}
+	if token == nil {
+		var err error
+		if token, err = o.authorize(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	o.token = token
+	o.saveCachedToken(token)
+	return token.AccessToken, nil
+}
+
+// authorize runs the interactive part of the flow
+func (o *OAuth2AuthCode) authorize(ctx context.Context) (*cachedToken, error) {
+	verifier := randomToken(32)
+	state := randomToken(16)
+	challenge := sha256.Sum256([]byte(verifier))
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(o.redirectPort)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the callback listener: %w", err)
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	authURL, err := url.Parse(o.authURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization URL: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", o.clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if len(o.scopes) > 0 {
+		query.Set("scope", strings.Join(o.scopes, " "))
+	}
+	authURL.RawQuery = query.Encode()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		params := r.URL.Query()
+		var res result
+		switch {
+		case params.Get("state") != state:
+			res.err = errors.New("the authorization response had the wrong state")
+		case params.Get("error") != "":
+			res.err = fmt.Errorf("authorization failed: %s %s", params.Get("error"), params.Get("error_description"))
+		case params.Get("code") == "":
+			res.err = errors.New("the authorization response had no code")
+		default:
+			res.code = params.Get("code")
+		}
+
+		message := "Signed in. You can close this window."
+		if res.err != nil {
+			message = res.err.Error()
+		}
+		fmt.Fprintf(w, "<!doctype html><title>go-http-client</title><p>%s</p>\n", html.EscapeString(message))
+		select {
+		case results <- res:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Fprintf(o.prompt, "Open this URL in a browser to sign in:\n\n  %s\n\n", authURL)
+	if err := o.openBrowser(authURL.String()); err != nil {
+		fmt.Fprintf(o.prompt, "Could not open a browser: %v\n", err)
+	}
+
+	var res result
+	select {
+	case res = <-results:
+	case <-time.After(o.timeout):
+		return nil, fmt.Errorf("no authorization response within %s", o.timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", res.code)
+	data.Set("redirect_uri", redirectURI)
+	data.Set("code_verifier", verifier)
+	return o.requestToken(ctx, data, "")
+}
+
+// refresh trades the refresh token for a new access token
+func (o *OAuth2AuthCode) refresh(ctx context.Context, refreshToken string) (*cachedToken, error) {
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	return o.requestToken(ctx, data, refreshToken)
+}
+
+// requestToken posts a token request, keeping previousRefresh when the
+// server doesn't issue a new refresh token
+func (o *OAuth2AuthCode) requestToken(ctx context.Context, data url.Values, previousRefresh string) (*cachedToken, error) {
+	data.Set("client_id", o.clientID)
+	if o.clientSecret != "" {
+		data.Set("client_secret", o.clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed with status: %s", resp.Status)
+	}
+
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+
+	token := &cachedToken{AccessToken: tokenResp.AccessToken, RefreshToken: tokenResp.RefreshToken}
+	if token.RefreshToken == "" {
+		token.RefreshToken = previousRefresh
+	}
+	if tokenResp.ExpiresIn > 0 {
+		// Refresh a minute early, or halfway through for short-lived tokens
+		margin := min(60, tokenResp.ExpiresIn/2)
+		token.Expiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn-margin) * time.Second)
+	}
+	return token, nil
+}
+
+// cacheKey identifies the tokens of one client, provider and scope set
+func (o *OAuth2AuthCode) cacheKey() string {
+	scopes := append([]string(nil), o.scopes...)
+	sort.Strings(scopes)
+	return strings.Join([]string{o.clientID, o.tokenURL, strings.Join(scopes, " ")}, "|")
+}
+
+func (o *OAuth2AuthCode) loadCachedToken() *cachedToken {
+	if o.cacheFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(o.cacheFile)
+	if err != nil {
+		return nil
+	}
+	var tokens map[string]*cachedToken
+	if json.Unmarshal(data, &tokens) != nil {
+		return nil
+	}
+	return tokens[o.cacheKey()]
+}
+
+// saveCachedToken writes token to the cache file, readable only by the
+// user. Failing to cache doesn't fail the request.
+func (o *OAuth2AuthCode) saveCachedToken(token *cachedToken) {
+	if o.cacheFile == "" {
+		return
+	}
+
+	tokens := make(map[string]*cachedToken)
+	if data, err := os.ReadFile(o.cacheFile); err == nil {
+		json.Unmarshal(data, &tokens)
+	}
+	tokens[o.cacheKey()] = token
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(o.cacheFile), 0700); err != nil {
+		return
+	}
+	os.WriteFile(o.cacheFile, data, 0600)
+}
+
+// openBrowser opens url with the platform's default handler
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// randomToken returns n random bytes, base64url encoded
+func randomToken(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// authCodeProvider is a fake authorization server. Its authorize endpoint
+// redirects straight back with a code, and its token endpoint checks the
+// PKCE verifier against the challenge.
+type authCodeProvider struct {
+	*httptest.Server
+	challenge   string
+	authorizes  atomic.Int32
+	refreshes   atomic.Int32
+	badState    bool
+	expiresIn   int
+	tokenPrefix string
+}
+
+func newAuthCodeProvider(t *testing.T) *authCodeProvider {
+	p := &authCodeProvider{expiresIn: 3600, tokenPrefix: "access"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		p.authorizes.Add(1)
+		q := r.URL.Query()
+		if q.Get("response_type") != "code" || q.Get("client_id") != "client" || q.Get("code_challenge_method") != "S256" || q.Get("scope") != "read write" {
+			t.Errorf("Unexpected authorization request %s", r.URL.RawQuery)
+		}
+		p.challenge = q.Get("code_challenge")
+		state := q.Get("state")
+		if p.badState {
+			state = "forged"
+		}
+		http.Redirect(w, r, q.Get("redirect_uri")+"?code=the-code&state="+url.QueryEscape(state), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+			if r.Form.Get("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token":"%s-1","refresh_token":"refresh","expires_in":%d}`, p.tokenPrefix, p.expiresIn)
+		case "refresh_token":
+			n := p.refreshes.Add(1)
+			if r.Form.Get("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token":"refreshed-%d","expires_in":3600}`, n)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// browser follows the authorization URL like a user who is already
+// signed in
+func browser(authURL string) error {
+	go func() {
+		resp, err := http.Get(authURL)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+	return nil
+}
+
+func newTestAuthCode(t *testing.T, p *authCodeProvider, cache string) *OAuth2AuthCode {
+	o, err := NewOAuth2AuthCode("client", "", p.URL+"/authorize", p.URL+"/token", []string{"read", "write"}, WithTokenCache(cache), WithBrowser(browser), WithPrompt(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.timeout = 5 * time.Second
+	return o
+}
+
+func TestOAuth2AuthCode(t *testing.T) {
+	p := newAuthCodeProvider(t)
+	cache := filepath.Join(t.TempDir(), "tokens", "tokens.json")
+
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	if err := newTestAuthCode(t, p, cache).Apply(req); err != nil {
+		t.Fatalf("Failed to apply auth: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer access-1" {
+		t.Errorf("Expected the exchanged token, got %q", got)
+	}
+
+	info, err := os.Stat(cache)
+	if err != nil {
+		t.Fatalf("Expected the token to be cached: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the cache to be private, got %v", info.Mode().Perm())
+	}
+
+	// A new run reuses the cached token without the browser
+	req, _ = http.NewRequest("GET", "https://api.example.com", nil)
+	if err := newTestAuthCode(t, p, cache).Apply(req); err != nil {
+		t.Fatalf("Failed to apply auth: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer access-1" || p.authorizes.Load() != 1 {
+		t.Errorf("Expected the cached token without signing in again, got %q after %d sign-ins", got, p.authorizes.Load())
+	}
+}
+
+func TestOAuth2AuthCodeRefresh(t *testing.T) {
+	p := newAuthCodeProvider(t)
+	cache := filepath.Join(t.TempDir(), "tokens.json")
+
+	o := newTestAuthCode(t, p, cache)
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	o.Apply(req)
+	o.token.Expiry = time.Now().Add(-time.Second)
+
+	for _, expected := range []string{"Bearer refreshed-1", "Bearer refreshed-1"} {
+		req, _ = http.NewRequest("GET", "https://api.example.com", nil)
+		if err := o.Apply(req); err != nil {
+			t.Fatalf("Failed to apply auth: %v", err)
+		}
+		if got := req.Header.Get("Authorization"); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	}
+	if p.authorizes.Load() != 1 || p.refreshes.Load() != 1 {
+		t.Errorf("Expected one sign-in and one refresh, got %d and %d", p.authorizes.Load(), p.refreshes.Load())
+	}
+
+	// The refreshed token keeps the old refresh token for next time
+	data, _ := os.ReadFile(cache)
+	if !strings.Contains(string(data), `"refresh_token": "refresh"`) {
+		t.Errorf("Expected the refresh token to be kept, got %s", data)
+	}
+}
+
+func TestOAuth2AuthCodeShortLivedToken(t *testing.T) {
+	p := newAuthCodeProvider(t)
+	p.expiresIn = 30
+
+	o := newTestAuthCode(t, p, "")
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+		if err := o.Apply(req); err != nil {
+			t.Fatalf("Failed to apply auth: %v", err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer access-1" {
+			t.Errorf("Expected the token to be used while it's valid, got %q", got)
+		}
+	}
+	if p.refreshes.Load() != 0 {
+		t.Errorf("Expected no refresh within the token's lifetime, got %d", p.refreshes.Load())
+	}
+}
+
+func TestOAuth2AuthCodeTLS(t *testing.T) {
+	p := newAuthCodeProvider(t)
+	p.Close()
+	p.Server = httptest.NewTLSServer(p.Config.Handler)
+	defer p.Close()
+
+	tests := []struct {
+		name string
+		opts []AuthCodeOption
+		ok   bool
+	}{
+		{"Untrusted", nil, false},
+		{"Trusted CA", []AuthCodeOption{WithAuthCodeTLS(&tls.Config{RootCAs: p.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs})}, true},
+		{"Insecure", []AuthCodeOption{WithAuthCodeTLS(&tls.Config{InsecureSkipVerify: true})}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]AuthCodeOption{WithTokenCache(""), WithBrowser(insecureBrowser), WithPrompt(io.Discard)}, tt.opts...)
+			o, _ := NewOAuth2AuthCode("client", "", p.URL+"/authorize", p.URL+"/token", []string{"read", "write"}, opts...)
+			o.timeout = 5 * time.Second
+
+			req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+			err := o.Apply(req)
+			if tt.ok && err != nil {
+				t.Errorf("Expected the token request to succeed, got %v", err)
+			}
+			if !tt.ok && (err == nil || !strings.Contains(err.Error(), "certificate")) {
+				t.Errorf("Expected a certificate error, got %v", err)
+			}
+		})
+	}
+}
+
+// insecureBrowser is browser for a provider with a self-signed certificate
+func insecureBrowser(authURL string) error {
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	go func() {
+		resp, err := client.Get(authURL)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+	return nil
+}
+
+func TestOAuth2AuthCodeStateMismatch(t *testing.T) {
+	p := newAuthCodeProvider(t)
+	p.badState = true
+
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	err := newTestAuthCode(t, p, "").Apply(req)
+	if err == nil || !strings.Contains(err.Error(), "wrong state") {
+		t.Errorf("Expected a state error, got %v", err)
+	}
+}
+
+func TestOAuth2AuthCodeCancel(t *testing.T) {
+	var prompt bytes.Buffer
+	opened := make(chan string, 1)
+	o, _ := NewOAuth2AuthCode("client", "", "https://auth.example.com/authorize", "https://auth.example.com/token", nil,
+		WithTokenCache(""), WithPrompt(&prompt), WithBrowser(func(authURL string) error {
+			opened <- authURL
+			return nil
+		}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-opened
+		cancel()
+	}()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://api.example.com", nil)
+	err := o.Apply(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the sign-in to be cancelled, got %v", err)
+	}
+	if !strings.Contains(prompt.String(), "https://auth.example.com/authorize?") {
+		t.Errorf("Expected the authorization URL in the prompt, got %q", prompt.String())
+	}
+}
+
+func TestOAuth2AuthCodeCacheKey(t *testing.T) {
+	a, _ := NewOAuth2AuthCode("client", "", "https://a/authorize", "https://a/token", []string{"write", "read"})
+	b, _ := NewOAuth2AuthCode("client", "", "https://a/authorize", "https://a/token", []string{"read", "write"})
+	c, _ := NewOAuth2AuthCode("client", "", "https://a/authorize", "https://a/token", []string{"read"})
+	if a.cacheKey() != b.cacheKey() {
+		t.Errorf("Expected scope order not to matter, got %q and %q", a.cacheKey(), b.cacheKey())
+	}
+	if a.cacheKey() == c.cacheKey() {
+		t.Errorf("Expected different scopes to get different tokens")
+	}
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultIMDSEndpoint is the EC2 instance metadata service
+const defaultIMDSEndpoint = "http://169.254.169.254"
+
+// AWSCredentials are the keys requests are signed with
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// AWSCredentialChain resolves credentials the way the AWS CLI does: from
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, then the AWS_PROFILE (or
+// default) profile in ~/.aws/credentials and ~/.aws/config, then the role
+// of the EC2 instance. Instance credentials are cached until shortly
+// before they expire.
+type AWSCredentialChain struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	cached *AWSCredentials
+}
+
+func NewAWSCredentialChain() *AWSCredentialChain {
+	return &AWSCredentialChain{client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (c *AWSCredentialChain) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && (c.cached.Expires.IsZero() || time.Until(c.cached.Expires) > 5*time.Minute) {
+		return *c.cached, nil
+	}
+
+	creds, err := c.resolve(ctx)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	c.cached = &creds
+	return creds, nil
+}
+
+func (c *AWSCredentialChain) resolve(ctx context.Context) (AWSCredentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return AWSCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	creds, found, err := sharedAWSCredentials()
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	if found {
+		return creds, nil
+	}
+
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return AWSCredentials{}, errors.New("no AWS credentials found in the environment or shared config files")
+	}
+	creds, err = c.instanceCredentials(ctx)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("no AWS credentials found in the environment or shared config files, and the instance metadata service failed: %w", err)
+	}
+	return creds, nil
+}
+
+// sharedAWSCredentials reads the profile's keys from the shared credentials
+// file, falling back to the config file, where profiles other than default
+// are named "profile NAME"
+func sharedAWSCredentials() (AWSCredentials, bool, error) {
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	home, _ := os.UserHomeDir()
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = filepath.Join(home, ".aws", "credentials")
+	}
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = filepath.Join(home, ".aws", "config")
+	}
+
+	configSection := "profile " + profile
+	if profile == "default" {
+		configSection = "default"
+	}
+
+	for _, source := range []struct{ path, section string }{
+		{credentialsFile, profile},
+		{configFile, configSection},
+	} {
+		values, err := readINISection(source.path, source.section)
+		if err != nil {
+			return AWSCredentials{}, false, err
+		}
+		if values["aws_access_key_id"] != "" && values["aws_secret_access_key"] != "" {
+			return AWSCredentials{
+				AccessKeyID:     values["aws_access_key_id"],
+				SecretAccessKey: values["aws_secret_access_key"],
+				SessionToken:    values["aws_session_token"],
+			}, true, nil
+		}
+	}
+	return AWSCredentials{}, false, nil
+}
+
+// readINISection returns the key = value pairs in [section] of an INI file.
+// A missing file has no sections.
+func readINISection(path, section string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS config: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+		case current == section:
+			if key, value, found := strings.Cut(line, "="); found {
+				values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read AWS config: %w", err)
+	}
+	return values, nil
+}
+
+// instanceCredentials fetches the instance role's credentials over IMDSv2.
+// AWS_EC2_METADATA_SERVICE_ENDPOINT overrides the endpoint, as in the SDKs.
+func (c *AWSCredentialChain) instanceCredentials(ctx context.Context) (AWSCredentials, error) {
+	endpoint := strings.TrimSuffix(os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"), "/")
+	if endpoint == "" {
+		endpoint = defaultIMDSEndpoint
+	}
+
+	token, err := c.metadata(ctx, http.MethodPut, endpoint+"/latest/api/token", map[string]string{
+		"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": "21600",
+	})
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	tokenHeader := map[string]string{"X-Aws-Ec2-Metadata-Token": token}
+
+	roles, err := c.metadata(ctx, http.MethodGet, endpoint+"/latest/meta-data/iam/security-credentials/", tokenHeader)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(roles), "\n")
+	if role == "" {
+		return AWSCredentials{}, errors.New("the instance has no IAM role")
+	}
+
+	document, err := c.metadata(ctx, http.MethodGet, endpoint+"/latest/meta-data/iam/security-credentials/"+role, tokenHeader)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	var result struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		Token           string    `json:"Token"`
+		Expiration      time.Time `json:"Expiration"`
+	}
+	if err := json.Unmarshal([]byte(document), &result); err != nil {
+		return AWSCredentials{}, fmt.Errorf("failed to parse instance credentials: %w", err)
+	}
+	return AWSCredentials{
+		AccessKeyID:     result.AccessKeyID,
+		SecretAccessKey: result.SecretAccessKey,
+		SessionToken:    result.Token,
+		Expires:         result.Expiration,
+	}, nil
+}
+
+func (c *AWSCredentialChain) metadata(ctx context.Context, method, url string, headers map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s returned %s", method, url, resp.Status)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+type CommandBearerAuth struct {
+	command string
+	token   string
+	mutex   sync.Mutex
+}
+
+func NewCommandBearerAuth(command string) *CommandBearerAuth {
+	return &CommandBearerAuth{
+		command: command,
+	}
+}
+
+func (c *CommandBearerAuth) Apply(req *http.Request) error {
+	token, err := c.getToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (c *CommandBearerAuth) getToken() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.token != "" {
+		return c.token, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", c.command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("bearer command %q failed: %w: %s", c.command, err, msg)
+		}
+		return "", fmt.Errorf("bearer command %q failed: %w", c.command, err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("bearer command %q produced no token", c.command)
+	}
+
+	c.token = token
+	return c.token, nil
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	return path
+}
+
+func TestCommandBearerAuth(t *testing.T) {
+	script := writeScript(t, `echo "  secret-token  "`)
+
+	authenticator, err := NewAuthenticator(Config{BearerCommand: script})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	if err := authenticator.Apply(req); err != nil {
+		t.Fatalf("Failed to apply authentication: %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer secret-token" {
+		t.Errorf("Expected 'Bearer secret-token', got %q", got)
+	}
+}
+
+func TestCommandBearerAuthCachesToken(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "count")
+	script := writeScript(t, `echo x >> "`+counter+`"; echo token`)
+
+	authenticator := NewCommandBearerAuth(script)
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		if err := authenticator.Apply(req); err != nil {
+			t.Fatalf("Failed to apply authentication: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatalf("Failed to read counter: %v", err)
+	}
+	if runs := strings.Count(string(data), "x"); runs != 1 {
+		t.Errorf("Expected command to run once, ran %d times", runs)
+	}
+}
+
+func TestCommandBearerAuthFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		contains string
+	}{
+		{"Non-zero exit", `echo "not logged in" >&2; exit 1`, "not logged in"},
+		{"Empty output", `true`, "produced no token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := NewCommandBearerAuth(writeScript(t, tt.script))
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+			err := authenticator.Apply(req)
+			if err == nil {
+				t.Fatal("Expected command failure to be reported")
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Expected error to contain %q, got: %v", tt.contains, err)
+			}
+			if req.Header.Get("Authorization") != "" {
+				t.Error("Authorization header should not be set on failure")
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DigestAuth implements HTTP Digest authentication (RFC 7616) with the MD5
+// and SHA-256 algorithms and qop=auth. The server's challenge is answered by
+// the transport returned from Transport; once one has been seen, Apply
+// answers it up front on later requests so they don't each start with a 401.
+type DigestAuth struct {
+	username string
+	password string
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+	count     int
+}
+
+// digestChallenge holds the parameters of a Digest WWW-Authenticate header
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+func NewDigestAuth(username, password string) *DigestAuth {
+	return &DigestAuth{
+		username: username,
+		password: password,
+	}
+}
+
+// Apply answers the last challenge received, if any
+func (d *DigestAuth) Apply(req *http.Request) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.challenge == nil {
+		return nil
+	}
+	return d.authorize(req)
+}
+
+// authorize sets the Authorization header for req from the current
+// challenge. d.mu must be held.
+func (d *DigestAuth) authorize(req *http.Request) error {
+	cnonce, err := newCnonce()
+	if err != nil {
+		return err
+	}
+	d.count++
+	req.Header.Set("Authorization", d.challenge.authorization(d.username, d.password, req.Method, req.URL.RequestURI(), d.count, cnonce))
+	return nil
+}
+
+// Transport wraps base so a 401 with a Digest challenge is answered by
+// sending the request again with credentials. A request whose body can't
+// be replayed gets the 401 back.
+func (d *DigestAuth) Transport(base http.RoundTripper) http.RoundTripper {
+	return &digestTransport{auth: d, base: base}
+}
+
+type digestTransport struct {
+	auth *DigestAuth
+	base http.RoundTripper
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := selectDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if challenge == nil || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+
+	t.auth.mu.Lock()
+	t.auth.challenge = challenge
+	t.auth.count = 0
+	err = t.auth.authorize(retry)
+	t.auth.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// authorization renders the Authorization header answering c
+func (c *digestChallenge) authorization(username, password, method, uri string, count int, cnonce string) string {
+	nc := fmt.Sprintf("%08x", count)
+	response := c.response(username, password, method, uri, nc, cnonce)
+
+	fields := []string{
+		"username=" + quote(username),
+		"realm=" + quote(c.realm),
+		"nonce=" + quote(c.nonce),
+		"uri=" + quote(uri),
+		"algorithm=" + c.algorithm,
+		"response=" + quote(response),
+	}
+	if c.opaque != "" {
+		fields = append(fields, "opaque="+quote(c.opaque))
+	}
+	if c.qop != "" {
+		fields = append(fields, "qop="+c.qop, "nc="+nc, "cnonce="+quote(cnonce))
+	}
+	return "Digest " + strings.Join(fields, ", ")
+}
+
+// response computes the request digest of RFC 7616 section 3.4.1, or that
+// of RFC 2069 when the server offered no qop
+func (c *digestChallenge) response(username, password, method, uri, nc, cnonce string) string {
+	h := c.hash
+	ha1 := h(username + ":" + c.realm + ":" + password)
+	if strings.HasSuffix(c.algorithm, "-sess") {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+
+	if c.qop == "" {
+		return h(ha1 + ":" + c.nonce + ":" + ha2)
+	}
+	return h(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":" + c.qop + ":" + ha2)
+}
+
+func (c *digestChallenge) hash(s string) string {
+	var h hash.Hash
+	if strings.HasPrefix(c.algorithm, "SHA-256") {
+		h = sha256.New()
+	} else {
+		h = md5.New()
+	}
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// selectDigestChallenge picks the strongest Digest challenge this package
+// can answer from WWW-Authenticate values, preferring SHA-256 to MD5
+func selectDigestChallenge(values []string) *digestChallenge {
+	var best *digestChallenge
+	for _, value := range values {
+		for _, ch := range parseChallenges(value) {
+			if !strings.EqualFold(ch.scheme, "Digest") {
+				continue
+			}
+
+			c := &digestChallenge{
+				realm:     ch.params["realm"],
+				nonce:     ch.params["nonce"],
+				opaque:    ch.params["opaque"],
+				algorithm: strings.ToUpper(ch.params["algorithm"]),
+			}
+			if c.algorithm == "" {
+				c.algorithm = "MD5"
+			}
+			switch c.algorithm {
+			case "MD5", "MD5-SESS", "SHA-256", "SHA-256-SESS":
+				c.algorithm = strings.Replace(c.algorithm, "-SESS", "-sess", 1)
+			default:
+				continue
+			}
+
+			if qop, ok := ch.params["qop"]; ok {
+				for _, option := range strings.Split(qop, ",") {
+					if strings.TrimSpace(option) == "auth" {
+						c.qop = "auth"
+					}
+				}
+				// Only auth-int was offered
+				if c.qop == "" {
+					continue
+				}
+			}
+			if c.nonce == "" {
+				continue
+			}
+
+			if best == nil || (strings.HasPrefix(c.algorithm, "SHA-256") && !strings.HasPrefix(best.algorithm, "SHA-256")) {
+				best = c
+			}
+		}
+	}
+	return best
+}
+
+type authChallenge struct {
+	scheme string
+	params map[string]string
+}
+
+// parseChallenges splits a WWW-Authenticate value, which may hold several
+// comma-separated challenges, into schemes and their auth-params
+func parseChallenges(value string) []authChallenge {
+	var challenges []authChallenge
+	for i := 0; i < len(value); {
+		for i < len(value) && (value[i] == ' ' || value[i] == '\t' || value[i] == ',') {
+			i++
+		}
+		start := i
+		for i < len(value) && !strings.ContainsRune(" \t,=", rune(value[i])) {
+			i++
+		}
+		token := value[start:i]
+		if token == "" {
+			i++
+			continue
+		}
+
+		for i < len(value) && (value[i] == ' ' || value[i] == '\t') {
+			i++
+		}
+		if i >= len(value) || value[i] != '=' || len(challenges) == 0 {
+			challenges = append(challenges, authChallenge{scheme: token, params: map[string]string{}})
+			continue
+		}
+
+		// An auth-param of the current challenge
+		i++
+		for i < len(value) && (value[i] == ' ' || value[i] == '\t') {
+			i++
+		}
+		var param strings.Builder
+		if i < len(value) && value[i] == '"' {
+			for i++; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) {
+					i++
+				}
+				param.WriteByte(value[i])
+			}
+			i++
+		} else {
+			for ; i < len(value) && value[i] != ',' && value[i] != ' '; i++ {
+				param.WriteByte(value[i])
+			}
+		}
+		challenges[len(challenges)-1].params[strings.ToLower(token)] = param.String()
+	}
+	return challenges
+}
+
+// quote renders s as an HTTP quoted-string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate cnonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
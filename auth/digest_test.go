@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDigestResponse(t *testing.T) {
+	// The examples from RFC 7616 section 3.9.1
+	const (
+		nonce  = "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v"
+		cnonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+	)
+
+	tests := []struct {
+		algorithm string
+		expected  string
+	}{
+		{"MD5", "8ca523f5e9506fed4657c9700eebdbec"},
+		{"SHA-256", "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			c := &digestChallenge{realm: "http-auth@example.org", nonce: nonce, algorithm: tt.algorithm, qop: "auth"}
+			got := c.response("Mufasa", "Circle of Life", "GET", "/dir/index.html", "00000001", cnonce)
+			if got != tt.expected {
+				t.Errorf("Expected response %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSelectDigestChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []string
+		algorithm string
+		qop       string
+	}{
+		{"Default MD5", []string{`Digest realm="r", nonce="n"`}, "MD5", ""},
+		{"SHA-256 preferred", []string{`Digest realm="r", nonce="n", algorithm=MD5, qop="auth", Digest realm="r", nonce="n", algorithm=SHA-256, qop="auth,auth-int"`}, "SHA-256", "auth"},
+		{"Separate headers", []string{`Basic realm="r"`, `Digest realm="r", nonce="n", algorithm=SHA-256-sess, qop="auth"`}, "SHA-256-sess", "auth"},
+		{"Unsupported algorithm", []string{`Digest realm="r", nonce="n", algorithm=SHA-512-256`}, "", ""},
+		{"Only auth-int", []string{`Digest realm="r", nonce="n", qop="auth-int"`}, "", ""},
+		{"No Digest", []string{`Bearer realm="api"`}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := selectDigestChallenge(tt.values)
+			if tt.algorithm == "" {
+				if c != nil {
+					t.Fatalf("Expected no usable challenge, got %+v", c)
+				}
+				return
+			}
+			if c == nil || c.algorithm != tt.algorithm || c.qop != tt.qop || c.realm != "r" || c.nonce != "n" {
+				t.Errorf("Expected %s with qop %q, got %+v", tt.algorithm, tt.qop, c)
+			}
+		})
+	}
+}
+
+func TestDigestTransport(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+
+		header := r.Header.Get("Authorization")
+		params := parseChallenges(header)
+		if len(params) == 0 || params[0].scheme != "Digest" {
+			w.Header().Add("WWW-Authenticate", `Basic realm="api"`)
+			w.Header().Add("WWW-Authenticate", `Digest realm="api", nonce="abc", opaque="xyz", algorithm=SHA-256, qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		p := params[0].params
+		c := &digestChallenge{realm: "api", nonce: "abc", algorithm: "SHA-256", qop: "auth"}
+		expected := c.response("user", "secret", r.Method, r.URL.RequestURI(), p["nc"], p["cnonce"])
+		if p["response"] != expected || p["opaque"] != "xyz" || p["username"] != "user" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, "%s %s", p["nc"], body)
+	}))
+	defer server.Close()
+
+	digest := NewDigestAuth("user", "secret")
+	client := &http.Client{Transport: digest.Transport(http.DefaultTransport)}
+
+	send := func(body string) string {
+		req, _ := http.NewRequest("POST", server.URL+"/items?x=1", strings.NewReader(body))
+		if err := digest.Apply(req); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		return string(data)
+	}
+
+	if got := send("first"); got != "00000001 first" {
+		t.Errorf("Expected the challenge to be answered with the body resent, got %q", got)
+	}
+	if got := send("second"); got != "00000002 second" {
+		t.Errorf("Expected the nonce to be reused with the next count, got %q", got)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected only the first request to be challenged, got %d requests", requests.Load())
+	}
+}
@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,25 +21,82 @@ type OAuth2ClientCredentials struct {
 	token        string
 	expiry       time.Time
 	mutex        sync.RWMutex
+	tokenTLS     *tls.Config
+	tokenCAs     *x509.CertPool
+	insecure     bool
+	audience     string
+	tokenParams  url.Values
+	assertionKey crypto.Signer
+	assertionKID string
+	optionErr    error
 }
 
-type tokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
+type OAuth2Option func(*OAuth2ClientCredentials)
+
+// WithTokenClientCert authenticates to the token endpoint with a client
+// certificate (RFC 8705 mutual TLS) instead of the client secret
+func WithTokenClientCert(cert tls.Certificate) OAuth2Option {
+	return func(o *OAuth2ClientCredentials) {
+		o.tokenTLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
 }
 
-func NewOAuth2ClientCredentials(clientID, clientSecret, tokenURL string, scopes []string) (*OAuth2ClientCredentials, error) {
-	if clientID == "" || clientSecret == "" || tokenURL == "" {
-		return nil, fmt.Errorf("clientID, clientSecret, and tokenURL are required")
+// WithTokenRootCAs trusts the CAs in pool rather than the system ones when
+// connecting to the token endpoint, for providers with a private CA
+func WithTokenRootCAs(pool *x509.CertPool) OAuth2Option {
+	return func(o *OAuth2ClientCredentials) {
+		o.tokenCAs = pool
 	}
-	
-	return &OAuth2ClientCredentials{
+}
+
+// WithTokenInsecure skips verifying the token endpoint's certificate
+func WithTokenInsecure() OAuth2Option {
+	return func(o *OAuth2ClientCredentials) {
+		o.insecure = true
+	}
+}
+
+// WithAudience requests a token for audience, as required by providers
+// such as Auth0
+func WithAudience(audience string) OAuth2Option {
+	return func(o *OAuth2ClientCredentials) {
+		o.audience = audience
+	}
+}
+
+// WithTokenParams adds extra parameters to the token request
+func WithTokenParams(params url.Values) OAuth2Option {
+	return func(o *OAuth2ClientCredentials) {
+		o.tokenParams = params
+	}
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func NewOAuth2ClientCredentials(clientID, clientSecret, tokenURL string, scopes []string, opts ...OAuth2Option) (*OAuth2ClientCredentials, error) {
+	o := &OAuth2ClientCredentials{
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		tokenURL:     tokenURL,
 		scopes:       scopes,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.optionErr != nil {
+		return nil, o.optionErr
+	}
+	
+	if clientID == "" || tokenURL == "" || (clientSecret == "" && o.tokenTLS == nil && o.assertionKey == nil) {
+		return nil, fmt.Errorf("clientID, clientSecret (or a client certificate or assertion key), and tokenURL are required")
+	}
+	
+	return o, nil
 }
 
 func (o *OAuth2ClientCredentials) Apply(req *http.Request) error {
@@ -72,11 +132,29 @@ func (o *OAuth2ClientCredentials) fetchToken() (string, error) {
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
 	data.Set("client_id", o.clientID)
-	data.Set("client_secret", o.clientSecret)
+	switch {
+	case o.assertionKey != nil:
+		assertion, err := buildClientAssertion(o.assertionKey, o.assertionKID, o.clientID, o.tokenURL, time.Now())
+		if err != nil {
+			return "", err
+		}
+		data.Set("client_assertion_type", clientAssertionType)
+		data.Set("client_assertion", assertion)
+	case o.tokenTLS == nil:
+		data.Set("client_secret", o.clientSecret)
+	}
 	
 	if len(o.scopes) > 0 {
 		data.Set("scope", strings.Join(o.scopes, " "))
 	}
+
+	if o.audience != "" {
+		data.Set("audience", o.audience)
+	}
+
+	for key, values := range o.tokenParams {
+		data[key] = values
+	}
 	
 	req, err := http.NewRequest("POST", o.tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
@@ -86,8 +164,7 @@ func (o *OAuth2ClientCredentials) fetchToken() (string, error) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 	
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := o.tokenClient().Do(req)
 	if err != nil {
 		return "", fmt.Errorf("token request failed: %w", err)
 	}
@@ -114,4 +191,22 @@ func (o *OAuth2ClientCredentials) fetchToken() (string, error) {
 	}
 	
 	return o.token, nil
-}
\ No newline at end of file
+}
+
+// tokenClient returns the client for token requests. It keeps the default
+// transport's proxy, timeouts and HTTP/2 support, adding only the client
+// certificate, trusted CAs and --insecure.
+func (o *OAuth2ClientCredentials) tokenClient() *http.Client {
+	client := &http.Client{Timeout: 30 * time.Second}
+	if o.tokenTLS == nil && o.tokenCAs == nil && !o.insecure {
+		return client
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: o.tokenCAs, InsecureSkipVerify: o.insecure}
+	if o.tokenTLS != nil {
+		transport.TLSClientConfig.Certificates = o.tokenTLS.Certificates
+	}
+	client.Transport = transport
+	return client
+}
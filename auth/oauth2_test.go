@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestClientCert creates a self-signed client certificate
+func newTestClientCert(t *testing.T, commonName string) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	pair, err := tls.X509KeyPair(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build keypair: %v", err)
+	}
+	return pair, pair.Leaf
+}
+
+func TestOAuth2TokenEndpointMutualTLS(t *testing.T) {
+	clientCert, leaf := newTestClientCert(t, "client-123")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(leaf)
+
+	var form url.Values
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "client-123" {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		form = r.PostForm
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "mtls-token", "expires_in": 3600})
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	o, err := NewOAuth2ClientCredentials("client-123", "", server.URL, []string{"read"}, WithTokenClientCert(clientCert), WithTokenRootCAs(roots))
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	if err := o.Apply(req); err != nil {
+		t.Fatalf("Failed to apply authentication: %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer mtls-token" {
+		t.Errorf("Expected 'Bearer mtls-token', got %q", got)
+	}
+	if form.Get("client_id") != "client-123" {
+		t.Errorf("Expected client_id in token request, got %v", form)
+	}
+	if _, ok := form["client_secret"]; ok {
+		t.Error("client_secret must be omitted with mutual TLS client authentication")
+	}
+}
+
+func TestOAuth2TokenEndpointPrivateCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "private-ca-token"})
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	authenticator, err := NewAuthenticator(Config{ClientID: "client-123", ClientSecret: "secret", TokenURL: server.URL, TokenCAFile: caFile})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	if err := authenticator.Apply(req); err != nil {
+		t.Fatalf("Failed to apply authentication: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer private-ca-token" {
+		t.Errorf("Expected 'Bearer private-ca-token', got %q", got)
+	}
+
+	// The token client keeps the default transport's settings
+	tr := authenticator.(*OAuth2ClientCredentials).tokenClient().Transport.(*http.Transport)
+	if tr.Proxy == nil || !tr.ForceAttemptHTTP2 || tr.TLSHandshakeTimeout == 0 {
+		t.Error("Expected the token transport to keep the proxy, HTTP/2 and timeouts")
+	}
+}
+
+func TestOAuth2TokenEndpointRejectsMissingCert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token"})
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	o, err := NewOAuth2ClientCredentials("client-123", "secret", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	if err := o.Apply(req); err == nil {
+		t.Error("Expected token request without a client certificate to fail")
+	}
+}
+
+func TestNewOAuth2ClientCredentialsRequiresSecretOrCert(t *testing.T) {
+	if _, err := NewOAuth2ClientCredentials("client", "", "https://auth.example.com/token", nil); err == nil {
+		t.Error("Expected error without a secret or client certificate")
+	}
+
+	cert, _ := newTestClientCert(t, "client")
+	if _, err := NewOAuth2ClientCredentials("client", "", "https://auth.example.com/token", nil, WithTokenClientCert(cert)); err != nil {
+		t.Errorf("Expected client certificate to stand in for the secret: %v", err)
+	}
+}
+
+func TestOAuth2TokenRequestParameters(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	authenticator, err := NewAuthenticator(Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		TokenURL:     server.URL,
+		Scopes:       []string{"read:users", "write:users"},
+		Audience:     "https://api.example.com",
+		TokenParams:  []string{"resource=orders", "organization=acme"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	if err := authenticator.Apply(req); err != nil {
+		t.Fatalf("Failed to apply authentication: %v", err)
+	}
+
+	expected := map[string]string{
+		"grant_type":   "client_credentials",
+		"scope":        "read:users write:users",
+		"audience":     "https://api.example.com",
+		"resource":     "orders",
+		"organization": "acme",
+	}
+	for key, value := range expected {
+		if got := form.Get(key); got != value {
+			t.Errorf("Expected %s %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestOAuth2OmitsUnsetAudience(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token"})
+	}))
+	defer server.Close()
+
+	o, _ := NewOAuth2ClientCredentials("client", "secret", server.URL, nil)
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	if err := o.Apply(req); err != nil {
+		t.Fatalf("Failed to apply authentication: %v", err)
+	}
+
+	if _, ok := form["audience"]; ok {
+		t.Error("Expected no audience parameter when none is set")
+	}
+}
+
+func TestInvalidTokenParam(t *testing.T) {
+	_, err := NewAuthenticator(Config{ClientID: "client", ClientSecret: "secret", TokenURL: "http://example.com", TokenParams: []string{"novalue"}})
+	if err == nil {
+		t.Error("Expected malformed token parameter to be rejected")
+	}
+}
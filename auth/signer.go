@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Signer adds authorization to a request that depends on its full content,
+// such as an HMAC over the method, URL, headers and body
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// CommandSigner delegates signing to an external command. The command reads
+// the canonical request on stdin and writes "Key: Value" headers to stdout,
+// which are set on the request.
+type CommandSigner struct {
+	command string
+}
+
+func NewCommandSigner(command string) *CommandSigner {
+	return &CommandSigner{
+		command: command,
+	}
+}
+
+func (c *CommandSigner) Sign(req *http.Request, body []byte) error {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", c.command)
+	cmd.Stdin = bytes.NewReader(CanonicalRequest(req, body))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("signer command %q failed: %w: %s", c.command, err, msg)
+		}
+		return fmt.Errorf("signer command %q failed: %w", c.command, err)
+	}
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("signer command %q produced invalid header line %q", c.command, line)
+		}
+		req.Header.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	return nil
+}
+
+// CanonicalRequest renders req as the method, the URL, one lowercased
+// "name:value" line per header in sorted order, a blank line and the body
+func CanonicalRequest(req *http.Request, body []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(req.Method + "\n")
+	buf.WriteString(req.URL.String() + "\n")
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s:%s\n", strings.ToLower(name), strings.Join(req.Header.Values(name), ","))
+	}
+
+	buf.WriteString("\n")
+	buf.Write(body)
+	return buf.Bytes()
+}
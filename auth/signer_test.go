@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCommandSigner(t *testing.T) {
+	// Echoes the request line and a digest of stdin back as headers
+	script := writeScript(t, `read method; read url; echo "X-Signed-Method: $method"; echo "X-Signed-URL: $url"; echo "X-Signature: $(cksum | cut -d' ' -f1)"`)
+
+	req, _ := http.NewRequest("POST", "http://example.com/orders?id=1", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	signer := NewCommandSigner(script)
+	if err := signer.Sign(req, []byte("{}")); err != nil {
+		t.Fatalf("Failed to sign request: %v", err)
+	}
+
+	if got := req.Header.Get("X-Signed-Method"); got != "POST" {
+		t.Errorf("Expected X-Signed-Method 'POST', got %q", got)
+	}
+	if got := req.Header.Get("X-Signed-URL"); got != "http://example.com/orders?id=1" {
+		t.Errorf("Expected X-Signed-URL to be the request URL, got %q", got)
+	}
+	if req.Header.Get("X-Signature") == "" {
+		t.Error("Expected X-Signature to be set")
+	}
+}
+
+func TestCommandSignerFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{"Command fails", `echo "no key" >&2; exit 1`, "no key"},
+		{"Invalid header line", `echo "not a header"`, "invalid header line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			err := NewCommandSigner(writeScript(t, tt.script)).Sign(req, nil)
+			if err == nil {
+				t.Fatal("Expected signing to fail")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error to contain %q, got: %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCanonicalRequest(t *testing.T) {
+	req, _ := http.NewRequest("PUT", "http://example.com/a", nil)
+	req.Header.Set("X-B", "2")
+	req.Header.Add("X-A", "1")
+	req.Header.Add("X-A", "3")
+
+	expected := "PUT\nhttp://example.com/a\nx-a:1,3\nx-b:2\n\nbody"
+	if got := string(CanonicalRequest(req, []byte("body"))); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
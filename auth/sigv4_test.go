@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticAWSCredentials returns a chain that always yields creds
+func staticAWSCredentials(creds AWSCredentials) *AWSCredentialChain {
+	return &AWSCredentialChain{cached: &creds}
+}
+
+func TestAWSSigV4(t *testing.T) {
+	// Requests and signatures from the AWS SigV4 test suite
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	at := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		url           string
+		signedHeaders string
+		signature     string
+	}{
+		{"get-vanilla", "https://example.amazonaws.com/", "host;x-amz-date", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-query-order-key-case", "https://example.amazonaws.com/?Param2=value2&Param1=value1", "host;x-amz-date", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := NewAWSSigV4("us-east-1", "service")
+			signer.credentials = staticAWSCredentials(creds)
+			signer.now = func() time.Time { return at }
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if err := signer.Sign(req, nil); err != nil {
+				t.Fatalf("Failed to sign request: %v", err)
+			}
+
+			expected := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=%s, Signature=%s", tt.signedHeaders, tt.signature)
+			if got := req.Header.Get("Authorization"); got != expected {
+				t.Errorf("Expected Authorization\n%s\ngot\n%s", expected, got)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("Expected X-Amz-Date 20150830T123600Z, got %q", got)
+			}
+		})
+	}
+}
+
+func TestAWSSigV4S3AndSessionToken(t *testing.T) {
+	signer := NewAWSSigV4("eu-west-1", "s3")
+	signer.credentials = staticAWSCredentials(AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"})
+
+	req, _ := http.NewRequest("PUT", "https://bucket.s3.amazonaws.com/a%20b.txt", strings.NewReader("hello"))
+	if err := signer.Sign(req, []byte("hello")); err != nil {
+		t.Fatalf("Failed to sign request: %v", err)
+	}
+
+	if got := req.Header.Get("X-Amz-Content-Sha256"); got != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("Expected the payload hash header, got %q", got)
+	}
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "session" {
+		t.Errorf("Expected the session token header, got %q", got)
+	}
+	if !strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Expected the AWS headers to be signed, got %q", req.Header.Get("Authorization"))
+	}
+	if got := canonicalAWSPath(req.URL, "s3"); got != "/a%20b.txt" {
+		t.Errorf("Expected S3 paths to be encoded once, got %q", got)
+	}
+	if got := canonicalAWSPath(req.URL, "execute-api"); got != "/a%2520b.txt" {
+		t.Errorf("Expected other paths to be encoded twice, got %q", got)
+	}
+}
+
+func TestParseAWSSigV4(t *testing.T) {
+	signer, err := ParseAWSSigV4("us-west-2/execute-api")
+	if err != nil || signer.region != "us-west-2" || signer.service != "execute-api" {
+		t.Errorf("Expected region and service, got %+v (%v)", signer, err)
+	}
+	for _, value := range []string{"us-west-2", "/s3", "us-west-2/", "a/b/c"} {
+		if _, err := ParseAWSSigV4(value); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
+
+func TestAWSCredentialChain(t *testing.T) {
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	os.WriteFile(credentialsFile, []byte("[default]\naws_access_key_id = FILEKEY\naws_secret_access_key = filesecret\n\n[other]\naws_access_key_id=OTHER\n"), 0600)
+	configFile := filepath.Join(dir, "config")
+	os.WriteFile(configFile, []byte("[profile other]\naws_access_key_id = CONFIGKEY\naws_secret_access_key = configsecret\n"), 0600)
+
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/latest/api/token":
+			fmt.Fprint(w, "token")
+		case r.Header.Get("X-Aws-Ec2-Metadata-Token") != "token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprint(w, "web-role\n")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/web-role":
+			fmt.Fprintf(w, `{"AccessKeyId":"IMDSKEY","SecretAccessKey":"imdssecret","Token":"imdstoken","Expiration":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer imds.Close()
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		keyID   string
+		token   string
+		failure string
+	}{
+		{"Environment", map[string]string{"AWS_ACCESS_KEY_ID": "ENVKEY", "AWS_SECRET_ACCESS_KEY": "envsecret", "AWS_SESSION_TOKEN": "envtoken"}, "ENVKEY", "envtoken", ""},
+		{"Shared credentials", nil, "FILEKEY", "", ""},
+		{"Config file profile", map[string]string{"AWS_PROFILE": "other"}, "CONFIGKEY", "", ""},
+		{"Instance metadata", map[string]string{"AWS_PROFILE": "missing"}, "IMDSKEY", "imdstoken", ""},
+		{"Metadata disabled", map[string]string{"AWS_PROFILE": "missing", "AWS_EC2_METADATA_DISABLED": "true"}, "", "", "no AWS credentials found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_EC2_METADATA_DISABLED"} {
+				t.Setenv(key, tt.env[key])
+			}
+			t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+			t.Setenv("AWS_CONFIG_FILE", configFile)
+			t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", imds.URL)
+
+			creds, err := NewAWSCredentialChain().Retrieve(context.Background())
+			if tt.failure != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.failure) {
+					t.Fatalf("Expected error containing %q, got %v", tt.failure, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to resolve credentials: %v", err)
+			}
+			if creds.AccessKeyID != tt.keyID || creds.SessionToken != tt.token {
+				t.Errorf("Expected key %q and token %q, got %+v", tt.keyID, tt.token, creds)
+			}
+		})
+	}
+}
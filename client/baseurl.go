@@ -0,0 +1,51 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// joinURL appends path to base. Unlike RFC 3986 resolution, a leading slash
+// in path doesn't drop the base path, so "/users" against ".../v1" gives
+// ".../v1/users". Base query parameters are kept, followed by those of path.
+// An absolute URL in path is returned unchanged.
+func joinURL(base, path string) (string, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	if ref.IsAbs() {
+		return path, nil
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %w", base, err)
+	}
+	if !baseURL.IsAbs() || baseURL.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q: must be absolute", base)
+	}
+
+	joined := *baseURL
+	if ref.Path != "" {
+		rawPath := strings.TrimRight(baseURL.EscapedPath(), "/") + "/" + strings.TrimLeft(ref.EscapedPath(), "/")
+		if joined.Path, err = url.PathUnescape(rawPath); err != nil {
+			return "", fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		joined.RawPath = rawPath
+	}
+
+	switch {
+	case baseURL.RawQuery == "":
+		joined.RawQuery = ref.RawQuery
+	case ref.RawQuery != "":
+		joined.RawQuery = baseURL.RawQuery + "&" + ref.RawQuery
+	}
+
+	if ref.Fragment != "" {
+		joined.Fragment = ref.Fragment
+	}
+
+	return joined.String(), nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		path     string
+		expected string
+	}{
+		{"Leading slash", "https://api.example.com/v1", "/users", "https://api.example.com/v1/users"},
+		{"No leading slash", "https://api.example.com/v1", "users", "https://api.example.com/v1/users"},
+		{"Trailing and leading slash", "https://api.example.com/v1/", "/users", "https://api.example.com/v1/users"},
+		{"Base without path", "https://api.example.com", "/users/1", "https://api.example.com/users/1"},
+		{"Trailing slash kept", "https://api.example.com/v1", "/users/", "https://api.example.com/v1/users/"},
+		{"Empty path", "https://api.example.com/v1", "", "https://api.example.com/v1"},
+		{"Path query", "https://api.example.com/v1", "/users?page=2", "https://api.example.com/v1/users?page=2"},
+		{"Base query preserved", "https://api.example.com/v1?key=abc", "/users", "https://api.example.com/v1/users?key=abc"},
+		{"Queries merged", "https://api.example.com/v1?key=abc", "/users?page=2", "https://api.example.com/v1/users?key=abc&page=2"},
+		{"Escaped path", "https://api.example.com/v1", "/files/a%2Fb", "https://api.example.com/v1/files/a%2Fb"},
+		{"Absolute URL overrides base", "https://api.example.com/v1", "https://other.example.com/x", "https://other.example.com/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinURL(tt.base, tt.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJoinURLInvalidBase(t *testing.T) {
+	for _, base := range []string{"/v1", "api.example.com/v1", "http://[::1"} {
+		if _, err := joinURL(base, "/users"); err == nil {
+			t.Errorf("Expected base %q to be rejected", base)
+		}
+	}
+}
+
+func TestBaseURLRequest(t *testing.T) {
+	var path, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.RawQuery
+	}))
+	defer server.Close()
+
+	r, _, _ := newTestRequester(t, Config{
+		BaseURL: server.URL + "/v1?key=abc",
+		URL:     "/users",
+	})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if path != "/v1/users" || query != "key=abc" {
+		t.Errorf("Expected /v1/users?key=abc, got %s?%s", path, query)
+	}
+}
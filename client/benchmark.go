@@ -0,0 +1,132 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// benchmarkResult collects the outcome of every request in a --benchmark run
+type benchmarkResult struct {
+	Duration  time.Duration
+	Latencies []time.Duration
+	Statuses  map[int]int
+	Errors    int
+	FirstErr  error
+}
+
+// benchmark sends --requests requests to the URL, or keeps sending them for
+// --duration, across --concurrency workers and prints throughput, latency
+// percentiles and status codes
+func (r *requester) benchmark() error {
+	concurrency := max(1, r.config.Concurrency)
+
+	// next reports whether a worker should start another request. Requests
+	// still in flight when --duration runs out are completed and counted.
+	var next func() bool
+	if r.config.BenchDuration > 0 {
+		deadline := time.Now().Add(r.config.BenchDuration)
+		next = func() bool { return time.Now().Before(deadline) }
+	} else {
+		requests := int64(r.config.Requests)
+		concurrency = max(1, min(concurrency, r.config.Requests))
+		var started atomic.Int64
+		next = func() bool { return started.Add(1) <= requests }
+	}
+
+	result := benchmarkResult{Statuses: make(map[int]int)}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next() {
+				if r.ctx.Err() != nil {
+					return
+				}
+
+				// The clock starts when the request is sent, after any wait
+				// for --rate and building the request
+				status := 0
+				requestStart := time.Now()
+				err := r.send(r.config.URL, true, false, func(resp *http.Response) error {
+					if timer := requestTimer(resp.Request); timer != nil {
+						requestStart = timer.start
+					}
+					status = resp.StatusCode
+					_, err := io.Copy(io.Discard, resp.Body)
+					return err
+				})
+				latency := time.Since(requestStart)
+
+				mutex.Lock()
+				if err != nil {
+					result.Errors++
+					if result.FirstErr == nil {
+						result.FirstErr = err
+					}
+				} else {
+					result.Latencies = append(result.Latencies, latency)
+				}
+				if status != 0 {
+					result.Statuses[status]++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.Duration = time.Since(start)
+
+	result.write(r.stdout)
+	if r.ctx.Err() != nil {
+		return interruptedError(0)
+	}
+	return nil
+}
+
+func (b *benchmarkResult) write(w io.Writer) {
+	total := len(b.Latencies) + b.Errors
+	fmt.Fprintf(w, "Requests:     %d (%d failed)\n", total, b.Errors)
+	fmt.Fprintf(w, "Duration:     %s\n", b.Duration.Round(time.Millisecond))
+	if b.Duration > 0 {
+		fmt.Fprintf(w, "Throughput:   %.2f req/s\n", float64(total)/b.Duration.Seconds())
+	}
+
+	sorted := append([]time.Duration(nil), b.Latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, p := range []float64{50, 90, 99} {
+		fmt.Fprintf(w, "Latency p%v:  %s\n", p, percentile(sorted, p).Round(time.Microsecond))
+	}
+
+	fmt.Fprintln(w, "Status codes:")
+	codes := make([]int, 0, len(b.Statuses))
+	for code := range b.Statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "  %d: %d\n", code, b.Statuses[code])
+	}
+
+	if b.FirstErr != nil {
+		fmt.Fprintf(w, "First error:  %v\n", b.FirstErr)
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
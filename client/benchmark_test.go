@@ -0,0 +1,154 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 1; i <= 100; i++ {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{0, 1 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(durations, tt.p); got != tt.expected {
+			t.Errorf("p%v: expected %s, got %s", tt.p, tt.expected, got)
+		}
+	}
+
+	if got := percentile([]time.Duration{3 * time.Second}, 99); got != 3*time.Second {
+		t.Errorf("Expected single sample to be every percentile, got %s", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("Expected 0 for no samples, got %s", got)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every fifth request fails
+		if atomic.AddInt32(&requests, 1)%5 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	r, stdout, _ := newTestRequester(t, Config{
+		URL:         server.URL,
+		Benchmark:   true,
+		Requests:    20,
+		Concurrency: 4,
+	})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+
+	if requests != 20 {
+		t.Errorf("Expected 20 requests, server saw %d", requests)
+	}
+
+	report := stdout.String()
+	for _, line := range []string{"Requests:     20 (0 failed)\n", "  200: 16\n", "  500: 4\n", "Latency p50", "Latency p90", "Latency p99", "req/s"} {
+		if !strings.Contains(report, line) {
+			t.Errorf("Expected %q in report:\n%s", line, report)
+		}
+	}
+}
+
+func TestBenchmarkLatencyExcludesRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The last of the five requests queues for 400ms behind --rate
+	r, stdout, _ := newTestRequester(t, Config{
+		URL:         server.URL,
+		Benchmark:   true,
+		Requests:    5,
+		Concurrency: 5,
+		RateLimit:   "1/100ms",
+	})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+
+	report := stdout.String()
+	_, after, found := strings.Cut(report, "Latency p99:")
+	if !found {
+		t.Fatalf("Expected a p99 latency in report:\n%s", report)
+	}
+	p99, err := time.ParseDuration(strings.TrimSpace(strings.SplitN(after, "\n", 2)[0]))
+	if err != nil {
+		t.Fatalf("Failed to parse p99 latency: %v", err)
+	}
+	if p99 > 200*time.Millisecond {
+		t.Errorf("Expected the rate limit wait to be left out of the latency, got p99 %s", p99)
+	}
+}
+
+func TestBenchSubcommand(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		args    []string
+		minTime time.Duration
+		check   func(sent int32) bool
+	}{
+		{"Fixed count", []string{"-n", "20", "-c", "4"}, 0, func(sent int32) bool { return sent == 20 }},
+		{"Duration", []string{"--duration", "150ms", "-c", "2"}, 150 * time.Millisecond, func(sent int32) bool { return sent >= 2 }},
+		{"Duration with rate limit", []string{"--duration", "250ms", "-c", "4", "--rate", "2/100ms"}, 250 * time.Millisecond, func(sent int32) bool { return sent >= 2 && sent <= 15 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			var stdout, stderr strings.Builder
+			start := time.Now()
+			if code := Run(append(append([]string{"bench"}, tt.args...), server.URL), &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+			}
+			if elapsed := time.Since(start); elapsed < tt.minTime {
+				t.Errorf("Expected the run to last at least %s, took %s", tt.minTime, elapsed)
+			}
+
+			sent := atomic.LoadInt32(&requests)
+			if !tt.check(sent) {
+				t.Errorf("Unexpected number of requests: %d", sent)
+			}
+			if !strings.Contains(stdout.String(), "Requests:     "+strconv.Itoa(int(sent))+" (0 failed)") {
+				t.Errorf("Expected the report to count %d requests, got %q", sent, stdout.String())
+			}
+			if !strings.Contains(stdout.String(), "  200: "+strconv.Itoa(int(sent))) {
+				t.Errorf("Expected a status histogram, got %q", stdout.String())
+			}
+		})
+	}
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"bench", "-n", "0", server.URL}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for -n 0, got %d", exitUsage, code)
+	}
+}
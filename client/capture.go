@@ -0,0 +1,91 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// capture names where a collection request takes a variable's value from:
+// "status", "header:NAME" or "json:PATH"
+type capture struct {
+	source string
+	key    string
+}
+
+// parseCapture checks a capture spec from a collection file
+func parseCapture(spec string) (capture, error) {
+	source, key, _ := strings.Cut(spec, ":")
+	source = strings.TrimSpace(source)
+	key = strings.TrimSpace(key)
+
+	switch {
+	case source == "status" && key == "":
+	case (source == "header" || source == "json") && key != "":
+	default:
+		return capture{}, fmt.Errorf("invalid capture %q (expected 'status', 'header:NAME' or 'json:PATH')", spec)
+	}
+	return capture{source: source, key: key}, nil
+}
+
+// needsBody reports whether any of the captures reads the response body
+func needsBody(captures map[string]capture) bool {
+	for _, c := range captures {
+		if c.source == "json" {
+			return true
+		}
+	}
+	return false
+}
+
+// captureValues extracts the captured variables from a response. JSON
+// strings are taken as they are and other JSON values as their JSON text.
+func captureValues(resp *http.Response, body []byte, captures map[string]capture) (map[string]string, error) {
+	values := make(map[string]string, len(captures))
+	var doc any
+	parsed := false
+
+	names := make([]string, 0, len(captures))
+	for name := range captures {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		c := captures[name]
+		switch c.source {
+		case "status":
+			values[name] = strconv.Itoa(resp.StatusCode)
+		case "header":
+			value := resp.Header.Get(c.key)
+			if value == "" {
+				return nil, fmt.Errorf("failed to capture %s: no %s header in the response", name, c.key)
+			}
+			values[name] = value
+		case "json":
+			if !parsed {
+				if err := json.Unmarshal(body, &doc); err != nil {
+					return nil, fmt.Errorf("failed to capture %s: response is not JSON: %w", name, err)
+				}
+				parsed = true
+			}
+			value, ok := lookupJSONPath(doc, c.key)
+			if !ok {
+				return nil, fmt.Errorf("failed to capture %s: no %q in the response", name, c.key)
+			}
+			if s, isString := value.(string); isString {
+				values[name] = s
+				continue
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to capture %s: %w", name, err)
+			}
+			values[name] = string(data)
+		}
+	}
+	return values, nil
+}
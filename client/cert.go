@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// checkCert connects to the server, completes the TLS handshake and reports
+// the certificate without sending an HTTP request
+func (r *requester) checkCert() error {
+	u, err := url.Parse(r.config.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("--check-cert requires an https URL")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	tlsConfig := &tls.Config{}
+	dial := (&net.Dialer{}).DialContext
+	if transport, ok := r.client.Transport.(*http.Transport); ok {
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+		if transport.DialContext != nil {
+			dial = transport.DialContext
+		}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.Timeout)
+	defer cancel()
+
+	conn, err := dial(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+
+	state := tlsConn.ConnectionState()
+	return reportCert(r.stdout, &state, r.config.CertMinDays, time.Now())
+}
+
+// reportCert prints the server certificate and fails when it expires within
+// minDays
+func reportCert(w io.Writer, state *tls.ConnectionState, minDays int, now time.Time) error {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("--check-cert requires an https URL")
+	}
+	cert := state.PeerCertificates[0]
+	days := int(cert.NotAfter.Sub(now).Hours() / 24)
+
+	fmt.Fprintf(w, "Subject:    %s\n", cert.Subject)
+	fmt.Fprintf(w, "Issuer:     %s\n", cert.Issuer)
+	fmt.Fprintf(w, "SANs:       %s\n", strings.Join(certNames(cert), ", "))
+	fmt.Fprintf(w, "Not before: %s\n", cert.NotBefore.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "Not after:  %s (%d days left)\n", cert.NotAfter.UTC().Format(time.RFC3339), days)
+
+	if minDays > 0 && cert.NotAfter.Before(now.Add(time.Duration(minDays)*24*time.Hour)) {
+		return &exitError{
+			code: exitCertExpiring,
+			err:  fmt.Errorf("certificate for %s expires in %d days, less than --cert-min-days %d", cert.Subject, days, minDays),
+		}
+	}
+	return nil
+}
+
+// certNames lists the certificate's DNS, IP, email and URI subject
+// alternative names
+func certNames(cert *x509.Certificate) []string {
+	names := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	names = append(names, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	return names
+}
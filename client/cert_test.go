@@ -0,0 +1,93 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newNearExpiryServer(t *testing.T) (*httptest.Server, *x509.CertPool) {
+	t.Helper()
+
+	// newTestCert issues certificates that expire in 24 hours
+	ca := newTestCert(t, "CA", nil, nil)
+	leaf := newTestCert(t, "localhost", ca, nil).tlsCertificate(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{leaf}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	return server, roots
+}
+
+func TestCheckCert(t *testing.T) {
+	server, roots := newNearExpiryServer(t)
+
+	tests := []struct {
+		name        string
+		config      Config
+		expectError bool
+	}{
+		{"Report only", Config{CheckCertOnly: true, CheckCert: true}, false},
+		{"Within min days", Config{CheckCertOnly: true, CheckCert: true, CertMinDays: 7}, true},
+		{"With request", Config{CheckCert: true}, false},
+		{"With request within min days", Config{CheckCert: true, CertMinDays: 7}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.URL = server.URL
+			r, stdout, stderr := newTestRequester(t, tt.config)
+			r.client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{RootCAs: roots}
+
+			err := r.execute()
+
+			report := stderr.String()
+			if tt.config.CheckCertOnly {
+				report = stdout.String()
+			} else if !strings.Contains(stdout.String(), "ok") {
+				t.Errorf("Expected the response to be printed, got: %s", stdout.String())
+			}
+			for _, want := range []string{"Subject:    CN=localhost", "Issuer:     CN=CA", "SANs:       localhost, 127.0.0.1", "(0 days left)"} {
+				if !strings.Contains(report, want) {
+					t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+				}
+			}
+
+			if !tt.expectError {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var exitErr *exitError
+			if !errors.As(err, &exitErr) || exitErr.code != exitCertExpiring {
+				t.Fatalf("Expected exit code %d, got: %v", exitCertExpiring, err)
+			}
+			if !strings.Contains(err.Error(), "less than --cert-min-days 7") {
+				t.Errorf("Expected min-days failure, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckCertRequiresHTTPS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	for _, only := range []bool{true, false} {
+		r, _, _ := newTestRequester(t, Config{URL: server.URL, CheckCert: true, CheckCertOnly: only})
+		if err := r.execute(); err == nil || !strings.Contains(err.Error(), "requires an https URL") {
+			t.Errorf("Expected https error (check-cert-only=%v), got: %v", only, err)
+		}
+	}
+}
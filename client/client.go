@@ -0,0 +1,1601 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	
+	"http-client/auth"
+	"http-client/ratelimit"
+	"http-client/response"
+	"http-client/retry"
+	"http-client/transport"
+)
+
+type Config struct {
+	Method                string
+	URL                   string
+	Headers               []string
+	Query                 []string
+	Data                  string
+	Form                  []string
+	Timeout               time.Duration
+	Username              string
+	Password              string
+	Digest                bool
+	BearerToken           string
+	BearerCommand         string
+	ClientID              string
+	ClientSecret          string
+	TokenURL              string
+	OAuthAuthCode         bool
+	OAuthAuthURL          string
+	OAuthRedirectPort     int
+	OAuthTokenCache       string
+	Scopes                []string
+	CustomHeader          string
+	CustomValue           string
+	PrettyPrint           bool
+	Raw                   bool
+	RateLimit             string
+	MaxFileSize           int64
+	MaxBody               int64
+	HTTPVersion           string
+	JSONPatch             []string
+	MergePatch            []string
+	PrintCookies          bool
+	Paginate              bool
+	PaginateField         string
+	PaginateMerge         bool
+	PageParam             string
+	CursorParam           string
+	PaginateItems         string
+	PaginateNDJSON        bool
+	MaxPages              int
+	JSONFields            []string
+	Verbose               bool
+	CertDir               string
+	HeadersJSON           bool
+	RetryOnReset          bool
+	MaxHeaderBytes        int64
+	DecodeJWT             bool
+	JWTHeader             string
+	MaxResponseTime       time.Duration
+	FormDir               string
+	FormDirPattern        string
+	FormDirPrefix         string
+	RecordDir             string
+	ReplayDir             string
+	TokenCertFile         string
+	TokenKeyFile          string
+	PrintBody             bool
+	MethodHeaders         []string
+	Output                string
+	RemoveOnInterrupt     bool
+	SignerCommand         string
+	AWSSigV4              string
+	DNSCacheTTL           time.Duration
+	OutputDir             string
+	ExpectContentType     []string
+	BaseURL               string
+	FoldHeaders           bool
+	RateFromHeaders       bool
+	Benchmark             bool
+	Requests              int
+	Concurrency           int
+	Repeat                int
+	OutputPattern         string
+	EmptyAsError          bool
+	RequireBody           bool
+	SNI                   string
+	ErrorJSON             bool
+	ShadowURL             string
+	RetryJitter           retry.Jitter
+	MetricsFile           string
+	ResponseContentType   string
+	OAuthAudience         string
+	OAuthParams           []string
+	QuietErrors           bool
+	SpeedLimit            int64
+	SpeedTime             time.Duration
+	CheckCert             bool
+	CertMinDays           int
+	CheckCertOnly         bool
+	Until                 UntilCondition
+	UntilBody             BodyPattern
+	PollInterval          time.Duration
+	PollTimeout           time.Duration
+	ErrorOutput           string
+	TrimTrailingNewline   bool
+	EnsureTrailingNewline bool
+	DataHex               string
+	DataBase64            string
+	GzipOutput            bool
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	FirstByteTimeout      time.Duration
+	TLSInfo               bool
+	MaxUploadSize         int64
+	REPL                  bool
+	MaskFields            []string
+	OAuthAssertionKey     string
+	OAuthAssertionKID     string
+	Filter                BodyPattern
+	FilterOut             BodyPattern
+	Retry                 int
+	RetryDelay            time.Duration
+	RetryMaxDelay         time.Duration
+	RetryOn               retry.Conditions
+	NoFollow              bool
+	MaxRedirects          int
+	ShowRedirects         bool
+	CookieFile            string
+	CookieJarFile         string
+	NoProgress            bool
+	JSON                  bool
+	TraceTime             bool
+	TraceTimeJSON         bool
+	Insecure              bool
+	CACert                string
+	ClientCert            string
+	ClientKey             string
+	TLSMin                string
+	TLSMax                string
+	Ciphers               []string
+	Proxy                 string
+	NoProxy               string
+	UnixSocket            string
+	Progress              bool
+	NoExpand              bool
+	Args                  []string
+	ExpectStatus          []string
+	ExpectHeaders         []string
+	ExpectBodyContains    []string
+	ExpectJSONPath        []string
+	Fail                  bool
+	FailWithBody          bool
+	OutputFormat          string
+	BodyBase64            bool
+	HeadOnly              bool
+	Include               bool
+	BodyOnly              bool
+	Color                 string
+	Colorize              bool
+	Compressed            bool
+	CompressBody          string
+	BenchDuration         time.Duration
+	URLFile               string
+	Parallel              int
+	WebSocket             bool
+	WSBinary              bool
+	WSMessages            int
+	WSPing                time.Duration
+	WSCloseCode           int
+	ProtoFile             string
+	ProtoMessage          string
+	ProtoResponseMessage  string
+	GRPCWeb               bool
+}
+
+type HeaderList []string
+
+func (h *HeaderList) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *HeaderList) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+// MethodHeaderList holds headers scoped to request methods, given as
+// "METHOD[,METHOD...] Key: Value"
+type MethodHeaderList []string
+
+func (m *MethodHeaderList) String() string {
+	return strings.Join(*m, ", ")
+}
+
+func (m *MethodHeaderList) Set(value string) error {
+	methods, header, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found || methods == "" || !strings.Contains(header, ":") {
+		return fmt.Errorf("must be in 'METHOD Key: Value' format")
+	}
+	*m = append(*m, value)
+	return nil
+}
+
+type QueryList []string
+
+func (q *QueryList) String() string {
+	return strings.Join(*q, ", ")
+}
+
+func (q *QueryList) Set(value string) error {
+	*q = append(*q, value)
+	return nil
+}
+
+type FormList []string
+
+func (f *FormList) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *FormList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+type ScopeList []string
+
+func (s *ScopeList) String() string {
+	return strings.Join(*s, " ")
+}
+
+func (s *ScopeList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// CipherList collects cipher suite names, given comma-separated or by
+// repeating the flag
+type CipherList []string
+
+func (c *CipherList) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *CipherList) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*c = append(*c, name)
+		}
+	}
+	return nil
+}
+
+type OAuthParamList []string
+
+func (o *OAuthParamList) String() string {
+	return strings.Join(*o, ", ")
+}
+
+func (o *OAuthParamList) Set(value string) error {
+	if key, _, found := strings.Cut(value, "="); !found || key == "" {
+		return fmt.Errorf("must be in 'key=value' format")
+	}
+	*o = append(*o, value)
+	return nil
+}
+
+// Run executes the CLI with args and returns the process exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "run" {
+		return runCollection(args[1:], stdout, stderr)
+	}
+	// 'bench' is the subcommand spelling of --benchmark, 'ws' of --websocket
+	if len(args) > 0 && args[0] == "bench" {
+		args = append([]string{"--benchmark"}, args[1:]...)
+	}
+	if len(args) > 0 && args[0] == "ws" {
+		args = append([]string{"--websocket"}, args[1:]...)
+	}
+
+	config, err := parseFlags(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if errors.Is(err, errMissingURL) {
+			return exitFailure
+		}
+		return exitUsage
+	}
+
+	// Bodies are indented for people and left byte-for-byte for pipes
+	if !config.Raw && isTerminal(stdout) {
+		config.PrettyPrint = true
+	}
+	config.Colorize = useColor(config.Color, stdout)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	r, err := newRequester(config, stdout, stderr)
+	if err == nil {
+		r.ctx = ctx
+		err = r.execute()
+
+		if config.CookieJarFile != "" {
+			if cookieErr := r.saveCookies(config.CookieJarFile); cookieErr != nil && !config.QuietErrors {
+				fmt.Fprintf(stderr, "Warning: %v\n", cookieErr)
+			}
+		}
+		if r.metrics != nil {
+			if metricsErr := r.metrics.writeFile(config.MetricsFile); metricsErr != nil && !config.QuietErrors {
+				fmt.Fprintf(stderr, "Warning: %v\n", metricsErr)
+			}
+		}
+	}
+	if err != nil {
+		return reportError(config, stderr, err)
+	}
+
+	return 0
+}
+
+// reportError prints err the way config asks for and returns the exit code
+func reportError(config Config, stderr io.Writer, err error) int {
+	code := exitCode(err)
+	switch {
+	case config.QuietErrors:
+	case config.ErrorJSON:
+		writeErrorJSON(stderr, err, code)
+	default:
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+	}
+	return code
+}
+
+func parseFlags(args []string, stderr io.Writer) (Config, error) {
+	var config Config
+	var headers HeaderList
+	var methodHeaders MethodHeaderList
+	var queries QueryList
+	var forms FormList
+	var maskFields MaskFieldList
+	var scopes ScopeList
+	var oauthParams OAuthParamList
+	var jsonPatches PatchList
+	var mergePatches PatchList
+	var jsonFields JSONFieldList
+	var remoteName bool
+	var contentTypes ContentTypeList
+	var expectStatus StatusList
+	var expectHeaders, expectBody ExpectList
+	var expectJSONPath JSONPathExpectList
+	var ciphers CipherList
+	var profileName, configPath string
+	follow := true
+
+	fs := flag.NewFlagSet("http-client", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s [OPTIONS] URL\n", fs.Name())
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&config.Method, "X", "GET", "HTTP method (defaults to POST when a body is given)")
+	fs.StringVar(&config.Method, "method", "GET", "HTTP method (defaults to POST when a body is given)")
+	fs.StringVar(&config.BaseURL, "base-url", "", "Base URL that relative URL arguments like '/users' are joined to")
+	fs.StringVar(&profileName, "profile", "", "Named profile from the config file to take the base URL, headers, auth, timeout and proxy from")
+	fs.StringVar(&configPath, "config", "", "Config file with named profiles (default ~/.go-http-client/config.yaml)")
+	fs.Var(&headers, "H", "Header in 'Key: Value' format")
+	fs.Var(&headers, "header", "Header in 'Key: Value' format")
+	fs.Var(&methodHeaders, "header-for", "Header applied only for some methods, in 'POST,PUT Key: Value' format")
+	fs.Var(&queries, "q", "Query parameter in 'key=value' format")
+	fs.Var(&queries, "query", "Query parameter in 'key=value' format")
+	fs.BoolVar(&config.NoExpand, "no-expand", false, "Send {{env.NAME}} in the URL, headers, query parameters and data literally instead of substituting environment variables")
+	fs.StringVar(&config.Data, "d", "", "Request data (string, @filename, or - for stdin)")
+	fs.StringVar(&config.Data, "data", "", "Request data (string, @filename, or - for stdin)")
+	fs.StringVar(&config.DataHex, "data-hex", "", "Request body as hex-encoded bytes (e.g., 'DEADBEEF')")
+	fs.StringVar(&config.DataBase64, "data-base64", "", "Request body as base64-encoded bytes")
+	fs.Var(&forms, "f", "Form data in 'key=value', 'key=@filename' or 'key=@filename;gzip' format ('-' for stdin)")
+	fs.Var(&forms, "form", "Form data in 'key=value', 'key=@filename' or 'key=@filename;gzip' format ('-' for stdin)")
+	fs.StringVar(&config.FormDir, "form-dir", "", "Add a multipart file part for every file in this directory")
+	fs.StringVar(&config.FormDirPattern, "form-dir-pattern", "*", "Only upload --form-dir files matching this glob (e.g., '*.png')")
+	fs.StringVar(&config.FormDirPrefix, "form-dir-prefix", "", "Prefix for --form-dir part names, which default to the file name")
+	fs.BoolVar(&config.JSON, "json", false, "Send the 'key=value' and 'key:=json' arguments after the URL as a JSON object and ask for JSON back")
+	fs.Var(&jsonFields, "json-field", "JSON body field in 'key=value', 'key:=json', 'key=@file' or 'key:=@file' format (can be used multiple times)")
+	fs.Var(&jsonPatches, "json-patch", "JSON Patch operation in 'op=replace;path=/a/b;value=1' format (can be used multiple times)")
+	fs.Var(&mergePatches, "merge-patch", "JSON Merge Patch member in '/a/b=value' format (can be used multiple times)")
+	fs.DurationVar(&config.Timeout, "t", defaultTimeout, "Request timeout")
+	fs.DurationVar(&config.Timeout, "timeout", defaultTimeout, "Request timeout")
+	fs.Var(&contentTypes, "expect-content-type", "Fail unless the response Content-Type matches this type, wildcards allowed (can be used multiple times)")
+	fs.Var(&expectStatus, "expect-status", "Fail unless the response status is one of these, e.g. '200' or '2xx,304' (can be used multiple times)")
+	fs.Var(&expectHeaders, "expect-header", "Fail unless the response has this header, given as 'Name' or 'Name: value' (can be used multiple times)")
+	fs.Var(&expectBody, "expect-body-contains", "Fail unless the response body contains this text (can be used multiple times)")
+	fs.Var(&expectJSONPath, "expect-jsonpath", "Fail unless the JSON body satisfies this assertion, e.g. '$.ok==true', '$.items[0].id!=0' or '$.token' (can be used multiple times)")
+	fs.BoolVar(&config.EmptyAsError, "empty-as-error", false, "Exit with status 5 when a 2xx response has an empty body")
+	fs.BoolVar(&config.RequireBody, "require-body", false, "Exit with status 5 when any response has an empty body")
+	fs.BoolVar(&config.Fail, "fail", false, "Exit with status 8 for a 4xx and 9 for a 5xx response, without printing it")
+	fs.BoolVar(&config.FailWithBody, "fail-with-body", false, "Like --fail, but print the response before exiting")
+	fs.DurationVar(&config.MaxResponseTime, "max-response-time", 0, "Fail if a request takes longer than this to complete, without aborting it")
+	
+	fs.StringVar(&config.Username, "u", "", "Username for basic authentication, or 'user:password'")
+	fs.StringVar(&config.Username, "user", "", "Username for basic authentication, or 'user:password'")
+	fs.BoolVar(&config.Digest, "digest", false, "Use HTTP Digest authentication (MD5 or SHA-256) with --user instead of Basic")
+	fs.StringVar(&config.Password, "p", "", "Password for basic authentication")
+	fs.StringVar(&config.Password, "password", "", "Password for basic authentication")
+	fs.StringVar(&config.BearerToken, "b", "", "Bearer token for authentication")
+	fs.StringVar(&config.BearerToken, "bearer", "", "Bearer token for authentication")
+	fs.StringVar(&config.BearerCommand, "bearer-command", "", "Command whose output is used as the bearer token")
+	fs.StringVar(&config.ClientID, "client-id", "", "OAuth2 client ID for client credentials flow")
+	fs.StringVar(&config.ClientID, "oauth2-client-id", "", "OAuth2 client ID for client credentials flow")
+	fs.StringVar(&config.ClientSecret, "client-secret", "", "OAuth2 client secret for client credentials flow")
+	fs.StringVar(&config.ClientSecret, "oauth2-client-secret", "", "OAuth2 client secret for client credentials flow")
+	fs.StringVar(&config.TokenURL, "token-url", "", "OAuth2 token endpoint URL")
+	fs.StringVar(&config.TokenURL, "oauth2-token-url", "", "OAuth2 token endpoint URL")
+	fs.BoolVar(&config.OAuthAuthCode, "oauth2-auth-code", false, "Get the OAuth2 token with the authorization code flow (PKCE) by signing in through the browser")
+	fs.StringVar(&config.OAuthAuthURL, "oauth2-auth-url", "", "OAuth2 authorization endpoint URL for --oauth2-auth-code")
+	fs.IntVar(&config.OAuthRedirectPort, "oauth2-redirect-port", 0, "Local port for the --oauth2-auth-code callback (default: a free port)")
+	fs.StringVar(&config.OAuthTokenCache, "oauth2-token-cache", auth.DefaultTokenCache(), "File to cache --oauth2-auth-code tokens in (empty to disable)")
+	fs.StringVar(&config.TokenCertFile, "token-cert", "", "Client certificate for mutual TLS authentication at the OAuth2 token endpoint")
+	fs.StringVar(&config.TokenKeyFile, "token-key", "", "Private key for --token-cert (defaults to the certificate file)")
+	fs.Var(&scopes, "scope", "OAuth2 scope (can be used multiple times)")
+	fs.Var(&scopes, "oauth-scope", "OAuth2 scope (can be used multiple times)")
+	fs.Var(&scopes, "oauth2-scope", "OAuth2 scope (can be used multiple times)")
+	fs.StringVar(&config.OAuthAssertionKey, "oauth-assertion-key", "", "PEM private key (RSA or P-256) for signing a JWT client assertion instead of sending --client-secret")
+	fs.StringVar(&config.OAuthAssertionKID, "oauth-assertion-kid", "", "Key ID (kid) to put in the client assertion header")
+	fs.StringVar(&config.OAuthAudience, "oauth-audience", "", "OAuth2 audience to request the token for")
+	fs.Var(&oauthParams, "oauth-param", "Extra OAuth2 token request parameter in 'key=value' format (can be used multiple times)")
+	fs.StringVar(&config.SignerCommand, "signer-command", "", "Command that reads the canonical request on stdin and prints signing headers")
+	fs.StringVar(&config.AWSSigV4, "aws-sigv4", "", "Sign requests with AWS Signature V4 for region/service (e.g., us-east-1/s3)")
+	fs.StringVar(&config.CustomHeader, "auth-header", "", "Custom authentication header name, or 'Name: value'")
+	fs.StringVar(&config.CustomValue, "auth-value", "", "Custom authentication header value")
+	fs.BoolVar(&config.RetryOnReset, "retry-on-reset", false, "Retry requests whose connection is reset (up to 3 times)")
+	config.RetryJitter = retry.JitterNone
+	fs.Var(&config.RetryJitter, "retry-jitter", "Randomize the retry backoff: none, full or equal")
+	fs.BoolVar(&follow, "follow", true, "Follow redirects")
+	fs.BoolVar(&config.NoFollow, "no-follow", false, "Print redirect responses instead of following them")
+	fs.IntVar(&config.MaxRedirects, "max-redirects", defaultMaxRedirects, "Give up after following this many redirects")
+	fs.BoolVar(&config.ShowRedirects, "show-redirects", false, "Print the status line and Location of each redirect followed to stderr")
+	fs.IntVar(&config.Retry, "retry", 0, "Retry failed requests up to this many times (see --retry-on)")
+	fs.DurationVar(&config.RetryDelay, "retry-delay", time.Second, "Initial delay between --retry attempts, doubled after each one")
+	fs.DurationVar(&config.RetryMaxDelay, "retry-max-delay", 30*time.Second, "Upper bound for the --retry backoff")
+	config.RetryOn, _ = retry.ParseConditions(retry.DefaultConditions)
+	fs.Var(&config.RetryOn, "retry-on", "Failures retried by --retry: status codes (429), classes (5xx) and connection")
+	fs.BoolVar(&config.Paginate, "paginate", false, "Follow Link rel=\"next\" headers (or --next-jsonpath or --page-param) to fetch every page")
+	fs.StringVar(&config.PaginateField, "paginate-field", "", "Dotted JSON path to the next page URL (e.g., 'links.next')")
+	fs.StringVar(&config.PaginateField, "next-jsonpath", "", "JSON path to the next page URL or cursor (e.g., '$.next'), same as --paginate-field")
+	fs.StringVar(&config.CursorParam, "cursor-param", "", "Send the --next-jsonpath value as this query parameter instead of following it as a URL")
+	fs.StringVar(&config.PageParam, "page-param", "", "Paginate by incrementing this page number query parameter until a page has no items")
+	fs.StringVar(&config.PaginateItems, "paginate-items", "", "Dotted JSON path to the array of items in each page (default: the page itself)")
+	fs.BoolVar(&config.PaginateMerge, "paginate-merge", false, "Merge JSON array pages into a single array")
+	fs.BoolVar(&config.PaginateNDJSON, "paginate-ndjson", false, "Stream the items of every page as newline-delimited JSON")
+	fs.IntVar(&config.MaxPages, "max-pages", 0, "Maximum number of pages to fetch with --paginate (0 for no limit)")
+	fs.BoolVar(&config.QuietErrors, "quiet-errors", false, "Don't report errors on stderr; rely on the exit code")
+	fs.BoolVar(&config.ErrorJSON, "error-json", false, "Report errors on stderr as JSON objects with error, category and exit_code")
+	fs.BoolVar(&config.Verbose, "v", false, "Print the outgoing request and connection diagnostics to stderr")
+	fs.BoolVar(&config.Verbose, "verbose", false, "Print the outgoing request and connection diagnostics to stderr")
+	fs.BoolVar(&config.TraceTime, "trace-time", false, "Print how long DNS, connect, TLS, time to first byte and the transfer took for each request to stderr")
+	fs.BoolVar(&config.TraceTimeJSON, "trace-time-json", false, "Like --trace-time, as one JSON object per request")
+	fs.BoolVar(&config.PrintBody, "print-body", false, "Print the assembled request body to stderr before sending it")
+	fs.BoolVar(&config.FoldHeaders, "fold-headers", false, "Print repeated response headers as one comma-separated line (except Set-Cookie)")
+	fs.BoolVar(&config.HeadOnly, "I", false, "Send a HEAD request (unless -X is given) and print only the status and headers")
+	fs.BoolVar(&config.HeadOnly, "head", false, "Send a HEAD request (unless -X is given) and print only the status and headers")
+	fs.BoolVar(&config.Include, "i", false, "Print the status and headers before the body (the default)")
+	fs.BoolVar(&config.Include, "include", false, "Print the status and headers before the body (the default)")
+	fs.StringVar(&config.Color, "color", "auto", "Color the status line, header names and JSON bodies: 'auto' (on a terminal, unless NO_COLOR is set), 'always' or 'never'")
+	fs.BoolVar(&config.BodyOnly, "body-only", false, "Print only the response body, without the status and headers")
+	fs.BoolVar(&config.HeadersJSON, "headers-json", false, "Print the response status and headers as a JSON object")
+	fs.StringVar(&config.OutputFormat, "output-format", "text", "Response output: 'text', or 'json' for one JSON document with the status, headers, timings and body")
+	fs.BoolVar(&config.BodyBase64, "body-base64", false, "With --output-format json, always base64-encode the body (binary bodies always are)")
+	fs.BoolVar(&config.DecodeJWT, "decode-jwt", false, "Decode JWTs found in the response body (or --jwt-header) to stderr")
+	fs.StringVar(&config.JWTHeader, "jwt-header", "", "Response header to search for JWTs with --decode-jwt (e.g., 'Set-Cookie')")
+	fs.StringVar(&config.CookieFile, "cookies", "", "Load cookies saved by --cookie-jar from this JSON file")
+	fs.StringVar(&config.CookieJarFile, "cookie-jar", "", "Save the cookies held at the end of the run to this JSON file")
+	fs.BoolVar(&config.PrintCookies, "print-cookies", false, "Print the cookies held for the response's URL, including ones set by it, to stderr")
+	fs.StringVar(&config.Output, "o", "", "Write the response body to this file instead of stdout")
+	fs.StringVar(&config.Output, "output", "", "Write the response body to this file instead of stdout")
+	fs.StringVar(&config.OutputDir, "output-dir", "", "Save each response body to a file in this directory named after the URL")
+	fs.BoolVar(&remoteName, "O", false, "Save the response body to a file in the current directory named after the URL or Content-Disposition")
+	fs.BoolVar(&config.Progress, "progress", false, "Print the percent sent and upload throughput of the request body to stderr")
+	fs.BoolVar(&config.NoProgress, "no-progress", false, "Don't draw a progress bar on the terminal while saving the response body")
+	fs.IntVar(&config.Repeat, "repeat", 1, "Send the request this many times, one after another")
+	fs.StringVar(&config.OutputPattern, "output-pattern", "", "Save each --repeat response body to its own file, with %d replaced by the iteration (e.g., 'out-%d.json')")
+	fs.BoolVar(&config.RemoveOnInterrupt, "remove-on-interrupt", false, "Delete the partial --output file when interrupted")
+	fs.BoolVar(&config.PrettyPrint, "pretty", false, "Pretty-print JSON and XML responses (the default when stdout is a terminal)")
+	fs.BoolVar(&config.Raw, "raw", false, "Print response bodies as received, even to a terminal")
+	fs.StringVar(&config.ResponseContentType, "response-content-type", "", "Format the response as this type regardless of its Content-Type (e.g., 'application/json')")
+	fs.StringVar(&config.RateLimit, "rate", "", "Rate limit in format 'requests/duration' (e.g., '10/s', '100/30s')")
+	fs.StringVar(&config.RateLimit, "r", "", "Rate limit in format 'requests/duration' (e.g., '10/s', '100/30s')")
+	fs.BoolVar(&config.WebSocket, "websocket", false, "Open a WebSocket connection and exchange messages instead of sending a request")
+	fs.BoolVar(&config.WSBinary, "ws-binary", false, "Send WebSocket messages as binary rather than text frames")
+	fs.IntVar(&config.WSMessages, "ws-messages", 0, "Close the WebSocket after receiving this many messages (0 to wait for the server)")
+	fs.DurationVar(&config.WSPing, "ws-ping", 0, "Send a WebSocket ping at this interval")
+	fs.IntVar(&config.WSCloseCode, "ws-close-code", 1000, "Status code sent when closing the WebSocket")
+	fs.StringVar(&config.ProtoFile, "proto", "", "Descriptor set (protoc --descriptor_set_out) used to send the JSON body as protobuf and show protobuf responses as JSON")
+	fs.StringVar(&config.ProtoMessage, "message", "", "Fully qualified protobuf message type of the request body, with --proto")
+	fs.StringVar(&config.ProtoResponseMessage, "response-message", "", "Protobuf message type of the response, if not the --message type")
+	fs.BoolVar(&config.GRPCWeb, "grpc-web", false, "Frame the --proto body and response as gRPC-web")
+	fs.StringVar(&config.URLFile, "url-file", "", "Send the request to every URL in this file, one per line, instead of the URL argument")
+	fs.IntVar(&config.Parallel, "parallel", 1, "Number of --url-file requests to send at once")
+	fs.BoolVar(&config.Benchmark, "benchmark", false, "Load test the URL and report throughput, latency percentiles and status codes")
+	fs.IntVar(&config.Requests, "n", 100, "Number of requests to send with --benchmark")
+	fs.IntVar(&config.Requests, "requests", 100, "Number of requests to send with --benchmark")
+	fs.IntVar(&config.Concurrency, "c", 10, "Number of concurrent workers with --benchmark")
+	fs.IntVar(&config.Concurrency, "concurrency", 10, "Number of concurrent workers with --benchmark")
+	fs.DurationVar(&config.BenchDuration, "duration", 0, "Send requests for this long with --benchmark instead of a fixed number")
+	fs.BoolVar(&config.RateFromHeaders, "rate-from-headers", false, "Pace requests using the server's X-RateLimit-Remaining and X-RateLimit-Reset headers")
+	fs.StringVar(&config.ShadowURL, "shadow", "", "Mirror each request to this backend and report status or body differences on stderr")
+	fs.StringVar(&config.MetricsFile, "metrics-prom", "", "Write Prometheus textfile metrics for the run to this file")
+	fs.StringVar(&config.RecordDir, "record", "", "Save every request/response pair to this directory")
+	fs.StringVar(&config.ReplayDir, "replay", "", "Serve responses recorded with --record from this directory instead of the network")
+	fs.StringVar(&config.SNI, "sni", "", "Server name to send in the TLS handshake and verify the certificate against, instead of the URL host")
+	fs.StringVar(&config.Proxy, "x", "", "Proxy URL: http://host:port, https://, socks5:// or socks5h:// (default from HTTP_PROXY/HTTPS_PROXY)")
+	fs.StringVar(&config.Proxy, "proxy", "", "Proxy URL: http://host:port, https://, socks5:// or socks5h:// (default from HTTP_PROXY/HTTPS_PROXY)")
+	fs.StringVar(&config.NoProxy, "noproxy", "", "Comma-separated hosts, domains or CIDR ranges to reach without the proxy ('*' for all)")
+	fs.StringVar(&config.UnixSocket, "unix-socket", "", "Connect to this Unix domain socket instead of the URL's host, e.g. /var/run/docker.sock")
+	fs.BoolVar(&config.Insecure, "k", false, "Don't verify the server's TLS certificate")
+	fs.BoolVar(&config.Insecure, "insecure", false, "Don't verify the server's TLS certificate")
+	fs.StringVar(&config.CACert, "cacert", "", "PEM file of CA certificates to trust instead of the system ones")
+	fs.StringVar(&config.ClientCert, "cert", "", "Client certificate (PEM) for mutual TLS")
+	fs.StringVar(&config.ClientKey, "key", "", "Private key (PEM) for --cert, if it isn't in the certificate file")
+	fs.StringVar(&config.TLSMin, "tls-min", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	fs.StringVar(&config.TLSMax, "tls-max", "", "Maximum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	fs.Var(&ciphers, "ciphers", "Comma-separated TLS 1.0-1.2 cipher suites to offer, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	fs.StringVar(&config.CertDir, "cert-dir", "", "Directory of client certificates to choose from by the server's acceptable CAs")
+	fs.DurationVar(&config.DNSCacheTTL, "dns-cache-ttl", 0, "Reuse host lookups for this long within a run (0 disables the cache)")
+	fs.StringVar(&config.HTTPVersion, "http-version", "", "Force HTTP protocol version (1.0 or 1.1)")
+	fs.Var((*ByteSize)(&config.MaxFileSize), "max-filesize", "Refuse responses whose Content-Length exceeds this size (e.g., '10M')")
+	fs.Var((*ByteSize)(&config.MaxHeaderBytes), "max-header-bytes", "Reject responses whose headers exceed this size (e.g., '64K')")
+	fs.DurationVar(&config.TLSHandshakeTimeout, "tls-handshake-timeout", 0, "Maximum time for the TLS handshake (e.g., 2s)")
+	fs.DurationVar(&config.ResponseHeaderTimeout, "response-header-timeout", 0, "Maximum time to wait for the response headers once the request is sent")
+	fs.DurationVar(&config.FirstByteTimeout, "first-byte-timeout", 0, "Maximum time to wait for the first response byte once the request is sent")
+	fs.StringVar(&config.CompressBody, "compress-body", "", "Compress the request body on the fly with 'gzip' or 'zstd' and send it chunked with a matching Content-Encoding")
+	fs.BoolVar(&config.Compressed, "compressed", false, "Ask for a compressed response (gzip, deflate, br, zstd) and decompress it, keeping Content-Encoding in the printed headers")
+	fs.BoolVar(&config.GzipOutput, "gzip-output", false, "Gzip-compress the body saved with -o, -O or --output-dir, adding .gz to the file name")
+	fs.Var(&config.Filter, "filter", "Stream the response line by line, printing only lines that match this regular expression")
+	fs.Var(&config.FilterOut, "filter-out", "Stream the response line by line, dropping lines that match this regular expression")
+	fs.Var(&maskFields, "mask-field", "Replace the value at this JSON path with *** before output, e.g. 'user.password' or 'items[*].token' (can be used multiple times)")
+	fs.BoolVar(&config.TrimTrailingNewline, "trim-trailing-newline", false, "Strip trailing newlines from textual response bodies")
+	fs.BoolVar(&config.EnsureTrailingNewline, "ensure-trailing-newline", false, "End textual response bodies with exactly one newline")
+	fs.StringVar(&config.ErrorOutput, "error-output", "", "Write the body of 4xx and 5xx responses to this file instead ('-' for stderr)")
+	fs.Var(&config.Until, "until", "Repeat the request until the status matches, e.g. 'status==200' or 'status>=200 && status<300'")
+	fs.Var(&config.UntilBody, "until-body", "Repeat the request until the response body matches this regular expression")
+	fs.DurationVar(&config.PollInterval, "poll-interval", time.Second, "Delay between attempts with --until or --until-body")
+	fs.DurationVar(&config.PollTimeout, "poll-timeout", time.Minute, "Give up polling after this long (0 to wait forever)")
+	fs.BoolVar(&config.REPL, "repl", false, "Start an interactive prompt that sends requests relative to the URL, keeping cookies and headers between commands")
+	fs.Var((*ByteSize)(&config.MaxUploadSize), "max-upload-size", "Refuse to upload form files that together exceed this size (e.g., '100M')")
+	fs.BoolVar(&config.TLSInfo, "tls-info", false, "Print the negotiated TLS version, cipher suite, ALPN protocol and session resumption on stderr")
+	fs.BoolVar(&config.CheckCert, "check-cert", false, "Report the server certificate's subject, issuer, SANs and validity on stderr")
+	fs.IntVar(&config.CertMinDays, "cert-min-days", 0, "Fail when the server certificate expires within this many days (implies --check-cert)")
+	fs.BoolVar(&config.CheckCertOnly, "check-cert-only", false, "Only perform the TLS handshake and report the certificate on stdout, without sending the request")
+	fs.Var((*ByteSize)(&config.SpeedLimit), "speed-limit", "Abort when the download is slower than this many bytes per second for --speed-time (e.g., '1K')")
+	fs.DurationVar(&config.SpeedTime, "speed-time", 30*time.Second, "How long the transfer may stay below --speed-limit before it is aborted")
+	fs.Var((*ByteSize)(&config.MaxBody), "max-body", "Abort once more than this many response bytes have been read (e.g., '10M')")
+
+	if err := fs.Parse(args); err != nil {
+		return config, err
+	}
+
+	if fs.NArg() < 1 && config.URLFile == "" {
+		fs.Usage()
+		return config, errMissingURL
+	}
+	if fs.NArg() > 0 && config.URLFile != "" {
+		fmt.Fprintln(stderr, "--url-file cannot be combined with a URL argument")
+		return config, errors.New("conflicting URL flags")
+	}
+
+	if configPath != "" && profileName == "" {
+		fmt.Fprintln(stderr, "--config requires --profile")
+		return config, errors.New("missing profile")
+	}
+	if profileName != "" {
+		if configPath == "" {
+			path, err := defaultProfilePath()
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return config, err
+			}
+			configPath = path
+		}
+		p, err := loadProfile(configPath, profileName)
+		if err == nil {
+			err = applyProfile(&config, p, setFlags(fs), &headers, &scopes)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return config, err
+		}
+	}
+
+	if remoteName && config.OutputDir == "" {
+		config.OutputDir = "."
+	}
+	if config.Output != "" && config.OutputDir != "" {
+		fmt.Fprintln(stderr, "-o cannot be combined with -O or --output-dir")
+		return config, errors.New("conflicting output flags")
+	}
+	dataFlags := 0
+	for _, set := range []bool{config.Data != "", config.DataHex != "", config.DataBase64 != ""} {
+		if set {
+			dataFlags++
+		}
+	}
+	if dataFlags > 1 {
+		fmt.Fprintln(stderr, "Only one of -d/--data, --data-hex and --data-base64 can be used")
+		return config, errors.New("conflicting data flags")
+	}
+	if config.GzipOutput && config.Output == "" && config.OutputDir == "" {
+		fmt.Fprintln(stderr, "--gzip-output requires -o, -O or --output-dir")
+		return config, errors.New("missing output file")
+	}
+	if config.CompressBody != "" && config.CompressBody != "gzip" && config.CompressBody != "zstd" {
+		fmt.Fprintf(stderr, "invalid --compress-body %q (expected 'gzip' or 'zstd')\n", config.CompressBody)
+		return config, errors.New("invalid --compress-body")
+	}
+	if config.Compressed && config.GzipOutput {
+		fmt.Fprintln(stderr, "--compressed cannot be combined with --gzip-output")
+		return config, errors.New("conflicting compression flags")
+	}
+	if config.TrimTrailingNewline && config.EnsureTrailingNewline {
+		fmt.Fprintln(stderr, "--trim-trailing-newline cannot be combined with --ensure-trailing-newline")
+		return config, errors.New("conflicting newline flags")
+	}
+	if config.OutputPattern != "" {
+		if err := validateOutputPattern(config.OutputPattern); err != nil {
+			fmt.Fprintln(stderr, err)
+			return config, err
+		}
+		if config.Output != "" || config.OutputDir != "" {
+			fmt.Fprintln(stderr, "--output-pattern cannot be combined with -o, -O or --output-dir")
+			return config, errors.New("conflicting output flags")
+		}
+	}
+
+	config.NoFollow = config.NoFollow || !follow
+	if config.MaxRedirects < 1 {
+		fmt.Fprintln(stderr, "--max-redirects must be at least 1 (use --no-follow to not follow redirects)")
+		return config, errors.New("invalid --max-redirects")
+	}
+
+	if config.Paginate {
+		switch {
+		case config.PageParam != "" && config.PaginateField != "":
+			fmt.Fprintln(stderr, "--page-param cannot be combined with --next-jsonpath")
+			return config, errors.New("conflicting pagination flags")
+		case config.CursorParam != "" && config.PaginateField == "":
+			fmt.Fprintln(stderr, "--cursor-param requires --next-jsonpath")
+			return config, errors.New("conflicting pagination flags")
+		case config.PaginateMerge && config.PaginateNDJSON:
+			fmt.Fprintln(stderr, "--paginate-merge and --paginate-ndjson cannot be combined")
+			return config, errors.New("conflicting pagination flags")
+		}
+	}
+
+	if config.AWSSigV4 != "" && config.SignerCommand != "" {
+		fmt.Fprintln(stderr, "--aws-sigv4 and --signer-command cannot be combined")
+		return config, errors.New("conflicting signing flags")
+	}
+	if config.AWSSigV4 != "" {
+		if _, err := auth.ParseAWSSigV4(config.AWSSigV4); err != nil {
+			fmt.Fprintln(stderr, err)
+			return config, err
+		}
+	}
+
+	if config.Digest && config.Username == "" {
+		fmt.Fprintln(stderr, "--digest requires --user")
+		return config, errors.New("missing credentials")
+	}
+
+	if config.OAuthAuthCode && (config.ClientID == "" || config.OAuthAuthURL == "" || config.TokenURL == "") {
+		fmt.Fprintln(stderr, "--oauth2-auth-code requires --client-id, --oauth2-auth-url and --token-url")
+		return config, errors.New("missing credentials")
+	}
+
+	if (config.ProtoFile == "") != (config.ProtoMessage == "") || (config.ProtoFile == "" && (config.ProtoResponseMessage != "" || config.GRPCWeb)) {
+		fmt.Fprintln(stderr, "--proto and --message must be given together (--response-message and --grpc-web need both)")
+		return config, errors.New("conflicting protobuf flags")
+	}
+
+	if config.WebSocket && (config.WSCloseCode < 1000 || config.WSCloseCode > 4999) {
+		fmt.Fprintln(stderr, "--ws-close-code must be between 1000 and 4999")
+		return config, errors.New("invalid --ws-close-code")
+	}
+
+	if config.URLFile != "" {
+		switch {
+		case config.Output != "":
+			fmt.Fprintln(stderr, "--url-file cannot be combined with -o (use --output-dir)")
+			return config, errors.New("conflicting output flags")
+		case config.Data == "-" || countStdinForms(forms) > 0:
+			fmt.Fprintln(stderr, "--url-file cannot send a body read from stdin")
+			return config, errors.New("conflicting body flags")
+		case config.Parallel < 1:
+			fmt.Fprintln(stderr, "--parallel must be at least 1")
+			return config, errors.New("invalid --parallel")
+		}
+	}
+
+	if config.Benchmark && (config.Requests < 1 || config.Concurrency < 1 || config.BenchDuration < 0) {
+		fmt.Fprintln(stderr, "--requests and --concurrency must be at least 1 and --duration must not be negative")
+		return config, errors.New("invalid benchmark flags")
+	}
+
+	if config.Retry < 0 {
+		fmt.Fprintln(stderr, "--retry must not be negative")
+		return config, errors.New("invalid --retry")
+	}
+
+	if config.BodyOnly && (config.Include || config.HeadOnly) {
+		fmt.Fprintln(stderr, "--body-only cannot be combined with -i/--include or -I/--head")
+		return config, errors.New("conflicting output flags")
+	}
+
+	if config.Color != "auto" && config.Color != "always" && config.Color != "never" {
+		fmt.Fprintf(stderr, "invalid --color %q (expected 'auto', 'always' or 'never')\n", config.Color)
+		return config, errors.New("invalid --color")
+	}
+
+	switch config.OutputFormat {
+	case "text":
+	case "json":
+		if config.Output != "" || config.OutputDir != "" || config.HeadersJSON {
+			fmt.Fprintln(stderr, "--output-format json cannot be combined with -o, -O, --output-dir or --headers-json")
+			return config, errors.New("conflicting output flags")
+		}
+	default:
+		fmt.Fprintf(stderr, "invalid --output-format %q (expected 'text' or 'json')\n", config.OutputFormat)
+		return config, errors.New("invalid --output-format")
+	}
+
+	if config.Fail && config.FailWithBody {
+		fmt.Fprintln(stderr, "--fail and --fail-with-body cannot be combined")
+		return config, errors.New("conflicting fail flags")
+	}
+
+	if config.PrettyPrint && config.Raw {
+		fmt.Fprintln(stderr, "--pretty and --raw cannot be combined")
+		return config, errors.New("conflicting output flags")
+	}
+
+	if config.ClientCert != "" && config.CertDir != "" {
+		fmt.Fprintln(stderr, "--cert cannot be combined with --cert-dir")
+		return config, errors.New("conflicting certificate flags")
+	}
+
+	if config.TraceTimeJSON {
+		config.TraceTime = true
+	}
+	if config.CheckCertOnly || config.CertMinDays > 0 {
+		config.CheckCert = true
+	}
+
+	methodSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "X" || f.Name == "method" {
+			methodSet = true
+		}
+	})
+
+	config.URL = fs.Arg(0)
+
+	splitCredentials(&config)
+
+	config.Headers = headers
+	config.MethodHeaders = methodHeaders
+	config.Query = queries
+	config.Form = forms
+	config.Scopes = scopes
+	config.OAuthParams = oauthParams
+	config.JSONPatch = jsonPatches
+	config.MergePatch = mergePatches
+	// With --json the arguments after the URL are fields, as in HTTPie
+	if config.JSON && fs.NArg() > 0 {
+		jsonFields = append(jsonFields, fs.Args()[1:]...)
+	}
+	config.JSONFields = jsonFields
+	config.ExpectContentType = contentTypes
+	config.ExpectStatus = expectStatus
+	config.ExpectHeaders = expectHeaders
+	config.ExpectBodyContains = expectBody
+	config.ExpectJSONPath = expectJSONPath
+	config.MaskFields = maskFields
+	config.Ciphers = ciphers
+	if fs.NArg() > 0 {
+		config.Args = fs.Args()[1:]
+	}
+
+	if !config.NoExpand {
+		if err := expandConfig(&config, lookupEnv); err != nil {
+			fmt.Fprintln(stderr, err)
+			return config, err
+		}
+	}
+
+	switch {
+	case methodSet:
+	case config.HeadOnly:
+		config.Method = http.MethodHead
+	default:
+		config.Method = defaultMethod(config)
+	}
+
+	return config, nil
+}
+
+// splitCredentials unpacks the curl-style combined forms --user
+// name:password and --auth-header 'Name: value'
+func splitCredentials(config *Config) {
+	if config.Password == "" {
+		if user, password, found := strings.Cut(config.Username, ":"); found {
+			config.Username, config.Password = user, password
+		}
+	}
+	if config.CustomValue == "" {
+		if name, value, found := strings.Cut(config.CustomHeader, ":"); found {
+			config.CustomHeader, config.CustomValue = strings.TrimSpace(name), strings.TrimSpace(value)
+		}
+	}
+}
+
+// defaultMethod derives the method when -X isn't given: PATCH for patch
+// documents, POST when any other body is present and GET otherwise
+func defaultMethod(config Config) string {
+	switch {
+	case len(config.JSONPatch) > 0 || len(config.MergePatch) > 0:
+		return "PATCH"
+	case config.Data != "" || config.DataHex != "" || config.DataBase64 != "" || len(config.Form) > 0 || config.FormDir != "" || len(config.JSONFields) > 0:
+		return "POST"
+	default:
+		return "GET"
+	}
+}
+
+// execute performs the request, walks every page with --paginate, sends it
+// --repeat times or load tests the URL with --benchmark
+func (r *requester) execute() error {
+	if r.config.REPL {
+		return r.repl(os.Stdin)
+	}
+
+	if r.config.CheckCertOnly {
+		return r.checkCert()
+	}
+
+	if r.config.WebSocket {
+		return r.websocket(os.Stdin)
+	}
+
+	if r.config.URLFile != "" {
+		return r.batch()
+	}
+
+	if r.config.Benchmark {
+		return r.benchmark()
+	}
+
+	if r.config.Paginate {
+		return r.paginate()
+	}
+
+	if r.config.polling() {
+		return r.poll()
+	}
+
+	if r.config.Repeat > 1 || r.config.OutputPattern != "" {
+		return r.repeat()
+	}
+
+	return r.do(r.config.URL, true, r.printResponse)
+}
+
+// requester holds what's shared by every request made in one invocation
+type requester struct {
+	ctx           context.Context
+	config        Config
+	client        *http.Client
+	authenticator auth.Authenticator
+	signer        auth.Signer
+	proto         *protoCodec
+	rand          *rand.Rand
+	metrics       *metrics
+	rateLimiter   *ratelimit.RateLimiter
+	stdout        io.Writer
+	stderr        io.Writer
+}
+
+func newRequester(config Config, stdout, stderr io.Writer) (*requester, error) {
+	if config.BaseURL != "" {
+		joined, err := joinURL(config.BaseURL, config.URL)
+		if err != nil {
+			return nil, err
+		}
+		config.URL = joined
+	}
+
+	// Initialize rate limiter if specified
+	rateLimiter, err := ratelimit.New(config.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limiter: %w", err)
+	}
+
+	client, err := buildHTTPClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Username:         config.Username,
+		Password:         config.Password,
+		Digest:           config.Digest,
+		BearerToken:      config.BearerToken,
+		BearerCommand:    config.BearerCommand,
+		ClientID:         config.ClientID,
+		ClientSecret:     config.ClientSecret,
+		TokenURL:         config.TokenURL,
+		AuthCode:         config.OAuthAuthCode,
+		AuthURL:          config.OAuthAuthURL,
+		RedirectPort:     config.OAuthRedirectPort,
+		TokenCache:       config.OAuthTokenCache,
+		Prompt:           stderr,
+		TokenCertFile:    config.TokenCertFile,
+		TokenKeyFile:     config.TokenKeyFile,
+		TokenCAFile:      config.CACert,
+		TokenInsecure:    config.Insecure,
+		AssertionKeyFile: config.OAuthAssertionKey,
+		AssertionKID:     config.OAuthAssertionKID,
+		Scopes:           config.Scopes,
+		Audience:         config.OAuthAudience,
+		TokenParams:      config.OAuthParams,
+		CustomHeader:     config.CustomHeader,
+		CustomValue:      config.CustomValue,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticator: %w", err)
+	}
+
+	var m *metrics
+	if config.MetricsFile != "" {
+		m = newMetrics()
+		client.Transport = &metricsTransport{base: client.Transport, metrics: m}
+	}
+
+	// Digest credentials are only sent once the server has challenged
+	if digest, ok := authenticator.(*auth.DigestAuth); ok {
+		client.Transport = digest.Transport(client.Transport)
+	}
+
+	var signer auth.Signer
+	if config.SignerCommand != "" {
+		signer = auth.NewCommandSigner(config.SignerCommand)
+	}
+	if config.AWSSigV4 != "" {
+		if signer, err = auth.ParseAWSSigV4(config.AWSSigV4); err != nil {
+			return nil, err
+		}
+	}
+
+	var codec *protoCodec
+	if config.ProtoFile != "" {
+		if codec, err = loadProtoCodec(config); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &requester{
+		ctx:           context.Background(),
+		config:        config,
+		client:        client,
+		authenticator: authenticator,
+		signer:        signer,
+		proto:         codec,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		metrics:       m,
+		rateLimiter:   rateLimiter,
+		stdout:        stdout,
+		stderr:        stderr,
+	}
+	client.CheckRedirect = r.checkRedirect
+	return r, nil
+}
+
+// buildRequest creates the request for rawURL. Only the initial request
+// carries the body and query parameters; follow-up requests such as the
+// next page of a paginated listing use the URL as given.
+func (r *requester) buildRequest(rawURL string, initial bool) (*http.Request, error) {
+	config := r.config
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	var body io.Reader
+	var contentType string
+
+	if initial {
+		body, contentType, err = buildBody(config)
+		if err != nil {
+			return nil, err
+		}
+		if r.proto != nil && body != nil {
+			if body, contentType, err = r.proto.encodeBody(body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The run's context lets an interrupt cancel authentication, such as a
+	// browser sign-in, before the request itself is sent
+	req, err := http.NewRequestWithContext(r.ctx, config.Method, parsedURL.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	switch sized := body.(type) {
+	case *fileBody:
+		req.ContentLength = sized.size
+		req.GetBody = sized.reopen
+	case *stdinBody:
+		req.ContentLength = sized.size
+	case *multipartBody:
+		if sized.size >= 0 {
+			req.ContentLength = sized.size
+		}
+		if sized.canReopen() {
+			req.GetBody = sized.reopen
+		}
+	}
+
+	if config.CompressBody != "" {
+		compressRequestBody(req, config.CompressBody)
+	}
+
+	if err := setRequestProto(req, config.HTTPVersion); err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.proto != nil && r.proto.grpcWeb {
+		req.Header.Set("X-Grpc-Web", "1")
+	}
+	if config.JSON {
+		req.Header.Set("Accept", jsonAccept)
+	}
+
+	addHeaders(req, config.Headers)
+	addMethodHeaders(req, config.MethodHeaders)
+	if config.GzipOutput {
+		requestGzip(req)
+	}
+	if config.Compressed && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", transport.AcceptEncoding)
+	}
+	if initial {
+		addQueryParams(req, config.Query)
+	}
+
+	// With --verbose the body follows the headers, which aren't final yet
+	if config.PrintBody && !config.Verbose {
+		if err := printRequestBody(r.stderr, req); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.authenticator != nil {
+		if err := r.authenticator.Apply(req); err != nil {
+			return nil, fmt.Errorf("failed to apply authentication: %w", err)
+		}
+	}
+
+	if r.signer != nil {
+		if err := signRequest(r.signer, req); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+func buildBody(config Config) (io.Reader, string, error) {
+	if len(config.JSONPatch) > 0 && len(config.MergePatch) > 0 {
+		return nil, "", fmt.Errorf("--json-patch and --merge-patch cannot be combined")
+	}
+
+	if len(config.JSONPatch) > 0 {
+		patch, err := buildJSONPatch(config.JSONPatch)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(patch), jsonPatchContentType, nil
+	}
+
+	if len(config.MergePatch) > 0 {
+		patch, err := buildMergePatch(config.MergePatch)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(patch), mergePatchContentType, nil
+	}
+
+	if len(config.JSONFields) > 0 {
+		body, err := buildJSONFields(config.JSONFields)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(body), "application/json", nil
+	}
+
+	forms := config.Form
+	if config.FormDir != "" {
+		dirForms, err := expandFormDir(config.FormDir, config.FormDirPattern, config.FormDirPrefix)
+		if err != nil {
+			return nil, "", err
+		}
+		forms = append(append([]string{}, forms...), dirForms...)
+	}
+
+	if len(forms) > 0 {
+		body, contentType, err := buildFormData(forms, config.MaxUploadSize)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to build form data: %w", err)
+		}
+		return body, contentType, nil
+	}
+
+	if config.DataHex != "" {
+		data, err := decodeHex(config.DataHex)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), "", nil
+	}
+
+	if config.DataBase64 != "" {
+		data, err := decodeBase64(config.DataBase64)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), "", nil
+	}
+
+	if config.Data != "" {
+		body, err := buildRequestBody(config.Data)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to build request body: %w", err)
+		}
+		return body, "", nil
+	}
+
+	return nil, "", nil
+}
+
+// do sends a request to rawURL and hands the response to handle before the
+// request's context is released
+func (r *requester) do(rawURL string, initial bool, handle func(*http.Response) error) error {
+	retryReset := r.config.RetryOnReset && canRetryBody(r.config)
+	retries := 0
+	if canRetryBody(r.config) {
+		retries = r.config.Retry
+	}
+	// A connection dropped mid-body is only retryable if nothing was printed
+	buffer := retryReset || (retries > 0 && r.config.RetryOn.Connection)
+
+	for attempt := 1; ; attempt++ {
+		err := r.send(rawURL, initial, buffer, r.retryStatus(r.failOnStatus(handle), attempt <= retries))
+		if err == nil {
+			return nil
+		}
+
+		var delay time.Duration
+		var status *retryStatusError
+		switch {
+		case errors.As(err, &status):
+			fmt.Fprintf(r.stderr, "Received %s, retrying (%d/%d)\n", status.status, attempt, retries)
+			delay = r.retryBackoff(attempt, status.retryAfter)
+		case attempt <= retries && r.config.RetryOn.Error(err):
+			fmt.Fprintf(r.stderr, "Request failed, retrying (%d/%d): %v\n", attempt, retries, err)
+			delay = r.retryBackoff(attempt, 0)
+		case retryReset && attempt <= resetRetries && isConnectionReset(err):
+			fmt.Fprintf(r.stderr, "Connection reset, retrying (%d/%d): %v\n", attempt, resetRetries, err)
+			delay = retry.Delay(attempt, resetBaseDelay, resetMaxDelay, r.config.RetryJitter, r.rand)
+		default:
+			return err
+		}
+		r.metrics.observeRetry()
+
+		select {
+		case <-time.After(delay):
+		case <-r.ctx.Done():
+			return interruptedError(0)
+		}
+	}
+}
+
+func (r *requester) send(rawURL string, initial, buffer bool, handle func(*http.Response) error) error {
+	req, err := r.buildRequest(rawURL, initial)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.Timeout)
+	defer cancel()
+
+	// Apply rate limiting
+	if r.rateLimiter.IsEnabled() {
+		waitStart := time.Now()
+		if err := r.rateLimiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit wait failed: %w", err)
+		}
+		r.metrics.observeRateLimitWait(time.Since(waitStart))
+	}
+
+	// The shadow request is started before the verbose trace is attached so
+	// its connection doesn't show up in the report
+	var shadow <-chan shadowResult
+	if r.config.ShadowURL != "" {
+		if shadow, err = r.startShadow(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	if r.config.FirstByteTimeout > 0 {
+		var cancelPhase context.CancelCauseFunc
+		ctx, cancelPhase = context.WithCancelCause(ctx)
+		defer cancelPhase(nil)
+
+		trace, stop := firstByteTrace(r.config.FirstByteTimeout, cancelPhase)
+		defer stop()
+		ctx = httptrace.WithClientTrace(ctx, trace)
+	}
+
+	var timer phaseTimer
+	if r.config.TraceTime || r.config.OutputFormat == "json" || r.config.Benchmark {
+		ctx = httptrace.WithClientTrace(ctx, timer.clientTrace())
+		ctx = context.WithValue(ctx, phaseTimerKey{}, &timer)
+	}
+
+	var conn connInfo
+	if r.config.Verbose {
+		ctx = httptrace.WithClientTrace(ctx, conn.clientTrace())
+
+		printRequestHead(r.stderr, req)
+		if r.config.PrintBody {
+			if err := printRequestBody(r.stderr, req); err != nil {
+				return err
+			}
+		}
+	}
+	req = req.WithContext(ctx)
+
+	if r.config.Progress && req.Body != nil && req.Body != http.NoBody {
+		req.Body = newProgressBody(req.Body, r.stderr, req.ContentLength)
+	}
+
+	stats := RequestStats{Start: time.Now()}
+	timer.start = stats.Start
+	resp, err := r.client.Do(req)
+	if err != nil {
+		if r.ctx.Err() != nil {
+			return interruptedError(0)
+		}
+		if phaseErr := phaseTimeoutError(ctx, err, r.config); phaseErr != nil {
+			return phaseErr
+		}
+		if isHeaderLimitError(err) {
+			return fmt.Errorf("response headers exceed --max-header-bytes of %d bytes: %w", r.config.MaxHeaderBytes, err)
+		}
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if r.config.RateFromHeaders {
+		r.rateLimiter.ObserveHeaders(resp.Header)
+	}
+
+	if r.config.Verbose && conn.got {
+		fmt.Fprintf(r.stderr, "* Connection: %s\n", &conn)
+	}
+	if r.config.TLSInfo {
+		fmt.Fprintf(r.stderr, "* TLS: %s\n", tlsInfo(resp.TLS))
+	}
+
+	if err := checkContentLength(resp, r.config.MaxFileSize); err != nil {
+		return err
+	}
+	if limit := bodyLimit(resp, r.config.MaxFileSize, r.config.MaxBody); limit > 0 {
+		resp.Body = newLimitedBody(resp.Body, limit)
+	}
+
+	var guard *speedGuard
+	if r.config.SpeedLimit > 0 {
+		guard = newSpeedGuard(resp.Body, r.config.SpeedLimit, r.config.SpeedTime, cancel)
+		resp.Body = guard
+		defer guard.Close()
+	}
+
+	received := &receivedBody{ReadCloser: resp.Body}
+	resp.Body = received
+
+	var body []byte
+	if buffer || shadow != nil || r.config.expectsBody() {
+		if body, err = bufferBody(resp); err != nil {
+			if r.ctx.Err() != nil {
+				return interruptedError(received.n)
+			}
+			if guard != nil && guard.err() != nil {
+				return guard.err()
+			}
+			return err
+		}
+	}
+
+	if err := handle(resp); err != nil {
+		if r.ctx.Err() != nil {
+			return interruptedError(received.n)
+		}
+		if guard != nil && guard.err() != nil {
+			return guard.err()
+		}
+		return err
+	}
+	stats.Total = time.Since(stats.Start)
+
+	if r.config.TraceTime {
+		timings := timer.timings(stats.Start.Add(stats.Total))
+		if r.config.TraceTimeJSON {
+			if err := writeTimingsJSON(r.stderr, req.URL.String(), timings); err != nil {
+				return err
+			}
+		} else {
+			writeTimings(r.stderr, timings)
+		}
+	}
+
+	if shadow != nil {
+		reportShadow(r.stderr, resp.StatusCode, body, <-shadow)
+	}
+
+	if err := checkContentType(resp, r.config.ExpectContentType); err != nil {
+		return err
+	}
+	expectBody := body
+	if r.config.GzipOutput && r.config.expectsBody() && gzipEncoded(resp) {
+		if expectBody, err = gunzipBody(body); err != nil {
+			return err
+		}
+	}
+	if err := checkExpectations(resp, expectBody, r.config); err != nil {
+		return err
+	}
+	if err := checkEmptyBody(resp, received.n, r.config.EmptyAsError, r.config.RequireBody); err != nil {
+		return err
+	}
+	if r.config.CheckCert {
+		if err := reportCert(r.stderr, resp.TLS, r.config.CertMinDays, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return checkResponseTime(stats, r.config.MaxResponseTime)
+}
+
+func (r *requester) printResponse(resp *http.Response) error {
+	if r.config.OutputFormat == "json" {
+		return r.printResponseJSON(resp)
+	}
+
+	if err := r.printHeaders(resp); err != nil {
+		return err
+	}
+	if r.config.HeadOnly {
+		return nil
+	}
+
+	// The decoded body is shown before a failed gRPC status is reported
+	if r.proto != nil {
+		if err := r.proto.decodeResponse(resp); err != nil {
+			return err
+		}
+		if err := r.printBody(resp); err != nil {
+			return err
+		}
+		return grpcStatusError(resp)
+	}
+	return r.printBody(resp)
+}
+
+// printBody saves the response body or writes it to stdout, formatted
+func (r *requester) printBody(resp *http.Response) error {
+	if r.config.ErrorOutput != "" && isErrorStatus(resp.StatusCode) {
+		return r.saveErrorBody(resp)
+	}
+
+	saving := r.config.Output != "" || r.config.OutputDir != ""
+	if saving && r.showDownloadProgress() {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{newProgressReader(resp.Body, r.stderr, resp.ContentLength), resp.Body}
+	}
+
+	filtering := r.config.Filter.Regexp != nil || r.config.FilterOut.Regexp != nil
+	if filtering {
+		resp.Body = newLineFilter(resp.Body, r.config.Filter, r.config.FilterOut)
+	}
+
+	if saving {
+		return r.saveBody(resp)
+	}
+
+	// Filtered lines are written as they arrive rather than formatted as a
+	// whole, which would hold the entire body in memory
+	if filtering {
+		if _, err := io.Copy(r.stdout, resp.Body); err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil
+	}
+
+	formattedBody, err := r.formatBody(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(r.stdout, string(formattedBody))
+	return nil
+}
+
+func (r *requester) printHeaders(resp *http.Response) error {
+	switch {
+	case r.config.BodyOnly:
+	case r.config.HeadersJSON:
+		if err := writeHeadersJSON(r.stdout, resp); err != nil {
+			return fmt.Errorf("failed to write headers: %w", err)
+		}
+	default:
+		status, name, reset := "", "", ""
+		if r.config.Colorize {
+			status, name, reset = statusColor(resp.StatusCode), ansiHeader, ansiReset
+		}
+		fmt.Fprintf(r.stdout, "%s%s %s%s\n", status, resp.Proto, resp.Status, reset)
+		for key, values := range resp.Header {
+			// Set-Cookie values may contain commas, so they're never folded
+			if r.config.FoldHeaders && len(values) > 1 && key != "Set-Cookie" {
+				fmt.Fprintf(r.stdout, "%s%s%s: %s\n", name, key, reset, strings.Join(values, ", "))
+				continue
+			}
+			for _, value := range values {
+				fmt.Fprintf(r.stdout, "%s%s%s: %s\n", name, key, reset, value)
+			}
+		}
+		fmt.Fprintln(r.stdout)
+	}
+
+	if r.config.PrintCookies {
+		printCookies(r.stderr, r.jarCookies(resp))
+	}
+
+	if r.config.DecodeJWT {
+		if err := printJWTs(r.stderr, resp, r.config.JWTHeader); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *requester) formatBody(resp *http.Response) ([]byte, error) {
+	var formatter response.Formatter
+	if r.config.PrettyPrint {
+		formatter = response.NewPrettyFormatter()
+	} else {
+		formatter = response.NewRawFormatter()
+	}
+
+	// The override only steers the formatter; the printed headers keep the
+	// server's Content-Type
+	if r.config.ResponseContentType != "" {
+		override := *resp
+		override.Header = resp.Header.Clone()
+		override.Header.Set("Content-Type", r.config.ResponseContentType)
+		resp = &override
+	}
+
+	transformers, err := r.transformers()
+	if err != nil {
+		return nil, err
+	}
+	if len(transformers) > 0 {
+		formatter = response.NewTransformingFormatter(formatter, transformers...)
+	}
+
+	if r.config.Colorize {
+		formatter = response.NewColorFormatter(formatter)
+	}
+
+	formattedBody, err := formatter.Format(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return formattedBody, nil
+}
+
+func buildRequestBody(data string) (io.Reader, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	if data == "-" {
+		return openStdinBody(), nil
+	}
+
+	if strings.HasPrefix(data, "@") {
+		return openFileBody(data[1:])
+	}
+
+	return strings.NewReader(data), nil
+}
+
+func buildFormData(forms []string, maxUploadSize int64) (io.Reader, string, error) {
+	if countStdinForms(forms) > 1 {
+		return nil, "", fmt.Errorf("only one form field can be read from stdin")
+	}
+	if err := checkUploadSize(forms, maxUploadSize); err != nil {
+		return nil, "", err
+	}
+
+	parts, err := parseFormParts(forms)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The boundary is picked here so the Content-Type, the length and any
+	// resent copy of the body agree on it
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	body := newMultipartBody(parts, boundary)
+	return body, "multipart/form-data; boundary=" + boundary, nil
+}
+
+func addHeaders(req *http.Request, headers []string) {
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) == 2 {
+			key := strings.TrimSpace(parts[0])
+			value := strings.TrimSpace(parts[1])
+			// net/http sends req.Host and ignores a Host entry in the header map
+			if strings.EqualFold(key, "Host") {
+				req.Host = value
+				continue
+			}
+			req.Header.Set(key, value)
+		}
+	}
+}
+
+// addMethodHeaders applies "METHOD[,METHOD...] Key: Value" headers when the
+// request method is one of the listed methods
+func addMethodHeaders(req *http.Request, headers []string) {
+	for _, header := range headers {
+		methods, header, found := strings.Cut(strings.TrimSpace(header), " ")
+		if !found {
+			continue
+		}
+		for _, method := range strings.Split(methods, ",") {
+			if strings.EqualFold(strings.TrimSpace(method), req.Method) {
+				addHeaders(req, []string{header})
+				break
+			}
+		}
+	}
+}
+
+func addQueryParams(req *http.Request, queries []string) {
+	q := req.URL.Query()
+	for _, query := range queries {
+		parts := strings.SplitN(query, "=", 2)
+		if len(parts) == 2 {
+			q.Add(parts[0], parts[1])
+		}
+	}
+	req.URL.RawQuery = q.Encode()
+}
\ No newline at end of file
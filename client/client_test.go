@@ -0,0 +1,349 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRequester returns a requester that writes to buffers instead of the
+// process's stdout and stderr
+func newTestRequester(t *testing.T, config Config) (*requester, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	if config.Method == "" {
+		config.Method = "GET"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Second
+	}
+
+	var stdout, stderr bytes.Buffer
+	r, err := newRequester(config, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Failed to create requester: %v", err)
+	}
+	return r, &stdout, &stderr
+}
+
+func TestRunUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+	}{
+		{"Missing URL", nil, exitFailure},
+		{"Unknown flag", []string{"--no-such-flag", "http://example.test"}, exitUsage},
+		{"Help", []string{"--help"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := Run(tt.args, &stdout, &stderr); code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, code)
+			}
+			if !strings.Contains(stderr.String(), "Usage:") {
+				t.Errorf("Expected usage on stderr, got: %s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestAddMethodHeaders(t *testing.T) {
+	headers := []string{
+		"POST Content-Type: application/json",
+		"GET,HEAD Accept: text/html",
+		"put,patch X-Idempotency-Key: abc",
+	}
+
+	tests := []struct {
+		method   string
+		expected map[string]string
+	}{
+		{"POST", map[string]string{"Content-Type": "application/json", "Accept": "", "X-Idempotency-Key": ""}},
+		{"GET", map[string]string{"Content-Type": "", "Accept": "text/html", "X-Idempotency-Key": ""}},
+		{"HEAD", map[string]string{"Content-Type": "", "Accept": "text/html", "X-Idempotency-Key": ""}},
+		{"PATCH", map[string]string{"Content-Type": "", "Accept": "", "X-Idempotency-Key": "abc"}},
+		{"DELETE", map[string]string{"Content-Type": "", "Accept": "", "X-Idempotency-Key": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "http://example.test", nil)
+			addMethodHeaders(req, headers)
+
+			for key, want := range tt.expected {
+				if got := req.Header.Get(key); got != want {
+					t.Errorf("Expected %s %q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMethodHeaderOverridesGeneralHeader(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	args := []string{
+		"-X", "POST",
+		"-H", "Content-Type: text/plain",
+		"--header-for", "POST Content-Type: application/json",
+		"-d", "{}",
+		server.URL,
+	}
+	if code := Run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("Request failed: %s", stderr.String())
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected method-scoped Content-Type, got %q", contentType)
+	}
+}
+
+func TestMethodHeaderListValidation(t *testing.T) {
+	var list MethodHeaderList
+	for _, value := range []string{"Content-Type: application/json", "POST", "POST NoColon"} {
+		if err := list.Set(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+	if err := list.Set("POST Content-Type: application/json"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDefaultMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"No body", nil, "GET"},
+		{"Data", []string{"-d", "payload"}, "POST"},
+		{"Form", []string{"-f", "a=1"}, "POST"},
+		{"JSON field", []string{"--json-field", "a=1"}, "POST"},
+		{"JSON patch", []string{"--json-patch", "op=remove;path=/a"}, "PATCH"},
+		{"Merge patch", []string{"--merge-patch", "/a=1"}, "PATCH"},
+		{"Explicit -X wins", []string{"-X", "PUT", "-d", "payload"}, "PUT"},
+		{"Explicit --method wins", []string{"--method", "GET", "-d", "payload"}, "GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			config, err := parseFlags(append(tt.args, "http://example.test"), &stderr)
+			if err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+			if config.Method != tt.expected {
+				t.Errorf("Expected method %s, got %s", tt.expected, config.Method)
+			}
+		})
+	}
+}
+
+func TestTimeoutFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected time.Duration
+	}{
+		{"Default", nil, defaultTimeout},
+		{"Short form", []string{"-t", "5s"}, 5 * time.Second},
+		{"Long form", []string{"--timeout", "2m"}, 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			config, err := parseFlags(append(tt.args, "http://example.test"), &stderr)
+			if err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+			if config.Timeout != tt.expected {
+				t.Errorf("Expected timeout %s, got %s", tt.expected, config.Timeout)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	Run([]string{"--help"}, &stdout, &stderr)
+	help := stdout.String() + stderr.String()
+	if strings.Count(help, "Request timeout (default "+defaultTimeout.String()+")") != 2 {
+		t.Errorf("Expected -t and --timeout to show the same default, got:\n%s", help)
+	}
+}
+
+func TestAuthFlags(t *testing.T) {
+	var authorization, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		apiKey = r.Header.Get("X-API-Key")
+	}))
+	defer server.Close()
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("client_id") != "id" || r.PostForm.Get("scope") != "read" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"access_token":"oauth-token","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	tests := []struct {
+		name          string
+		args          []string
+		authorization string
+		apiKey        string
+	}{
+		{"User with password", []string{"--user", "ann:s3cret"}, "Basic YW5uOnMzY3JldA==", ""},
+		{"User and password flags", []string{"-u", "ann", "-p", "s3cret"}, "Basic YW5uOnMzY3JldA==", ""},
+		{"Bearer", []string{"--bearer", "abc"}, "Bearer abc", ""},
+		{"OAuth2 aliases", []string{"--oauth2-client-id", "id", "--oauth2-client-secret", "secret", "--oauth2-token-url", tokenServer.URL, "--oauth2-scope", "read"}, "Bearer oauth-token", ""},
+		{"Auth header with value", []string{"--auth-header", "X-API-Key: k1"}, "", "k1"},
+		{"Auth header and value flags", []string{"--auth-header", "X-API-Key", "--auth-value", "k2"}, "", "k2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorization, apiKey = "", ""
+
+			var stdout, stderr bytes.Buffer
+			if code := Run(append(tt.args, server.URL), &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+			}
+			if authorization != tt.authorization {
+				t.Errorf("Expected Authorization %q, got %q", tt.authorization, authorization)
+			}
+			if apiKey != tt.apiKey {
+				t.Errorf("Expected X-API-Key %q, got %q", tt.apiKey, apiKey)
+			}
+		})
+	}
+}
+
+func TestDigestAuthFlag(t *testing.T) {
+	var challenged int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Digest ") {
+			challenged++
+			w.Header().Set("WWW-Authenticate", `Digest realm="api", nonce="n1", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), `username="ann"`) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("welcome"))
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--digest", "--user", "ann:s3cret", "--body-only", server.URL}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if stdout.String() != "welcome" || challenged != 1 {
+		t.Errorf("Expected one challenge then the response, got %q after %d challenges", stdout.String(), challenged)
+	}
+
+	stderr.Reset()
+	if code := Run([]string{"--digest", server.URL}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d without --user, got %d", exitUsage, code)
+	}
+	if !strings.Contains(stderr.String(), "--digest requires --user") {
+		t.Errorf("Expected a usage error, got %q", stderr.String())
+	}
+}
+
+func TestOAuth2AuthCodeFlags(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"--oauth2-auth-code", "--client-id", "app", "--token-url", "https://auth.example.com/token", "https://api.example.com"}, &stdout, &stderr)
+	if code != exitUsage {
+		t.Errorf("Expected exit code %d without --oauth2-auth-url, got %d", exitUsage, code)
+	}
+	if !strings.Contains(stderr.String(), "--oauth2-auth-code requires") {
+		t.Errorf("Expected a usage error, got %q", stderr.String())
+	}
+}
+
+// authFunc adapts a function to auth.Authenticator
+type authFunc func(*http.Request) error
+
+func (f authFunc) Apply(req *http.Request) error { return f(req) }
+
+func TestAuthenticationUsesRunContext(t *testing.T) {
+	r, _, _ := newTestRequester(t, Config{URL: "http://127.0.0.1:1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.ctx = ctx
+	r.authenticator = authFunc(func(req *http.Request) error {
+		return req.Context().Err()
+	})
+
+	if _, err := r.buildRequest(r.config.URL, true); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected authentication to see the interrupted run, got %v", err)
+	}
+}
+
+func TestOutputControls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		args    []string
+		method  string
+		headers bool
+		body    bool
+	}{
+		{"Default", nil, "GET", true, true},
+		{"Include", []string{"-i"}, "GET", true, true},
+		{"Head", []string{"-I"}, "HEAD", true, false},
+		{"Head with explicit method", []string{"--head", "-X", "GET"}, "GET", true, false},
+		{"Body only", []string{"--body-only"}, "GET", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := Run(append(tt.args, server.URL), &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+			}
+			output := stdout.String()
+			if headers := strings.HasPrefix(output, "HTTP/1.1 200 OK\n"); headers != tt.headers {
+				t.Errorf("Expected headers printed %t, got %q", tt.headers, output)
+			}
+			if tt.headers && !strings.Contains(output, "X-Method: "+tt.method+"\n") {
+				t.Errorf("Expected %s request, got %q", tt.method, output)
+			}
+			if body := strings.HasSuffix(output, "hello"); body != tt.body {
+				t.Errorf("Expected body printed %t, got %q", tt.body, output)
+			}
+			if !tt.headers && output != "hello" {
+				t.Errorf("Expected only the body, got %q", output)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--body-only", "-I", server.URL}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for --body-only with -I, got %d", exitUsage, code)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// loadClientCertificates loads every keypair in dir. Certificates are read
+// from .crt, .cer and .pem files, with the key taken from a sibling file
+// with a .key extension or, failing that, from the certificate file itself.
+func loadClientCertificates(dir string) ([]tls.Certificate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".crt", ".cer", ".pem":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var certs []tls.Certificate
+	for _, name := range names {
+		certFile := filepath.Join(dir, name)
+		keyFile := strings.TrimSuffix(certFile, filepath.Ext(certFile)) + ".key"
+		if _, err := os.Stat(keyFile); err != nil {
+			keyFile = certFile
+		}
+
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %s: %w", certFile, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no client certificates found in %s", dir)
+	}
+
+	return certs, nil
+}
+
+// selectClientCertificate returns a GetClientCertificate callback that picks
+// the first certificate the server's CertificateRequest accepts, matching
+// issuers against its list of acceptable CAs. When none match no
+// certificate is sent and the server decides whether to continue.
+func selectClientCertificate(certs []tls.Certificate) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		for i := range certs {
+			if err := cri.SupportsCertificate(&certs[i]); err == nil {
+				return &certs[i], nil
+			}
+		}
+		return &tls.Certificate{}, nil
+	}
+}
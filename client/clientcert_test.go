@@ -0,0 +1,124 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeClientCertDir(t *testing.T, certA, certB *testCert) string {
+	t.Helper()
+	dir := t.TempDir()
+	certA.writeFiles(t, filepath.Join(dir, "a.crt"), filepath.Join(dir, "a.key"))
+
+	// The second pair is a combined PEM holding both certificate and key
+	combined := append(append([]byte{}, certB.certPEM...), certB.keyPEM...)
+	if err := os.WriteFile(filepath.Join(dir, "b.pem"), combined, 0600); err != nil {
+		t.Fatalf("Failed to write combined PEM: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "README.txt"), []byte("ignored"), 0600)
+	return dir
+}
+
+func TestSelectClientCertificateByAcceptableCA(t *testing.T) {
+	caA := newTestCert(t, "CA A", nil, nil)
+	caB := newTestCert(t, "CA B", nil, nil)
+	clientA := newTestCert(t, "client-a", caA, nil)
+	clientB := newTestCert(t, "client-b", caB, nil)
+
+	certs, err := loadClientCertificates(writeClientCertDir(t, clientA, clientB))
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("Expected 2 certificates, got %d", len(certs))
+	}
+
+	selectCert := selectClientCertificate(certs)
+	tests := []struct {
+		name     string
+		ca       *testCert
+		expected string
+	}{
+		{"Server asks for CA A", caA, "client-a"},
+		{"Server asks for CA B", caB, "client-b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := selectCert(&tls.CertificateRequestInfo{
+				AcceptableCAs:    [][]byte{tt.ca.cert.RawSubject},
+				SignatureSchemes: []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+				Version:          tls.VersionTLS13,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cert.Leaf == nil || cert.Leaf.Subject.CommonName != tt.expected {
+				t.Errorf("Expected %s to be selected, got %+v", tt.expected, cert.Leaf)
+			}
+		})
+	}
+
+	unknownCA := newTestCert(t, "CA C", nil, nil)
+	cert, err := selectCert(&tls.CertificateRequestInfo{
+		AcceptableCAs:    [][]byte{unknownCA.cert.RawSubject},
+		SignatureSchemes: []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+		Version:          tls.VersionTLS13,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cert.Certificate) != 0 {
+		t.Error("Expected no certificate when no issuer matches")
+	}
+}
+
+func TestCertDirHandshake(t *testing.T) {
+	caA := newTestCert(t, "CA A", nil, nil)
+	caB := newTestCert(t, "CA B", nil, nil)
+	clientA := newTestCert(t, "client-a", caA, nil)
+	clientB := newTestCert(t, "client-b", caB, nil)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caB.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	client, err := buildHTTPClient(Config{CertDir: writeClientCertDir(t, clientA, clientB)})
+	if err != nil {
+		t.Fatalf("Failed to build client: %v", err)
+	}
+	transport := client.Transport.(*http.Transport)
+	transport.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	client.Timeout = 5 * time.Second
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "client-b" {
+		t.Errorf("Expected server to receive client-b, got %q", body)
+	}
+}
+
+func TestLoadClientCertificatesEmptyDir(t *testing.T) {
+	if _, err := loadClientCertificates(t.TempDir()); err == nil {
+		t.Error("Expected error for directory without certificates")
+	}
+}
@@ -0,0 +1,293 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// collection is a file of named requests for 'http-client run'. JSON files
+// are read with the YAML decoder, since JSON is a subset of YAML.
+type collection struct {
+	BaseURL  string              `yaml:"base_url"`
+	Headers  []string            `yaml:"headers"`
+	Requests []collectionRequest `yaml:"requests"`
+}
+
+type collectionRequest struct {
+	Name    string            `yaml:"name"`
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers []string          `yaml:"headers"`
+	Query   []string          `yaml:"query"`
+	Body    any               `yaml:"body"`
+	Auth    profileAuth       `yaml:"auth"`
+	Capture map[string]string `yaml:"capture"`
+
+	captures map[string]capture
+}
+
+// variablePattern is what a captured variable may be called, so that it
+// can be referenced as {{name}}
+var variablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// loadCollection reads and checks a collection file. Requests without a
+// name are called "request N" after their position.
+func loadCollection(path string) (*collection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection: %w", err)
+	}
+
+	var c collection
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse collection %s: %w", path, err)
+	}
+	if len(c.Requests) == 0 {
+		return nil, fmt.Errorf("collection %s has no requests", path)
+	}
+
+	seen := make(map[string]bool)
+	for i := range c.Requests {
+		req := &c.Requests[i]
+		if req.Name == "" {
+			req.Name = fmt.Sprintf("request %d", i+1)
+		}
+		if seen[req.Name] {
+			return nil, fmt.Errorf("collection %s has more than one request named %q", path, req.Name)
+		}
+		seen[req.Name] = true
+		if req.URL == "" {
+			return nil, fmt.Errorf("request %q has no url", req.Name)
+		}
+
+		req.captures = make(map[string]capture, len(req.Capture))
+		for name, spec := range req.Capture {
+			if !variablePattern.MatchString(name) || strings.HasPrefix(name, "env.") {
+				return nil, fmt.Errorf("request %q captures into invalid variable name %q", req.Name, name)
+			}
+			c, err := parseCapture(spec)
+			if err != nil {
+				return nil, fmt.Errorf("request %q: %w", req.Name, err)
+			}
+			req.captures[name] = c
+		}
+	}
+	return &c, nil
+}
+
+// selectRequests returns the named requests in the order given, or every
+// request in file order when no names are given
+func (c *collection) selectRequests(names []string) ([]collectionRequest, error) {
+	if len(names) == 0 {
+		return c.Requests, nil
+	}
+
+	selected := make([]collectionRequest, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, req := range c.Requests {
+			if req.Name == name {
+				selected = append(selected, req)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no request named %q in the collection", name)
+		}
+	}
+	return selected, nil
+}
+
+// requestConfig builds the Config for one request on top of the options
+// given on the command line. Headers are applied from the most general to
+// the most specific: the collection's, then the request's, then -H.
+// {{name}} references resolve to the variables captured so far or, for
+// {{env.NAME}}, to the environment.
+func (c *collection) requestConfig(req collectionRequest, defaults Config, vars map[string]string) (Config, error) {
+	config := defaults
+	config.URL = req.URL
+	if config.BaseURL == "" {
+		config.BaseURL = c.BaseURL
+	}
+
+	var headers []string
+	switch body := req.Body.(type) {
+	case nil:
+	case string:
+		config.Data = body
+	default:
+		// A mapping or list is sent as JSON
+		data, err := json.Marshal(body)
+		if err != nil {
+			return config, fmt.Errorf("failed to encode body of %q: %w", req.Name, err)
+		}
+		config.Data = string(data)
+		headers = append(headers, "Content-Type: application/json")
+	}
+	headers = append(headers, c.Headers...)
+	headers = append(headers, req.Headers...)
+	config.Headers = append(headers, defaults.Headers...)
+	config.Query = append(append([]string{}, req.Query...), defaults.Query...)
+
+	// A request's auth block replaces the command line's credentials
+	if !req.Auth.empty() {
+		req.Auth.apply(&config)
+		config.CustomValue = ""
+		config.Scopes = req.Auth.OAuthScopes
+		splitCredentials(&config)
+	}
+
+	config.Method = strings.ToUpper(req.Method)
+	if config.Method == "" {
+		config.Method = defaultMethod(config)
+	}
+
+	if !config.NoExpand {
+		lookup := func(name string) (string, bool, error) {
+			if value, ok := vars[name]; ok {
+				return value, true, nil
+			}
+			return lookupEnv(name)
+		}
+		if err := expandConfig(&config, lookup); err != nil {
+			return config, fmt.Errorf("%s: %w", req.Name, err)
+		}
+	}
+	return config, nil
+}
+
+// runCollection implements 'http-client run [OPTIONS] FILE [NAME...]'. The
+// options are the usual ones and apply to every request; the URL argument
+// is the collection file and the arguments after it name the requests to
+// run. Requests run one after another and share a cookie jar, and the run
+// stops at the first request that fails.
+func runCollection(args []string, stdout, stderr io.Writer) int {
+	defaults, err := parseFlags(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if errors.Is(err, errMissingURL) {
+			return exitFailure
+		}
+		return exitUsage
+	}
+	path, names := defaults.URL, defaults.Args
+
+	c, err := loadCollection(path)
+	var requests []collectionRequest
+	if err == nil {
+		requests, err = c.selectRequests(names)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	if !defaults.Raw && isTerminal(stdout) {
+		defaults.PrettyPrint = true
+	}
+	defaults.Colorize = useColor(defaults.Color, stdout)
+	if err := runRequests(c, requests, defaults, stdout, stderr); err != nil {
+		return reportError(defaults, stderr, err)
+	}
+	return 0
+}
+
+func runRequests(c *collection, requests []collectionRequest, defaults Config, stdout, stderr io.Writer) error {
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// The requests are reported together, like a single run
+	var m *metrics
+	if defaults.MetricsFile != "" {
+		m = newMetrics()
+		defer func() {
+			if err := m.writeFile(defaults.MetricsFile); err != nil && !defaults.QuietErrors {
+				fmt.Fprintf(stderr, "Warning: %v\n", err)
+			}
+		}()
+	}
+
+	var jar http.CookieJar
+	vars := make(map[string]string)
+	for i, req := range requests {
+		config, err := c.requestConfig(req, defaults, vars)
+		if err != nil {
+			return err
+		}
+		r, err := newRequester(config, stdout, stderr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", req.Name, err)
+		}
+		r.ctx = ctx
+		if jar == nil {
+			jar = r.client.Jar
+		} else {
+			r.client.Jar = jar
+		}
+
+		status := ""
+		start := time.Now()
+		err = r.do(r.config.URL, true, func(resp *http.Response) error {
+			status = resp.Status
+			if len(req.captures) == 0 {
+				return r.printResponse(resp)
+			}
+
+			// The body is read up front when a capture needs it and handed
+			// on to printResponse from memory
+			var body []byte
+			if needsBody(req.captures) {
+				var err error
+				if body, err = io.ReadAll(resp.Body); err != nil {
+					return fmt.Errorf("failed to read response body: %w", err)
+				}
+				resp.Body.Close()
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			values, err := captureValues(resp, body, req.captures)
+			if err != nil {
+				return err
+			}
+			if err := r.printResponse(resp); err != nil {
+				return err
+			}
+			maps.Copy(vars, values)
+			return nil
+		})
+		elapsed := time.Since(start).Round(time.Millisecond)
+		m.merge(r.metrics)
+
+		result := status
+		if err != nil {
+			result = "failed"
+		}
+		fmt.Fprintf(stderr, "[%d/%d] %s: %s %s -> %s (%s)\n", i+1, len(requests), req.Name, config.Method, r.config.URL, result, elapsed)
+
+		if i == len(requests)-1 || err != nil {
+			if defaults.CookieJarFile != "" {
+				if cookieErr := r.saveCookies(defaults.CookieJarFile); cookieErr != nil && !defaults.QuietErrors {
+					fmt.Fprintf(stderr, "Warning: %v\n", cookieErr)
+				}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", req.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newCollectionServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" || string(body) != `{"user":"alice"}` {
+				http.Error(w, "bad login", http.StatusBadRequest)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, "logged in\n")
+		case "/me":
+			if _, err := r.Cookie("session"); err != nil {
+				http.Error(w, "no session", http.StatusUnauthorized)
+				return
+			}
+			io.WriteString(w, r.Header.Get("X-Team")+" "+r.Header.Get("Authorization")+"\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeCollection(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunCollection(t *testing.T) {
+	server := newCollectionServer(t)
+	t.Setenv("API_TOKEN", "s3cret")
+
+	yamlPath := writeCollection(t, "api.yaml", `base_url: `+server.URL+`
+headers:
+  - "X-Team: payments"
+requests:
+  - name: login
+    url: /login
+    body:
+      user: alice
+  - name: me
+    url: /me
+    auth:
+      bearer: "{{env.API_TOKEN}}"
+`)
+	jsonPath := writeCollection(t, "api.json", `{
+  "base_url": "`+server.URL+`",
+  "requests": [
+    {"name": "login", "method": "post", "url": "/login", "headers": ["Content-Type: application/json"], "body": "{\"user\":\"alice\"}"},
+    {"name": "missing", "url": "/missing"}
+  ]
+}`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		bodies   []string
+		progress []string
+	}{
+		{
+			"All requests in order", []string{yamlPath}, 0,
+			[]string{"logged in\n", "payments Bearer s3cret\n"},
+			[]string{"[1/2] login: POST " + server.URL + "/login -> 201 Created", "[2/2] me: GET " + server.URL + "/me -> 200 OK"},
+		},
+		{
+			"Selected by name", []string{yamlPath, "login"}, 0,
+			[]string{"logged in\n"},
+			[]string{"[1/1] login: POST"},
+		},
+		{
+			"Cookies are not shared across runs", []string{yamlPath, "me"}, 0,
+			[]string{"no session\n"},
+			[]string{"[1/1] me: GET " + server.URL + "/me -> 401 Unauthorized"},
+		},
+		{
+			"JSON collection", []string{jsonPath}, 0,
+			[]string{"logged in\n", "404 page not found\n"},
+			[]string{"[1/2] login: POST", "[2/2] missing: GET " + server.URL + "/missing -> 404 Not Found"},
+		},
+		{
+			"Options apply to every request", []string{"-H", "X-Team: search", yamlPath}, 0,
+			[]string{"logged in\n", "search Bearer s3cret\n"},
+			[]string{"[2/2] me"},
+		},
+		{
+			"Unknown request", []string{yamlPath, "logout"}, exitUsage,
+			nil,
+			[]string{`no request named "logout"`},
+		},
+		{
+			"Missing file", []string{yamlPath + ".missing"}, exitUsage,
+			nil,
+			[]string{"failed to read collection"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			code := Run(append([]string{"run"}, tt.args...), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr %q)", tt.code, code, stderr.String())
+			}
+			for _, body := range tt.bodies {
+				if !strings.Contains(stdout.String(), "\n\n"+body) {
+					t.Errorf("Expected body %q in stdout, got %q", body, stdout.String())
+				}
+			}
+			for _, line := range tt.progress {
+				if !strings.Contains(stderr.String(), line) {
+					t.Errorf("Expected %q in stderr, got %q", line, stderr.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunCollectionStopsAtFailure(t *testing.T) {
+	path := writeCollection(t, "api.yaml", `requests:
+  - name: down
+    url: http://127.0.0.1:1/
+  - name: never
+    url: http://127.0.0.1:1/never
+`)
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"run", "--timeout", "2s", path}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("Expected exit code %d, got %d", exitFailure, code)
+	}
+	if !strings.Contains(stderr.String(), "[1/2] down: GET http://127.0.0.1:1/ -> failed") {
+		t.Errorf("Expected the failed request to be reported, got %q", stderr.String())
+	}
+	if strings.Contains(stderr.String(), "never") {
+		t.Errorf("Expected the run to stop at the first failure, got %q", stderr.String())
+	}
+}
+
+func TestRunCollectionMetrics(t *testing.T) {
+	server := newCollectionServer(t)
+	path := writeCollection(t, "api.yaml", `base_url: `+server.URL+`
+requests:
+  - name: login
+    url: /login
+    body:
+      user: alice
+  - name: me
+    url: /me
+`)
+	metricsPath := filepath.Join(t.TempDir(), "run.prom")
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"run", "--metrics-prom", metricsPath, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(metricsPath)
+	if err != nil {
+		t.Fatalf("Expected the metrics file to be written: %v", err)
+	}
+	for _, line := range []string{`http_client_requests_total{code="200"} 1`, `http_client_requests_total{code="201"} 1`, "http_client_request_duration_seconds_count 2"} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("Expected %q in metrics:\n%s", line, data)
+		}
+	}
+}
+
+func TestRunCollectionCapture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Request-Id", "req-7")
+			io.WriteString(w, `{"access_token": "tok-1", "user": {"id": 42, "roles": ["admin"]}}`)
+		default:
+			io.WriteString(w, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("Authorization")+" "+r.Header.Get("X-Trace")+"\n")
+		}
+	}))
+	defer server.Close()
+
+	path := writeCollection(t, "api.yaml", `base_url: `+server.URL+`
+requests:
+  - name: login
+    method: POST
+    url: /login
+    capture:
+      token: json:access_token
+      user_id: json:$.user.id
+      role: json:user.roles[0]
+      trace: header:X-Request-Id
+      login_status: status
+  - name: profile
+    url: /users/{{user_id}}?role={{role}}&status={{login_status}}
+    headers:
+      - "X-Trace: {{trace}}"
+    auth:
+      bearer: "{{token}}"
+  - name: missing
+    url: /login
+    capture:
+      refresh: json:refresh_token
+`)
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"run", path, "login", "profile"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+	expected := "GET /users/42?role=admin&status=200 Bearer tok-1 req-7\n"
+	if !strings.HasSuffix(stdout.String(), "\n\n"+expected) {
+		t.Errorf("Expected captured values in %q, got %q", expected, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), `"access_token": "tok-1"`) {
+		t.Errorf("Expected the captured response to be printed, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := Run([]string{"run", path, "missing"}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("Expected exit code %d for a missing capture, got %d", exitFailure, code)
+	}
+	if !strings.Contains(stderr.String(), `failed to capture refresh: no "refresh_token" in the response`) {
+		t.Errorf("Expected capture error, got %q", stderr.String())
+	}
+
+	invalid := writeCollection(t, "invalid.yaml", `requests:
+  - url: http://example.com
+    capture:
+      token: body
+`)
+	stderr.Reset()
+	if code := Run([]string{"run", invalid}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for an invalid capture, got %d", exitUsage, code)
+	}
+	if !strings.Contains(stderr.String(), `invalid capture "body"`) {
+		t.Errorf("Expected invalid capture error, got %q", stderr.String())
+	}
+}
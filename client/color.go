@@ -0,0 +1,43 @@
+package client
+
+import (
+	"io"
+	"os"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiGreen  = "\x1b[1;32m"
+	ansiCyan   = "\x1b[1;36m"
+	ansiYellow = "\x1b[1;33m"
+	ansiRed    = "\x1b[1;31m"
+	ansiHeader = "\x1b[34m"
+)
+
+// useColor resolves --color: "always" and "never" are taken as given, and
+// "auto" colors a terminal unless NO_COLOR is set (https://no-color.org)
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return os.Getenv("NO_COLOR") == "" && isTerminal(w)
+	}
+}
+
+// statusColor picks the status line color by class: 2xx green, 3xx cyan,
+// 4xx yellow and 5xx red
+func statusColor(code int) string {
+	switch {
+	case code >= 500:
+		return ansiRed
+	case code >= 400:
+		return ansiYellow
+	case code >= 300:
+		return ansiCyan
+	default:
+		return ansiGreen
+	}
+}
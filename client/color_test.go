@@ -0,0 +1,73 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestColorOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "missing"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		args    []string
+		noColor string
+		colored bool
+	}{
+		{"Auto without a terminal", nil, "", false},
+		{"Always", []string{"--color", "always"}, "", true},
+		{"Always beats NO_COLOR", []string{"--color", "always"}, "1", true},
+		{"Never", []string{"--color", "never"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			var stdout, stderr strings.Builder
+			if code := Run(append(tt.args, server.URL), &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+			}
+
+			output := stdout.String()
+			if colored := strings.Contains(output, "\x1b["); colored != tt.colored {
+				t.Fatalf("Expected colored %t, got %q", tt.colored, output)
+			}
+			if !tt.colored {
+				return
+			}
+			if !strings.HasPrefix(output, ansiYellow+"HTTP/1.1 404 Not Found"+ansiReset+"\n") {
+				t.Errorf("Expected a yellow 4xx status line, got %q", output)
+			}
+			if !strings.Contains(output, ansiHeader+"Content-Type"+ansiReset+": application/json\n") {
+				t.Errorf("Expected a colored header name, got %q", output)
+			}
+			if !strings.Contains(output, `"missing"`+ansiReset) {
+				t.Errorf("Expected a highlighted JSON body, got %q", output)
+			}
+		})
+	}
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--color", "sometimes", server.URL}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for an invalid --color, got %d", exitUsage, code)
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if useColor("auto", nil) {
+		t.Error("Expected no color when NO_COLOR is set")
+	}
+	for code, color := range map[int]string{200: ansiGreen, 301: ansiCyan, 404: ansiYellow, 503: ansiRed} {
+		if statusColor(code) != color {
+			t.Errorf("Expected color %q for %d, got %q", color, code, statusColor(code))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressRequestBody replaces the request body with one compressed on the
+// fly for --compress-body. The compressed size isn't known up front, so the
+// body is sent chunked; GetBody, when set, yields a fresh compressed copy
+// for redirects and retries.
+func compressRequestBody(req *http.Request, encoding string) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	req.Body = compressStream(req.Body, encoding)
+	req.ContentLength = -1
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return compressStream(body, encoding), nil
+		}
+	}
+	req.Header.Set("Content-Encoding", encoding)
+}
+
+// compressStream compresses body through a pipe, so only a buffer's worth of
+// it is held in memory at a time
+func compressStream(body io.ReadCloser, encoding string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer body.Close()
+
+		w, err := newCompressor(pw, encoding)
+		if err == nil {
+			_, err = io.Copy(w, body)
+			if closeErr := w.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+func newCompressor(w io.Writer, encoding string) (io.WriteCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "zstd":
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported body compression %q", encoding)
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/ingest", http.StatusTemporaryRedirect)
+			return
+		}
+
+		var body io.Reader
+		switch r.Header.Get("Content-Encoding") {
+		case "gzip":
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = gz
+		case "zstd":
+			zr, err := zstd.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer zr.Close()
+			body = zr
+		default:
+			http.Error(w, "missing Content-Encoding", http.StatusBadRequest)
+			return
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(r.Header.Get("Content-Encoding") + " " + strings.Join(r.TransferEncoding, ",") + " " + string(data)))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	payload := strings.Repeat(`{"event": "click"}`+"\n", 100)
+	os.WriteFile(path, []byte(payload), 0644)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"Gzip inline data", []string{"--compress-body", "gzip", "-d", "hello", server.URL + "/ingest"}, "gzip chunked hello"},
+		{"Zstd file", []string{"--compress-body", "zstd", "-d", "@" + path, server.URL + "/ingest"}, "zstd chunked " + payload},
+		{"Gzip after a redirect", []string{"--compress-body", "gzip", "-d", "@" + path, server.URL + "/redirect"}, "gzip chunked " + payload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(append([]string{"--body-only"}, tt.args...), &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+			}
+			if stdout.String() != tt.expected {
+				t.Errorf("Expected %.60q, got %.60q", tt.expected, stdout.String())
+			}
+		})
+	}
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--compress-body", "br", "-d", "x", server.URL}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for an unsupported compression, got %d", exitUsage, code)
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ContentTypeList holds the media types accepted by --expect-content-type
+type ContentTypeList []string
+
+func (c *ContentTypeList) String() string {
+	return strings.Join(*c, ", ")
+}
+
+func (c *ContentTypeList) Set(value string) error {
+	if _, err := path.Match(value, ""); err != nil || !strings.Contains(value, "/") {
+		return fmt.Errorf("invalid media type %q (e.g., 'application/json', 'application/*')", value)
+	}
+	*c = append(*c, value)
+	return nil
+}
+
+// checkContentType fails unless the response Content-Type matches one of
+// the expected media types. Parameters such as charset are ignored and
+// patterns may use wildcards, e.g. "application/*" or "*/*+json".
+func checkContentType(resp *http.Response, expected []string) error {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	actual := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(actual)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(actual))
+	}
+
+	for _, pattern := range expected {
+		if matched, _ := path.Match(strings.ToLower(pattern), mediaType); matched {
+			return nil
+		}
+	}
+
+	if actual == "" {
+		actual = "none"
+	}
+	return &exitError{
+		code: exitAssertion,
+		err:  fmt.Errorf("response Content-Type %s does not match --expect-content-type %s", actual, strings.Join(expected, ", ")),
+	}
+}
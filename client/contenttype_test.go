@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExpectContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<h1>Bad Gateway</h1>"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		expected []string
+		exitCode int
+	}{
+		{"Exact type matches", []string{"text/html"}, 0},
+		{"Wildcard matches", []string{"text/*"}, 0},
+		{"Any listed type matches", []string{"application/json", "text/html"}, 0},
+		{"Mismatch fails", []string{"application/json"}, exitAssertion},
+		{"Wildcard mismatch fails", []string{"application/*"}, exitAssertion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{}
+			for _, expected := range tt.expected {
+				args = append(args, "--expect-content-type", expected)
+			}
+			args = append(args, server.URL)
+
+			var stdout, stderr bytes.Buffer
+			code := Run(args, &stdout, &stderr)
+
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.exitCode, code, stderr.String())
+			}
+			if tt.exitCode != 0 && !strings.Contains(stderr.String(), "Content-Type text/html; charset=utf-8 does not match --expect-content-type "+strings.Join(tt.expected, ", ")) {
+				t.Errorf("Expected actual vs expected type to be reported, got: %s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestCheckContentTypeMissingHeader(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+
+	err := checkContentType(resp, []string{"*/*"})
+	if err == nil {
+		t.Fatal("Expected a response without Content-Type to fail")
+	}
+	if !strings.Contains(err.Error(), "Content-Type none") {
+		t.Errorf("Expected missing type to be reported, got: %v", err)
+	}
+}
+
+func TestContentTypeListValidation(t *testing.T) {
+	var list ContentTypeList
+	for _, value := range []string{"json", "application/[json"} {
+		if err := list.Set(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
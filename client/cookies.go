@@ -0,0 +1,83 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"http-client/cookies"
+)
+
+// jarCookies returns the cookies the client holds for resp's URL once the
+// response's Set-Cookie headers have been stored: those from --cookies and
+// earlier responses as well as this one. Without a jar it falls back to
+// the response's own cookies.
+func (r *requester) jarCookies(resp *http.Response) []*http.Cookie {
+	if r.client.Jar == nil || resp.Request == nil {
+		return resp.Cookies()
+	}
+	if jar, ok := r.client.Jar.(*cookies.Jar); ok {
+		return jar.Stored(resp.Request.URL)
+	}
+	return r.client.Jar.Cookies(resp.Request.URL)
+}
+
+// printCookies writes one line per cookie in key=value form
+func printCookies(w io.Writer, cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		fmt.Fprintln(w, formatCookie(cookie))
+	}
+}
+
+func formatCookie(cookie *http.Cookie) string {
+	fields := []string{
+		"name=" + cookie.Name,
+		"value=" + cookie.Value,
+	}
+
+	if cookie.Domain != "" {
+		fields = append(fields, "domain="+cookie.Domain)
+	}
+	if cookie.Path != "" {
+		fields = append(fields, "path="+cookie.Path)
+	}
+	if !cookie.Expires.IsZero() {
+		fields = append(fields, "expires="+cookie.Expires.UTC().Format(time.RFC3339))
+	}
+	if cookie.MaxAge != 0 {
+		maxAge := cookie.MaxAge
+		if maxAge < 0 {
+			// net/http reports "Max-Age=0" as -1
+			maxAge = 0
+		}
+		fields = append(fields, "max-age="+strconv.Itoa(maxAge))
+	}
+	if cookie.Secure {
+		fields = append(fields, "secure")
+	}
+	if cookie.HttpOnly {
+		fields = append(fields, "httponly")
+	}
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		fields = append(fields, "samesite=Lax")
+	case http.SameSiteStrictMode:
+		fields = append(fields, "samesite=Strict")
+	case http.SameSiteNoneMode:
+		fields = append(fields, "samesite=None")
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// saveCookies writes the run's cookies to path for a later --cookies
+func (r *requester) saveCookies(path string) error {
+	jar, ok := r.client.Jar.(*cookies.Jar)
+	if !ok {
+		return nil
+	}
+	return jar.Save(path)
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"http-client/cookies"
+)
+
+func TestPrintCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "session=abc123; Domain=example.com; Path=/app; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Max-Age=3600; Secure; HttpOnly; SameSite=Strict")
+		w.Header().Add("Set-Cookie", "theme=dark")
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	var buf bytes.Buffer
+	printCookies(&buf, resp.Cookies())
+
+	expected := "name=session value=abc123 domain=example.com path=/app expires=2026-10-21T07:28:00Z max-age=3600 secure httponly samesite=Strict\n" +
+		"name=theme value=dark\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestPrintCookiesShowsJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+		case "/home":
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/", HttpOnly: true})
+		}
+	}))
+	defer server.Close()
+
+	saved := cookies.New()
+	u, _ := url.Parse(server.URL)
+	saved.SetCookies(u, []*http.Cookie{{Name: "pref", Value: "1", Path: "/"}})
+	cookieFile := filepath.Join(t.TempDir(), "cookies.json")
+	if err := saved.Save(cookieFile); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--print-cookies", "--cookies", cookieFile, server.URL + "/login"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	for _, line := range []string{
+		"name=pref value=1 path=/\n",
+		"name=session value=abc123 path=/\n",
+		"name=theme value=dark path=/ httponly\n",
+	} {
+		if !strings.Contains(stderr.String(), line) {
+			t.Errorf("Expected %q among the printed cookies, got:\n%s", line, stderr.String())
+		}
+	}
+}
+
+func TestFormatCookieExpiredMaxAge(t *testing.T) {
+	cookie := &http.Cookie{Name: "gone", Value: "", MaxAge: -1}
+
+	expected := "name=gone value= max-age=0"
+	if got := formatCookie(cookie); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestCookieJarSharedAcrossRequests(t *testing.T) {
+	received := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+			http.SetCookie(w, &http.Cookie{Name: "admin", Value: "yes", Path: "/admin"})
+		default:
+			received[r.URL.Path] = r.Header.Get("Cookie")
+		}
+	}))
+	defer server.Close()
+
+	r, _, _ := newTestRequester(t, Config{URL: server.URL})
+	for _, path := range []string{"/login", "/data", "/admin/users"} {
+		if err := r.do(server.URL+path, true, r.printResponse); err != nil {
+			t.Fatalf("Request to %s failed: %v", path, err)
+		}
+	}
+
+	if got := received["/data"]; got != "session=abc123" {
+		t.Errorf("Expected /data to receive only the session cookie, got %q", got)
+	}
+	if got := received["/admin/users"]; got != "admin=yes; session=abc123" {
+		t.Errorf("Expected /admin/users to receive both cookies, got %q", got)
+	}
+
+	// The jar is keyed by host, so another name for the server gets nothing
+	otherHost := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	if err := r.do(otherHost+"/data", true, r.printResponse); err != nil {
+		t.Fatalf("Request to other host failed: %v", err)
+	}
+	if got := received["/data"]; got != "" {
+		t.Errorf("Expected no cookies for a different host, got %q", got)
+	}
+}
+
+func TestCookieJarPersistsBetweenRuns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+			return
+		}
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc123" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--cookie-jar", path, server.URL + "/login"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Login failed with exit code %d: %s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"--cookies", path, server.URL + "/profile"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Request failed with exit code %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "HTTP/1.1 200 OK") {
+		t.Errorf("Expected the saved session cookie to be sent, got %q", stdout.String())
+	}
+
+	if code := Run([]string{"--cookies", filepath.Join(t.TempDir(), "missing.json"), server.URL}, &stdout, &stderr); code != exitFailure {
+		t.Errorf("Expected a missing cookie file to fail with exit code %d, got %d", exitFailure, code)
+	}
+}
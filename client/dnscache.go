@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsCache remembers host lookups for ttl so that repeated requests in one
+// run (pages, retries) don't resolve the same host again. net.Resolver can't
+// be wrapped, so the cache sits in front of the transport's dialer instead.
+type dnsCache struct {
+	ttl    time.Duration
+	lookup func(ctx context.Context, host string) ([]string, error)
+	now    func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration, lookup func(ctx context.Context, host string) ([]string, error)) *dnsCache {
+	return &dnsCache{
+		ttl:     ttl,
+		lookup:  lookup,
+		now:     time.Now,
+		entries: make(map[string]dnsEntry),
+	}
+}
+
+// LookupHost returns the cached addresses for host, resolving it when there
+// is no entry or the entry has expired
+func (c *dnsCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	c.mutex.Lock()
+	entry, ok := c.entries[host]
+	c.mutex.Unlock()
+
+	if ok && c.now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.entries[host] = dnsEntry{addrs: addrs, expires: c.now().Add(c.ttl)}
+	c.mutex.Unlock()
+
+	return addrs, nil
+}
+
+// dialContext wraps dial so hostnames are resolved through the cache. Each
+// cached address is tried in turn until one connects.
+func (c *dnsCache) dialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := c.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var dialErr error
+		for _, ip := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = errors.Join(dialErr, err)
+		}
+		if dialErr == nil {
+			dialErr = &net.DNSError{Err: "no addresses", Name: host, IsNotFound: true}
+		}
+		return nil, dialErr
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDNSCacheReusesLookups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	_, port, _ := net.SplitHostPort(serverURL.Host)
+
+	var lookups atomic.Int32
+	cache := newDNSCache(time.Minute, func(ctx context.Context, host string) ([]string, error) {
+		lookups.Add(1)
+		return []string{"127.0.0.1"}, nil
+	})
+
+	// Keep-alives are off so every request dials, and therefore resolves
+	transport := &http.Transport{
+		DisableKeepAlives: true,
+		DialContext:       cache.dialContext((&net.Dialer{}).DialContext),
+	}
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("http://api.example.test:" + port)
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("Expected 1 lookup within the TTL, got %d", n)
+	}
+}
+
+func TestDNSCacheExpires(t *testing.T) {
+	var lookups int
+	cache := newDNSCache(time.Second, func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"127.0.0.1"}, nil
+	})
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.LookupHost(context.Background(), "example.test")
+	cache.LookupHost(context.Background(), "example.test")
+	if lookups != 1 {
+		t.Fatalf("Expected 1 lookup before expiry, got %d", lookups)
+	}
+
+	now = now.Add(2 * time.Second)
+	cache.LookupHost(context.Background(), "example.test")
+	if lookups != 2 {
+		t.Errorf("Expected a new lookup after the TTL, got %d lookups", lookups)
+	}
+}
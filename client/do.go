@@ -0,0 +1,149 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"http-client/cookies"
+	"http-client/retry"
+)
+
+// defaultTimeout applies when a Config leaves Timeout unset
+const defaultTimeout = 30 * time.Second
+
+// Client sends requests described by a Config from Go code. Cookies set by
+// one response are sent on later requests made through the same Client. A
+// Config's CookieFile is added to the Client's cookies the first time it's
+// used, and CookieJarFile is written with them after each request.
+type Client struct {
+	jar *cookies.Jar
+
+	mu     sync.Mutex
+	loaded map[string]bool
+
+	// Stderr receives the diagnostics the CLI prints, such as --verbose
+	// output and retry notices. They are discarded when it's nil.
+	Stderr io.Writer
+}
+
+// Response is a completed response with its body read
+type Response struct {
+	Proto      string
+	Status     string
+	StatusCode int
+	Header     http.Header
+
+	// Body holds the raw bytes, Formatted the body after the formatter and
+	// transformers selected by the Config (PrettyPrint, MaskFields, ...)
+	Body      []byte
+	Formatted []byte
+
+	Duration time.Duration
+}
+
+func New() *Client {
+	return &Client{}
+}
+
+// Do sends a request with a new Client
+func Do(config Config) (*Response, error) {
+	return New().Do(config)
+}
+
+// Do sends the single request described by config. Method defaults like
+// the CLI's (POST when there is a body), Timeout to 30s and RetryOn, when
+// Retry is set, to the CLI's 5xx, 429 and connection failures. Multi-request
+// modes such as Benchmark, Paginate or Repeat are CLI features and are
+// ignored. When a check like ExpectContentType or MaxResponseTime fails,
+// the response is returned along with the error.
+func (c *Client) Do(config Config) (*Response, error) {
+	return c.DoContext(context.Background(), config)
+}
+
+// DoContext is Do with a context that cancels the request
+func (c *Client) DoContext(ctx context.Context, config Config) (*Response, error) {
+	if config.Method == "" {
+		config.Method = defaultMethod(config)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.Retry > 0 && config.RetryOn.String() == "" {
+		config.RetryOn, _ = retry.ParseConditions(retry.DefaultConditions)
+	}
+
+	stderr := c.Stderr
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	r, err := newRequester(config, io.Discard, stderr)
+	if err != nil {
+		return nil, err
+	}
+	jar, err := c.sharedJar(config.CookieFile)
+	if err != nil {
+		return nil, err
+	}
+	r.ctx = ctx
+	r.client.Jar = jar
+
+	var result *Response
+	start := time.Now()
+	err = r.do(r.config.URL, true, func(resp *http.Response) error {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		formatted, err := r.formatBody(resp)
+		if err != nil {
+			return err
+		}
+
+		result = &Response{
+			Proto:      resp.Proto,
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       body,
+			Formatted:  formatted,
+		}
+		return nil
+	})
+	if result != nil {
+		result.Duration = time.Since(start)
+	}
+	if config.CookieJarFile != "" {
+		if cookieErr := r.saveCookies(config.CookieJarFile); cookieErr != nil && err == nil {
+			err = cookieErr
+		}
+	}
+
+	return result, err
+}
+
+// sharedJar returns the Client's jar with the cookies in path added. Each
+// file is only loaded once, so cookies updated by later responses aren't
+// reset.
+func (c *Client) sharedJar(path string) (*cookies.Jar, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.jar == nil {
+		c.jar = cookies.New()
+		c.loaded = make(map[string]bool)
+	}
+	if path != "" && !c.loaded[path] {
+		if err := c.jar.LoadFile(path); err != nil {
+			return nil, err
+		}
+		c.loaded[path] = true
+	}
+	return c.jar, nil
+}
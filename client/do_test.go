@@ -0,0 +1,162 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"http-client/cookies"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"method":%q,"body":%q,"tenant":%q}`, r.Method, body, r.Header.Get("X-Tenant"))
+	}))
+	defer server.Close()
+
+	resp, err := Do(Config{
+		URL:         server.URL,
+		Data:        "hello",
+		Headers:     []string{"X-Tenant: acme"},
+		PrettyPrint: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Proto != "HTTP/1.1" {
+		t.Errorf("Expected HTTP/1.1 200, got %s %d", resp.Proto, resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected JSON Content-Type, got %q", got)
+	}
+	expected := `{"method":"POST","body":"hello","tenant":"acme"}`
+	if string(resp.Body) != expected {
+		t.Errorf("Expected body %s, got %s", expected, resp.Body)
+	}
+	if !strings.Contains(string(resp.Formatted), "\n  \"method\": \"POST\"") {
+		t.Errorf("Expected pretty-printed body, got %s", resp.Formatted)
+	}
+}
+
+func TestDoRetriesByDefault(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests++; requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := Do(Config{URL: server.URL, Retry: 2, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || requests != 2 {
+		t.Errorf("Expected the 503 to be retried, got %d after %d requests", resp.StatusCode, requests)
+	}
+}
+
+func TestClientSharesCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			return
+		}
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(cookie.Value))
+	}))
+	defer server.Close()
+
+	c := New()
+	if _, err := c.Do(Config{URL: server.URL + "/login"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp, err := c.Do(Config{URL: server.URL + "/me"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != "abc" {
+		t.Errorf("Expected the session cookie to carry over, got %d %q", resp.StatusCode, resp.Body)
+	}
+
+	if resp, _ := New().Do(Config{URL: server.URL + "/me"}); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a new Client to start without cookies, got %d", resp.StatusCode)
+	}
+}
+
+func TestClientCookieFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rotate" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "rotated", Path: "/"})
+		}
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(cookie.Value))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	saved := cookies.New()
+	u, _ := url.Parse(server.URL)
+	saved.SetCookies(u, []*http.Cookie{{Name: "session", Value: "from-file", Path: "/"}})
+	cookieFile := filepath.Join(dir, "cookies.json")
+	if err := saved.Save(cookieFile); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New()
+	jarFile := filepath.Join(dir, "jar.json")
+	resp, err := c.Do(Config{URL: server.URL + "/rotate", CookieFile: cookieFile, CookieJarFile: jarFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(resp.Body) != "from-file" {
+		t.Errorf("Expected the cookie from the file to be sent, got %d %q", resp.StatusCode, resp.Body)
+	}
+
+	// The file isn't loaded again over the cookie the server replaced
+	resp, err = c.Do(Config{URL: server.URL + "/me", CookieFile: cookieFile})
+	if err != nil || string(resp.Body) != "rotated" {
+		t.Errorf("Expected the rotated cookie, got %q (%v)", resp.Body, err)
+	}
+
+	data, _ := os.ReadFile(jarFile)
+	if !strings.Contains(string(data), `"value": "rotated"`) {
+		t.Errorf("Expected the cookie jar file to hold the rotated cookie, got %s", data)
+	}
+}
+
+func TestDoReturnsResponseWithCheckError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("slow"))
+	}))
+	defer server.Close()
+
+	resp, err := Do(Config{URL: server.URL, MaxResponseTime: time.Millisecond})
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitSlowResponse {
+		t.Fatalf("Expected slow response error, got: %v", err)
+	}
+	if resp == nil || string(resp.Body) != "slow" {
+		t.Errorf("Expected the response alongside the error, got %+v", resp)
+	}
+}
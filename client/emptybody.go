@@ -0,0 +1,24 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// checkEmptyBody fails a response that carried no body bytes: any response
+// with --require-body, or only successful ones with --empty-as-error
+func checkEmptyBody(resp *http.Response, received int64, emptyAsError, requireBody bool) error {
+	if received > 0 {
+		return nil
+	}
+
+	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !requireBody && !(emptyAsError && success) {
+		return nil
+	}
+
+	return &exitError{
+		code: exitEmptyBody,
+		err:  fmt.Errorf("response %s has an empty body", resp.Status),
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/empty":
+		case "/empty-error":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Write([]byte("body"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		path     string
+		exitCode int
+	}{
+		{"Empty 200 succeeds by default", nil, "/empty", 0},
+		{"Empty 200 with --empty-as-error", []string{"--empty-as-error"}, "/empty", exitEmptyBody},
+		{"Body with --empty-as-error", []string{"--empty-as-error"}, "/", 0},
+		{"Empty 404 with --empty-as-error", []string{"--empty-as-error"}, "/empty-error", 0},
+		{"Empty 404 with --require-body", []string{"--require-body"}, "/empty-error", exitEmptyBody},
+		{"Body with --require-body", []string{"--require-body"}, "/", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(append(tt.args, server.URL+tt.path), &stdout, &stderr)
+
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.exitCode, code, stderr.String())
+			}
+			if tt.exitCode != 0 && !strings.Contains(stderr.String(), "has an empty body") {
+				t.Errorf("Expected empty body message, got: %s", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// errorCategory classifies a request error for --error-json
+func errorCategory(err error) string {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		switch exitErr.code {
+		case exitInterrupted:
+			return "interrupted"
+		case exitSlowResponse:
+			return "slow_response"
+		case exitAssertion:
+			return "assertion"
+		case exitEmptyBody:
+			return "empty_body"
+		case exitSlowTransfer:
+			return "slow_transfer"
+		case exitCertExpiring:
+			return "cert_expiring"
+		case exitClientError:
+			return "client_error"
+		case exitServerError:
+			return "server_error"
+		}
+	}
+
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case isConnectionReset(err):
+		return "connection_reset"
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr), errors.As(err, &recordErr):
+		return "tls"
+	}
+
+	return "request"
+}
+
+// writeErrorJSON reports err as a single-line JSON object
+func writeErrorJSON(w io.Writer, err error, code int) error {
+	return json.NewEncoder(w).Encode(struct {
+		Error    string `json:"error"`
+		Category string `json:"category"`
+		ExitCode int    `json:"exit_code"`
+	}{err.Error(), errorCategory(err), code})
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrorJSON(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	// A listener that is closed straight away leaves a port nothing accepts on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	refusedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		category string
+	}{
+		{"Connection refused", []string{refusedURL}, "connection_refused"},
+		{"Timeout", []string{"-t", "50ms", slow.URL}, "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(append([]string{"--error-json"}, tt.args...), &stdout, &stderr)
+
+			var report struct {
+				Error    string `json:"error"`
+				Category string `json:"category"`
+				ExitCode int    `json:"exit_code"`
+			}
+			if err := json.Unmarshal(stderr.Bytes(), &report); err != nil {
+				t.Fatalf("Expected a JSON error, got %q: %v", stderr.String(), err)
+			}
+
+			if report.Category != tt.category {
+				t.Errorf("Expected category %q, got %q", tt.category, report.Category)
+			}
+			if report.Error == "" {
+				t.Error("Expected error message to be set")
+			}
+			if report.ExitCode != code || code != exitFailure {
+				t.Errorf("Expected exit_code %d to match exit status %d", report.ExitCode, code)
+			}
+		})
+	}
+}
+
+func TestErrorPlainTextByDefault(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	Run([]string{"http://127.0.0.1:1"}, &stdout, &stderr)
+
+	if !bytes.HasPrefix(stderr.Bytes(), []byte("Error: ")) {
+		t.Errorf("Expected plain-text error, got %q", stderr.String())
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err      error
+		category string
+	}{
+		{interruptedError(10), "interrupted"},
+		{checkResponseTime(RequestStats{Total: 2 * time.Second}, time.Second), "slow_response"},
+		{&exitError{code: exitSlowTransfer, err: errors.New("too slow")}, "slow_transfer"},
+		{&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, "dns"},
+		{errMissingURL, "request"},
+	}
+
+	for _, tt := range tests {
+		if got := errorCategory(tt.err); got != tt.category {
+			t.Errorf("Expected category %q for %v, got %q", tt.category, tt.err, got)
+		}
+	}
+}
+
+func TestQuietErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<h1>Oops</h1>"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+	}{
+		{"Connection refused", []string{"http://127.0.0.1:1"}, exitFailure},
+		{"Assertion failure", []string{"--expect-content-type", "application/json", server.URL}, exitAssertion},
+		{"Wins over --error-json", []string{"--error-json", "http://127.0.0.1:1"}, exitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(append([]string{"--quiet-errors"}, tt.args...), &stdout, &stderr)
+
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, code)
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("Expected empty stderr, got %q", stderr.String())
+			}
+		})
+	}
+}
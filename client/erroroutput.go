@@ -0,0 +1,31 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// isErrorStatus reports whether a response body belongs in --error-output
+func isErrorStatus(status int) bool {
+	return status >= 400
+}
+
+// saveErrorBody writes an error response body to --error-output, formatted
+// on stderr for "-" or saved as-is to the file otherwise
+func (r *requester) saveErrorBody(resp *http.Response) error {
+	if r.config.ErrorOutput == "-" {
+		formattedBody, err := r.formatBody(resp)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(r.stderr, string(formattedBody))
+		return nil
+	}
+
+	file, err := os.Create(r.config.ErrorOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create error output file: %w", err)
+	}
+	return r.writeBody(file, resp, false)
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestErrorOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("error payload"))
+			return
+		}
+		w.Write([]byte("data payload"))
+	}))
+	defer server.Close()
+
+	t.Run("Stdout and file", func(t *testing.T) {
+		errorFile := filepath.Join(t.TempDir(), "errors.txt")
+
+		r, stdout, _ := newTestRequester(t, Config{URL: server.URL, ErrorOutput: errorFile})
+		if err := r.do(server.URL+"/fail", true, r.printResponse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := r.do(server.URL+"/ok", true, r.printResponse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(errorFile)
+		if err != nil {
+			t.Fatalf("Failed to read error output: %v", err)
+		}
+		if string(data) != "error payload" {
+			t.Errorf("Expected error body in error output, got %q", data)
+		}
+		if out := stdout.String(); !strings.Contains(out, "data payload") || strings.Contains(out, "error payload") {
+			t.Errorf("Expected only the success body on stdout, got: %s", out)
+		}
+	})
+
+	t.Run("Composes with -o", func(t *testing.T) {
+		dir := t.TempDir()
+		output := filepath.Join(dir, "out.txt")
+		errorFile := filepath.Join(dir, "errors.txt")
+
+		r, _, _ := newTestRequester(t, Config{URL: server.URL, Output: output, ErrorOutput: errorFile})
+		if err := r.do(server.URL+"/ok", true, r.printResponse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := r.do(server.URL+"/fail", true, r.printResponse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if data, _ := os.ReadFile(output); string(data) != "data payload" {
+			t.Errorf("Expected success body in -o file, got %q", data)
+		}
+		if data, _ := os.ReadFile(errorFile); string(data) != "error payload" {
+			t.Errorf("Expected error body in error output, got %q", data)
+		}
+	})
+
+	t.Run("Stderr", func(t *testing.T) {
+		r, stdout, stderr := newTestRequester(t, Config{URL: server.URL, ErrorOutput: "-"})
+		if err := r.do(server.URL+"/fail", true, r.printResponse); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(stderr.String(), "error payload") || strings.Contains(stdout.String(), "error payload") {
+			t.Errorf("Expected error body on stderr only, got stdout %q, stderr %q", stdout.String(), stderr.String())
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"errors"
+)
+
+const (
+	exitFailure      = 1
+	exitUsage        = 2
+	exitSlowResponse = 3
+	exitAssertion    = 4
+	exitEmptyBody    = 5
+	exitSlowTransfer = 6
+	exitCertExpiring = 7
+	exitClientError  = 8
+	exitServerError  = 9
+	exitInterrupted  = 130
+)
+
+var errMissingURL = errors.New("missing URL")
+
+// exitError attaches a process exit code to an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// exitCode maps an error returned by a request to the process exit code
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// templatePattern matches {{name}} references, allowing spaces inside the
+// braces as in {{ env.API_TOKEN }}
+var templatePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// expandTemplate replaces each {{name}} in s with lookup(name). References
+// lookup doesn't handle (ok false, nil error) are left as they are.
+func expandTemplate(s string, lookup func(name string) (string, bool, error)) (string, error) {
+	var firstErr error
+	expanded := templatePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := templatePattern.FindStringSubmatch(match)[1]
+		value, ok, err := lookup(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return match
+		}
+		if !ok {
+			return match
+		}
+		return value
+	})
+	return expanded, firstErr
+}
+
+// lookupEnv resolves {{env.NAME}} from the environment. A variable that
+// isn't set is an error rather than an empty string, so a missing secret
+// doesn't silently turn into an empty token.
+func lookupEnv(name string) (string, bool, error) {
+	key, found := strings.CutPrefix(name, "env.")
+	if !found {
+		return "", false, nil
+	}
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", false, fmt.Errorf("environment variable %s is not set", key)
+	}
+	return value, true, nil
+}
+
+// expandConfig substitutes the {{name}} references lookup resolves in the
+// URL, headers, query parameters, request data and credentials
+func expandConfig(config *Config, lookup func(name string) (string, bool, error)) error {
+	var err error
+	expand := func(s *string) {
+		if err == nil {
+			*s, err = expandTemplate(*s, lookup)
+		}
+	}
+
+	expand(&config.URL)
+	expand(&config.BaseURL)
+	expand(&config.Data)
+	expand(&config.Username)
+	expand(&config.Password)
+	expand(&config.BearerToken)
+	expand(&config.CustomValue)
+	expand(&config.ClientSecret)
+	for i := range config.Headers {
+		expand(&config.Headers[i])
+	}
+	for i := range config.Query {
+		expand(&config.Query[i])
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	t.Setenv("API_TOKEN", "s3cret")
+	t.Setenv("API_HOST", "api.example.com")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      string
+	}{
+		{"No references", "plain text", "plain text", ""},
+		{"Single variable", "Bearer {{env.API_TOKEN}}", "Bearer s3cret", ""},
+		{"Spaces inside braces", "{{ env.API_HOST }}", "api.example.com", ""},
+		{"Several variables", "https://{{env.API_HOST}}/?t={{env.API_TOKEN}}", "https://api.example.com/?t=s3cret", ""},
+		{"Other references are kept", `{"template": "{{name}}"}`, `{"template": "{{name}}"}`, ""},
+		{"Unset variable", "{{env.MISSING_VAR}}", "", "environment variable MISSING_VAR is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := expandTemplate(tt.input, lookupEnv)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestExpandFlags(t *testing.T) {
+	t.Setenv("API_TOKEN", "s3cret")
+	t.Setenv("API_HOST", "api.example.com")
+
+	args := []string{
+		"-H", "Authorization: Bearer {{env.API_TOKEN}}",
+		"-q", "token={{env.API_TOKEN}}",
+		"-d", `{"token": "{{env.API_TOKEN}}"}`,
+		"https://{{env.API_HOST}}/login",
+	}
+
+	var stderr strings.Builder
+	config, err := parseFlags(args, &stderr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.URL != "https://api.example.com/login" {
+		t.Errorf("Expected expanded URL, got %q", config.URL)
+	}
+	if !reflect.DeepEqual([]string(config.Headers), []string{"Authorization: Bearer s3cret"}) {
+		t.Errorf("Expected expanded header, got %q", config.Headers)
+	}
+	if !reflect.DeepEqual([]string(config.Query), []string{"token=s3cret"}) {
+		t.Errorf("Expected expanded query, got %q", config.Query)
+	}
+	if config.Data != `{"token": "s3cret"}` {
+		t.Errorf("Expected expanded data, got %q", config.Data)
+	}
+
+	config, err = parseFlags(append([]string{"--no-expand"}, args...), &stderr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.URL != "https://{{env.API_HOST}}/login" || config.Headers[0] != "Authorization: Bearer {{env.API_TOKEN}}" {
+		t.Errorf("Expected --no-expand to keep references, got %q and %q", config.URL, config.Headers[0])
+	}
+
+	var stdout strings.Builder
+	stderr.Reset()
+	if code := Run([]string{"-H", "X-Key: {{env.MISSING_VAR}}", "http://example.com"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for an unset variable, got %d", exitUsage, code)
+	}
+	if !strings.Contains(stderr.String(), "MISSING_VAR is not set") {
+		t.Errorf("Expected unset variable error, got %q", stderr.String())
+	}
+}
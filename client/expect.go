@@ -0,0 +1,204 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StatusList holds the codes accepted by --expect-status: exact codes like
+// 200 or classes like 2xx, given comma-separated or by repeating the flag
+type StatusList []string
+
+func (s *StatusList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *StatusList) Set(value string) error {
+	for _, status := range strings.Split(value, ",") {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if !validStatusPattern(status) {
+			return fmt.Errorf("invalid status %q (e.g., '200', '2xx')", status)
+		}
+		*s = append(*s, status)
+	}
+	return nil
+}
+
+func validStatusPattern(status string) bool {
+	if len(status) != 3 || status[0] < '1' || status[0] > '5' {
+		return false
+	}
+	if status[1:] == "xx" {
+		return true
+	}
+	_, err := strconv.Atoi(status)
+	return err == nil
+}
+
+// ExpectList collects the values of a repeatable --expect-* flag
+type ExpectList []string
+
+func (e *ExpectList) String() string {
+	return strings.Join(*e, ", ")
+}
+
+func (e *ExpectList) Set(value string) error {
+	*e = append(*e, value)
+	return nil
+}
+
+// JSONPathExpectList holds --expect-jsonpath assertions, checked for
+// syntax as they are given
+type JSONPathExpectList []string
+
+func (j *JSONPathExpectList) String() string {
+	return strings.Join(*j, ", ")
+}
+
+func (j *JSONPathExpectList) Set(value string) error {
+	if _, err := parseJSONPathExpect(value); err != nil {
+		return err
+	}
+	*j = append(*j, value)
+	return nil
+}
+
+// jsonPathExpect is a parsed --expect-jsonpath: "PATH" requires the path to
+// exist, "PATH==VALUE" and "PATH!=VALUE" compare what it holds
+type jsonPathExpect struct {
+	path   string
+	op     string
+	value  any
+	source string
+}
+
+func parseJSONPathExpect(expr string) (jsonPathExpect, error) {
+	e := jsonPathExpect{path: strings.TrimSpace(expr), source: expr}
+	for _, op := range []string{"==", "!="} {
+		path, value, found := strings.Cut(expr, op)
+		if !found {
+			continue
+		}
+		e.path, e.op = strings.TrimSpace(path), op
+		value = strings.TrimSpace(value)
+		// The expected value is JSON when it parses as JSON and a plain
+		// string otherwise, so both $.status==ok and $.status=="ok" work
+		if err := json.Unmarshal([]byte(value), &e.value); err != nil {
+			e.value = value
+		}
+		break
+	}
+	if e.path == "" || e.path == "$" {
+		return e, fmt.Errorf("invalid JSON path assertion %q (e.g., '$.ok==true')", expr)
+	}
+	return e, nil
+}
+
+// expectsBody reports whether an expectation needs the whole response body
+func (c Config) expectsBody() bool {
+	return len(c.ExpectBodyContains) > 0 || len(c.ExpectJSONPath) > 0
+}
+
+// checkExpectations evaluates the --expect-* flags against the response and
+// reports every expectation that failed, not just the first
+func checkExpectations(resp *http.Response, body []byte, config Config) error {
+	var failures []error
+
+	if len(config.ExpectStatus) > 0 && !statusMatches(resp.StatusCode, config.ExpectStatus) {
+		failures = append(failures, fmt.Errorf("expected status %s, got %d", strings.Join(config.ExpectStatus, " or "), resp.StatusCode))
+	}
+
+	for _, header := range config.ExpectHeaders {
+		if err := checkHeader(resp.Header, header); err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	for _, text := range config.ExpectBodyContains {
+		if !strings.Contains(string(body), text) {
+			failures = append(failures, fmt.Errorf("expected body to contain %q", text))
+		}
+	}
+
+	if len(config.ExpectJSONPath) > 0 {
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			failures = append(failures, fmt.Errorf("expected a JSON body for --expect-jsonpath: %w", err))
+		} else {
+			for _, expr := range config.ExpectJSONPath {
+				if err := checkJSONPath(doc, expr); err != nil {
+					failures = append(failures, err)
+				}
+			}
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	return &exitError{code: exitAssertion, err: errors.Join(failures...)}
+}
+
+func statusMatches(code int, patterns []string) bool {
+	actual := strconv.Itoa(code)
+	for _, pattern := range patterns {
+		if pattern == actual || (strings.HasSuffix(pattern, "xx") && pattern[0] == actual[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHeader checks "Name" for presence, or "Name: value" for a value that
+// is equal or only differs by parameters, so "Content-Type:
+// application/json" accepts "application/json; charset=utf-8"
+func checkHeader(h http.Header, expected string) error {
+	name, value, hasValue := strings.Cut(expected, ":")
+	name = strings.TrimSpace(name)
+	values := h.Values(name)
+	if len(values) == 0 {
+		return fmt.Errorf("expected header %s", name)
+	}
+	if !hasValue {
+		return nil
+	}
+
+	value = strings.TrimSpace(value)
+	for _, actual := range values {
+		if actual == value || strings.HasPrefix(actual, value+";") {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected header %s: %s, got %s", name, value, strings.Join(values, ", "))
+}
+
+func checkJSONPath(doc any, expr string) error {
+	e, err := parseJSONPathExpect(expr)
+	if err != nil {
+		return err
+	}
+
+	actual, found := lookupJSONPath(doc, e.path)
+	switch {
+	case !found:
+		return fmt.Errorf("expected %s to exist", e.path)
+	case e.op == "==" && !reflect.DeepEqual(actual, e.value):
+		return fmt.Errorf("expected %s, got %s", e.source, jsonText(actual))
+	case e.op == "!=" && reflect.DeepEqual(actual, e.value):
+		return fmt.Errorf("expected %s, got %s", e.source, jsonText(actual))
+	}
+	return nil
+}
+
+func jsonText(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExpectations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("X-Version", "v2")
+		io.WriteString(w, `{"ok": true, "status": "up", "count": 3, "items": [{"id": 7}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		failures []string
+	}{
+		{"Status", []string{"--expect-status", "200"}, 0, nil},
+		{"Status class", []string{"--expect-status", "4xx,2xx"}, 0, nil},
+		{"Wrong status", []string{"--expect-status", "201", "--expect-status", "3xx"}, exitAssertion, []string{"expected status 201 or 3xx, got 200"}},
+		{"Header present", []string{"--expect-header", "X-Version"}, 0, nil},
+		{"Header value ignores parameters", []string{"--expect-header", "Content-Type: application/json"}, 0, nil},
+		{"Wrong header value", []string{"--expect-header", "X-Version: v3"}, exitAssertion, []string{"expected header X-Version: v3, got v2"}},
+		{"Missing header", []string{"--expect-header", "X-Missing"}, exitAssertion, []string{"expected header X-Missing"}},
+		{"Body contains", []string{"--expect-body-contains", `"status": "up"`}, 0, nil},
+		{"Body missing text", []string{"--expect-body-contains", "down"}, exitAssertion, []string{`expected body to contain "down"`}},
+		{"JSON path equals", []string{"--expect-jsonpath", "$.ok==true", "--expect-jsonpath", "$.status==up", "--expect-jsonpath", "count==3", "--expect-jsonpath", `$.items[0]=={"id": 7}`}, 0, nil},
+		{"JSON path not equal", []string{"--expect-jsonpath", "$.count!=0"}, 0, nil},
+		{"JSON path exists", []string{"--expect-jsonpath", "$.items[0].id"}, 0, nil},
+		{"JSON path fails", []string{"--expect-jsonpath", "$.ok==false", "--expect-jsonpath", "$.missing"}, exitAssertion, []string{"expected $.ok==false, got true", "expected $.missing to exist"}},
+		{"All failures reported", []string{"--expect-status", "500", "--expect-body-contains", "down"}, exitAssertion, []string{"expected status 500", `expected body to contain "down"`}},
+		{"Invalid status", []string{"--expect-status", "20"}, exitUsage, []string{`invalid status "20"`}},
+		{"Invalid JSON path", []string{"--expect-jsonpath", "==true"}, exitUsage, []string{"invalid JSON path assertion"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			code := Run(append(tt.args, server.URL), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr %q)", tt.code, code, stderr.String())
+			}
+			for _, failure := range tt.failures {
+				if !strings.Contains(stderr.String(), failure) {
+					t.Errorf("Expected %q in stderr, got %q", failure, stderr.String())
+				}
+			}
+			if tt.code == exitAssertion && !strings.Contains(stdout.String(), `"ok": true`) {
+				t.Errorf("Expected the response to be printed before failing, got %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestExpectJSONPathOnNonJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "plain text")
+	}))
+	defer server.Close()
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--expect-jsonpath", "$.ok", server.URL}, &stdout, &stderr); code != exitAssertion {
+		t.Fatalf("Expected exit code %d, got %d", exitAssertion, code)
+	}
+	if !strings.Contains(stderr.String(), "expected a JSON body") {
+		t.Errorf("Expected JSON body error, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// failOnStatus wraps handle for --fail and --fail-with-body: a 4xx or 5xx
+// response becomes an error with its own exit code, after printing the
+// response only with --fail-with-body
+func (r *requester) failOnStatus(handle func(*http.Response) error) func(*http.Response) error {
+	if !r.config.Fail && !r.config.FailWithBody {
+		return handle
+	}
+	return func(resp *http.Response) error {
+		if !isErrorStatus(resp.StatusCode) {
+			return handle(resp)
+		}
+		if r.config.FailWithBody {
+			if err := handle(resp); err != nil {
+				return err
+			}
+		}
+		return httpStatusError(resp)
+	}
+}
+
+// httpStatusError maps a 4xx response to exitClientError and a 5xx response
+// to exitServerError
+func httpStatusError(resp *http.Response) error {
+	code := exitClientError
+	if resp.StatusCode >= 500 {
+		code = exitServerError
+	}
+	return &exitError{code: code, err: fmt.Errorf("the server returned %s", resp.Status)}
+}
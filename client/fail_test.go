@@ -0,0 +1,60 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(status)
+		w.Write([]byte("body " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		printed  bool
+		category string
+	}{
+		{"Success", []string{"--fail", server.URL + "/200"}, 0, true, ""},
+		{"Redirect status", []string{"--fail", "--no-follow", server.URL + "/304"}, 0, false, ""},
+		{"Client error", []string{"--fail", server.URL + "/404"}, exitClientError, false, "client_error"},
+		{"Server error", []string{"--fail", server.URL + "/503"}, exitServerError, false, "server_error"},
+		{"Client error with body", []string{"--fail-with-body", server.URL + "/422"}, exitClientError, true, "client_error"},
+		{"Server error with body", []string{"--fail-with-body", server.URL + "/500"}, exitServerError, true, "server_error"},
+		{"Without --fail", []string{server.URL + "/404"}, 0, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			code := Run(tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr %q)", tt.code, code, stderr.String())
+			}
+			if printed := strings.Contains(stdout.String(), "body /"); printed != tt.printed {
+				t.Errorf("Expected body printed %t, got stdout %q", tt.printed, stdout.String())
+			}
+			if tt.code != 0 && !strings.Contains(stderr.String(), "Error: the server returned ") {
+				t.Errorf("Expected status error, got %q", stderr.String())
+			}
+			if tt.category != "" {
+				if category := errorCategory(&exitError{code: code}); category != tt.category {
+					t.Errorf("Expected category %q, got %q", tt.category, category)
+				}
+			}
+		})
+	}
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--fail", "--fail-with-body", server.URL + "/200"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for conflicting flags, got %d", exitUsage, code)
+	}
+}
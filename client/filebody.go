@@ -0,0 +1,75 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// fileBody streams a request body from a file. Its size is known up front,
+// so the request can send an exact Content-Length instead of being chunked.
+type fileBody struct {
+	*os.File
+	size int64
+}
+
+func openFileBody(filename string) (io.Reader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	// net/http treats a zero length with a non-nil body as unknown
+	if info.Size() == 0 {
+		file.Close()
+		return strings.NewReader(""), nil
+	}
+
+	return &fileBody{File: file, size: info.Size()}, nil
+}
+
+// reopen serves as the request's GetBody so the body can be sent again,
+// for example when following a 307 redirect
+func (f *fileBody) reopen() (io.ReadCloser, error) {
+	body, err := openFileBody(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	if file, ok := body.(*fileBody); ok {
+		return file, nil
+	}
+	return io.NopCloser(body), nil
+}
+
+// stdinBody streams stdin as the request body. When stdin is redirected
+// from a file the rest of it has a known size and is sent with a
+// Content-Length; a pipe or terminal is streamed with chunked encoding.
+type stdinBody struct {
+	io.Reader
+	size int64
+}
+
+// openStdinBody returns stdin as a request body without reading it into
+// memory. The reader hides Close so the transport doesn't close stdin.
+func openStdinBody() io.Reader {
+	info, err := os.Stdin.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return struct{ io.Reader }{os.Stdin}
+	}
+
+	offset, err := os.Stdin.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return struct{ io.Reader }{os.Stdin}
+	}
+	if remaining := info.Size() - offset; remaining > 0 {
+		return &stdinBody{Reader: os.Stdin, size: remaining}
+	}
+	return strings.NewReader("")
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileBodyContentLength(t *testing.T) {
+	var contentLength int64
+	var transferEncoding []string
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		transferEncoding = r.TransferEncoding
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"File", strings.Repeat("upload ", 1000)},
+		{"Empty file", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "upload.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			r, _, _ := newTestRequester(t, Config{Method: "PUT", URL: server.URL, Data: "@" + path})
+			if err := r.execute(); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			if contentLength != int64(len(tt.content)) {
+				t.Errorf("Expected Content-Length %d, got %d", len(tt.content), contentLength)
+			}
+			if len(transferEncoding) != 0 {
+				t.Errorf("Expected no Transfer-Encoding, got %v", transferEncoding)
+			}
+			if received != tt.content {
+				t.Errorf("Expected the file content to be sent, got %d bytes", len(received))
+			}
+		})
+	}
+}
+
+func TestFileBodyFollowsRedirect(t *testing.T) {
+	var received string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "upload.txt")
+	os.WriteFile(path, []byte("payload"), 0644)
+
+	r, _, _ := newTestRequester(t, Config{Method: "POST", URL: server.URL + "/old", Data: "@" + path})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if received != "payload" {
+		t.Errorf("Expected body to be resent after the redirect, got %q", received)
+	}
+}
+
+func TestStdinBodyIsStreamed(t *testing.T) {
+	var contentLength int64
+	var transferEncoding []string
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		contentLength, transferEncoding, body = r.ContentLength, r.TransferEncoding, string(data)
+	}))
+	defer server.Close()
+
+	content := "line one\r\nline two\n"
+
+	t.Run("Pipe is chunked", func(t *testing.T) {
+		setStdin(t, content)
+		r, _, _ := newTestRequester(t, Config{Method: "POST", URL: server.URL, Data: "-"})
+		if err := r.execute(); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+
+		if body != content {
+			t.Errorf("Expected stdin to be sent byte for byte, got %q", body)
+		}
+		if contentLength != -1 || len(transferEncoding) != 1 || transferEncoding[0] != "chunked" {
+			t.Errorf("Expected chunked encoding, got Content-Length %d and %v", contentLength, transferEncoding)
+		}
+	})
+
+	t.Run("Redirected file has a length", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "body.txt")
+		os.WriteFile(path, []byte(content), 0644)
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdin := os.Stdin
+		os.Stdin = file
+		t.Cleanup(func() {
+			os.Stdin = stdin
+			file.Close()
+		})
+
+		r, _, _ := newTestRequester(t, Config{Method: "POST", URL: server.URL, Data: "-"})
+		if err := r.execute(); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+
+		if body != content {
+			t.Errorf("Expected stdin to be sent byte for byte, got %q", body)
+		}
+		if contentLength != int64(len(content)) || len(transferEncoding) != 0 {
+			t.Errorf("Expected Content-Length %d, got %d and %v", len(content), contentLength, transferEncoding)
+		}
+	})
+}
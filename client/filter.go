@@ -0,0 +1,58 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// lineFilter streams a body one line at a time, passing on only the lines
+// that match include (when set) and don't match exclude (when set). Lines
+// are released as soon as they are complete, so the body is never held in
+// memory beyond the current line.
+type lineFilter struct {
+	io.Closer
+	reader  *bufio.Reader
+	include BodyPattern
+	exclude BodyPattern
+	pending []byte
+	err     error
+}
+
+func newLineFilter(body io.ReadCloser, include, exclude BodyPattern) *lineFilter {
+	return &lineFilter{
+		Closer:  body,
+		reader:  bufio.NewReader(body),
+		include: include,
+		exclude: exclude,
+	}
+}
+
+func (f *lineFilter) Read(p []byte) (int, error) {
+	for len(f.pending) == 0 {
+		if f.err != nil {
+			return 0, f.err
+		}
+
+		line, err := f.reader.ReadBytes('\n')
+		f.err = err
+		if len(line) > 0 && f.keep(line) {
+			f.pending = line
+		}
+	}
+
+	n := copy(p, f.pending)
+	f.pending = f.pending[n:]
+	return n, nil
+}
+
+func (f *lineFilter) keep(line []byte) bool {
+	text := bytes.TrimRight(line, "\r\n")
+	if f.include.Regexp != nil && !f.include.Match(text) {
+		return false
+	}
+	if f.exclude.Regexp != nil && f.exclude.Match(text) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chanWriter hands every write to a channel
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+func TestFilterStreamsMatchingLines(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("INFO starting\nERROR disk full\nDEBUG noise\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("ERROR disk still full\nINFO done\nERROR last"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := Config{URL: server.URL}
+	config.Filter.Set("^ERROR")
+	config.FilterOut.Set("still")
+	r, _, _ := newTestRequester(t, config)
+
+	out := make(chanWriter, 100)
+	r.stdout = out
+	done := make(chan error, 1)
+	go func() {
+		done <- r.execute()
+	}()
+
+	// The first matching line must be printed while the server is still
+	// holding back the rest of the body
+	timeout := time.After(2 * time.Second)
+	for first := false; !first; {
+		select {
+		case line := <-out:
+			if strings.Contains(line, "DEBUG") || strings.Contains(line, "INFO") {
+				t.Fatalf("Expected non-matching lines to be dropped, got %q", line)
+			}
+			first = line == "ERROR disk full\n"
+		case <-timeout:
+			t.Fatal("Expected the first matching line before the body completed")
+		}
+	}
+	release <- struct{}{}
+
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	close(out)
+
+	var rest []string
+	for line := range out {
+		rest = append(rest, line)
+	}
+	if got := strings.Join(rest, ""); got != "ERROR last" {
+		t.Errorf("Expected only the remaining matching line, got %q", got)
+	}
+}
+
+func TestFilterOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("a1\nb2\r\na3\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		filter    string
+		filterOut string
+		expected  string
+	}{
+		{"Filter", "^a", "", "a1\na3\n"},
+		{"Filter out", "", "^a", "b2\r\n"},
+		{"Both", "[0-9]$", "3", "a1\nb2\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{URL: server.URL}
+			if tt.filter != "" {
+				config.Filter.Set(tt.filter)
+			}
+			if tt.filterOut != "" {
+				config.FilterOut.Set(tt.filterOut)
+			}
+			r, stdout, _ := newTestRequester(t, config)
+			if err := r.execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(stdout.String(), "\n\n"+tt.expected) {
+				t.Errorf("Expected body %q, got output %q", tt.expected, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseContentTypeOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte(`{"a":1}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		override string
+		expected string
+	}{
+		{"Mislabeled body printed as is", "", `{"a":1}`},
+		{"Override formats as JSON", "application/json", "{\n  \"a\": 1\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, stdout, _ := newTestRequester(t, Config{
+				URL:                 server.URL,
+				PrettyPrint:         true,
+				ResponseContentType: tt.override,
+			})
+			if err := r.execute(); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			output := stdout.String()
+			if !strings.HasSuffix(output, tt.expected) {
+				t.Errorf("Expected body %q, got:\n%s", tt.expected, output)
+			}
+			if !strings.Contains(output, "Content-Type: application/octet-stream\n") {
+				t.Errorf("Expected the server's Content-Type to be printed, got:\n%s", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// expandFormDir turns every regular file in dir matching pattern into a
+// 'name=@path' form entry, named by prefix plus the file name
+func expandFormDir(dir, pattern, prefix string) ([]string, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid --form-dir-pattern %q: %w", pattern, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read form directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, entry.Name()); matched {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no files in %s match %q", dir, pattern)
+	}
+
+	forms := make([]string, 0, len(names))
+	for _, name := range names {
+		forms = append(forms, prefix+name+"=@"+filepath.Join(dir, name))
+	}
+
+	return forms, nil
+}
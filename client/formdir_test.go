@@ -0,0 +1,101 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type receivedPart struct {
+	name     string
+	filename string
+	content  string
+}
+
+func newMultipartServer(t *testing.T, parts *[]receivedPart) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			content, _ := io.ReadAll(part)
+			*parts = append(*parts, receivedPart{part.FormName(), part.FileName(), string(content)})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFormDirUpload(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "b.png"), []byte("image b"), 0644)
+	os.WriteFile(filepath.Join(dir, "a.png"), []byte("image a"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skip me"), 0644)
+	os.Mkdir(filepath.Join(dir, "sub.png"), 0755)
+
+	var parts []receivedPart
+	server := newMultipartServer(t, &parts)
+
+	r, _, _ := newTestRequester(t, Config{
+		Method:         "POST",
+		URL:            server.URL,
+		Form:           []string{"album=holiday"},
+		FormDir:        dir,
+		FormDirPattern: "*.png",
+		FormDirPrefix:  "photo-",
+	})
+	if err := r.do(server.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	expected := []receivedPart{
+		{"album", "", "holiday"},
+		{"photo-a.png", "a.png", "image a"},
+		{"photo-b.png", "b.png", "image b"},
+	}
+	if len(parts) != len(expected) {
+		t.Fatalf("Expected %d parts, got %d: %+v", len(expected), len(parts), parts)
+	}
+	for i, part := range parts {
+		if part != expected[i] {
+			t.Errorf("Part %d: expected %+v, got %+v", i, expected[i], part)
+		}
+	}
+}
+
+func TestExpandFormDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "report.csv"), []byte("a,b"), 0644)
+
+	forms, err := expandFormDir(dir, "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(forms) != 1 || forms[0] != "report.csv=@"+filepath.Join(dir, "report.csv") {
+		t.Errorf("Unexpected form entries: %v", forms)
+	}
+
+	if _, err := expandFormDir(dir, "*.png", ""); err == nil {
+		t.Error("Expected error when no files match")
+	}
+	if _, err := expandFormDir(dir, "[", ""); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+	if _, err := expandFormDir(filepath.Join(dir, "missing"), "*", ""); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipOutputName appends .gz to a --gzip-output file name unless present
+func gzipOutputName(name string) string {
+	if strings.HasSuffix(strings.ToLower(name), ".gz") {
+		return name
+	}
+	return name + ".gz"
+}
+
+// requestGzip asks the server for a gzip-encoded response when saving with
+// --gzip-output. Setting Accept-Encoding ourselves stops net/http from
+// transparently decompressing, so a gzip body can be written as-is instead
+// of being decompressed only to be compressed again.
+func requestGzip(req *http.Request) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+}
+
+// gzipEncoded reports whether the body still carries its gzip encoding
+func gzipEncoded(resp *http.Response) bool {
+	return strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip")
+}
+
+// gunzipBody returns the content of a gzip-encoded body, which the
+// --expect-* checks look at rather than the compressed bytes that are saved
+func gunzipBody(body []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	defer gz.Close()
+
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	return content, nil
+}
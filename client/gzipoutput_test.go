@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readGzipFile(t *testing.T, name string) string {
+	t.Helper()
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Output is not valid gzip: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to decompress output: %v", err)
+	}
+	return string(data)
+}
+
+func TestGzipOutput(t *testing.T) {
+	body := strings.Repeat(`{"line":"some log data"}`+"\n", 1000)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(body))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/encoded" && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(compressed.Bytes())
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	t.Run("Compresses plain body", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "data.json")
+		r, _, _ := newTestRequester(t, Config{URL: server.URL + "/plain", Output: output, GzipOutput: true})
+		if err := r.execute(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if got := readGzipFile(t, output+".gz"); got != body {
+			t.Errorf("Expected decompressed output to match the body, got %d bytes", len(got))
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("Expected no uncompressed file at %s", output)
+		}
+	})
+
+	t.Run("Keeps gzip-encoded body as-is", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "data.json.gz")
+		r, _, _ := newTestRequester(t, Config{URL: server.URL + "/encoded", Output: output, GzipOutput: true})
+		if err := r.execute(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		saved, _ := os.ReadFile(output)
+		if !bytes.Equal(saved, compressed.Bytes()) {
+			t.Error("Expected the server's gzip stream to be saved without recompressing")
+		}
+		if got := readGzipFile(t, output); got != body {
+			t.Errorf("Expected decompressed output to match the body, got %d bytes", len(got))
+		}
+	})
+
+	t.Run("Expectations see the uncompressed body", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "data.json.gz")
+		r, _, _ := newTestRequester(t, Config{
+			URL:                server.URL + "/encoded",
+			Output:             output,
+			GzipOutput:         true,
+			ExpectBodyContains: []string{`{"line":"some log data"}`},
+		})
+		if err := r.execute(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		saved, _ := os.ReadFile(output)
+		if !bytes.Equal(saved, compressed.Bytes()) {
+			t.Error("Expected the body to still be saved compressed")
+		}
+	})
+
+	t.Run("Output dir", func(t *testing.T) {
+		dir := t.TempDir()
+		r, _, _ := newTestRequester(t, Config{URL: server.URL + "/report.json", OutputDir: dir, GzipOutput: true})
+		if err := r.execute(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := readGzipFile(t, filepath.Join(dir, "report.json.gz")); got != body {
+			t.Errorf("Expected decompressed output to match the body, got %d bytes", len(got))
+		}
+	})
+}
+
+func TestGzipOutputRequiresOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--gzip-output", "http://127.0.0.1:1"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+	}
+	if !strings.Contains(stderr.String(), "--gzip-output requires") {
+		t.Errorf("Expected usage error, got: %s", stderr.String())
+	}
+}
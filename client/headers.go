@@ -0,0 +1,44 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// headersJSON is the --headers-json view of a response's status and headers.
+// Single-valued headers are strings, repeated headers are arrays.
+type headersJSON struct {
+	Proto   string         `json:"proto"`
+	Status  int            `json:"status"`
+	Reason  string         `json:"reason"`
+	Headers map[string]any `json:"headers"`
+}
+
+func writeHeadersJSON(w io.Writer, resp *http.Response) error {
+	encoder := json.NewEncoder(w)
+	return encoder.Encode(newHeadersJSON(resp))
+}
+
+func newHeadersJSON(resp *http.Response) headersJSON {
+	headers := make(map[string]any, len(resp.Header))
+	for key, values := range resp.Header {
+		if len(values) == 1 {
+			headers[key] = values[0]
+		} else {
+			headers[key] = values
+		}
+	}
+
+	reason := http.StatusText(resp.StatusCode)
+	if len(resp.Status) > 4 {
+		reason = resp.Status[4:]
+	}
+
+	return headersJSON{
+		Proto:   resp.Proto,
+		Status:  resp.StatusCode,
+		Reason:  reason,
+		Headers: headers,
+	}
+}
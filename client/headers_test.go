@@ -0,0 +1,109 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHeadersJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	r, stdout, _ := newTestRequester(t, Config{URL: server.URL, HeadersJSON: true})
+	if err := r.do(server.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	headerLine, body, found := strings.Cut(stdout.String(), "\n")
+	if !found || body != "body" {
+		t.Fatalf("Expected JSON line followed by body, got:\n%s", stdout.String())
+	}
+
+	var doc struct {
+		Proto   string                     `json:"proto"`
+		Status  int                        `json:"status"`
+		Reason  string                     `json:"reason"`
+		Headers map[string]json.RawMessage `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(headerLine), &doc); err != nil {
+		t.Fatalf("Headers are not valid JSON: %v\n%s", err, headerLine)
+	}
+
+	if doc.Proto != "HTTP/1.1" || doc.Status != 201 || doc.Reason != "Created" {
+		t.Errorf("Unexpected status fields: %+v", doc)
+	}
+	if got := string(doc.Headers["Content-Type"]); got != `"text/plain"` {
+		t.Errorf("Expected single-valued header as string, got %s", got)
+	}
+	if got := string(doc.Headers["Set-Cookie"]); got != `["a=1","b=2"]` {
+		t.Errorf("Expected multi-valued header as array, got %s", got)
+	}
+	if strings.Contains(stdout.String(), "HTTP/1.1 201 Created\n") {
+		t.Error("Text header dump should be replaced by JSON")
+	}
+}
+
+func TestFoldHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Origin")
+		w.Header().Add("Set-Cookie", "a=1; Expires=Wed, 21 Oct 2026 07:28:00 GMT")
+		w.Header().Add("Set-Cookie", "b=2")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		fold        bool
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:        "Unfolded",
+			fold:        false,
+			expected:    []string{"Vary: Accept\n", "Vary: Origin\n"},
+			notExpected: []string{"Vary: Accept, Origin\n"},
+		},
+		{
+			name:        "Folded",
+			fold:        true,
+			expected:    []string{"Vary: Accept, Origin\n"},
+			notExpected: []string{"Vary: Accept\n", "Vary: Origin\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, stdout, _ := newTestRequester(t, Config{URL: server.URL, FoldHeaders: tt.fold})
+			if err := r.do(server.URL, true, r.printResponse); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			output := stdout.String()
+			for _, line := range tt.expected {
+				if !strings.Contains(output, line) {
+					t.Errorf("Expected %q in output:\n%s", line, output)
+				}
+			}
+			for _, line := range tt.notExpected {
+				if strings.Contains(output, line) {
+					t.Errorf("Did not expect %q in output:\n%s", line, output)
+				}
+			}
+
+			// Set-Cookie stays one line per cookie either way
+			if !strings.Contains(output, "Set-Cookie: a=1; Expires=Wed, 21 Oct 2026 07:28:00 GMT\n") || !strings.Contains(output, "Set-Cookie: b=2\n") {
+				t.Errorf("Expected Set-Cookie to never be folded, got:\n%s", output)
+			}
+		})
+	}
+}
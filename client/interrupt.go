@@ -0,0 +1,88 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptContext returns a context that is canceled on SIGINT or SIGTERM,
+// so an in-flight request is torn down instead of the process dying mid-write
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// receivedBody counts the response bytes read so far
+type receivedBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *receivedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func interruptedError(received int64) error {
+	return &exitError{
+		code: exitInterrupted,
+		err:  fmt.Errorf("interrupted after receiving %d bytes", received),
+	}
+}
+
+// saveBody streams the response body to the --output file, or to a file
+// named after the URL in --output-dir. When the copy is cut short by an
+// interrupt the partial file is kept for resuming, unless
+// --remove-on-interrupt is set.
+func (r *requester) saveBody(resp *http.Response) error {
+	file, err := r.createOutput(resp)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	return r.writeBody(file, resp, r.config.GzipOutput && !gzipEncoded(resp))
+}
+
+// writeBody copies the response body into file, gzip-compressing it when
+// compress is set, and closes it
+func (r *requester) writeBody(file *os.File, resp *http.Response, compress bool) error {
+	if err := r.transformSavedBody(resp); err != nil {
+		file.Close()
+		return err
+	}
+
+	var dst io.Writer = file
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(file)
+		dst = gz
+	}
+
+	_, copyErr := io.Copy(dst, resp.Body)
+	if gz != nil && copyErr == nil {
+		copyErr = gz.Close()
+	}
+	closeErr := file.Close()
+
+	if copyErr != nil {
+		if r.ctx.Err() != nil {
+			if r.config.RemoveOnInterrupt {
+				os.Remove(file.Name())
+			} else {
+				fmt.Fprintf(r.stderr, "Partial output kept in %s\n", file.Name())
+			}
+		}
+		return fmt.Errorf("failed to write output file: %w", copyErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write output file: %w", closeErr)
+	}
+
+	return nil
+}
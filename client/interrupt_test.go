@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterruptDuringDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 10)))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		removeOnInterrupt bool
+		expectFile        bool
+	}{
+		{"Partial file kept", false, true},
+		{"Partial file removed", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "download")
+			r, _, stderr := newTestRequester(t, Config{
+				URL:               server.URL,
+				Output:            output,
+				RemoveOnInterrupt: tt.removeOnInterrupt,
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r.ctx = ctx
+
+			// Interrupt once the first chunk has made it to disk
+			go func() {
+				for {
+					if info, err := os.Stat(output); err == nil && info.Size() == 10 {
+						cancel()
+						return
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}()
+
+			err := r.execute()
+			if err == nil {
+				t.Fatal("Expected interrupted download to fail")
+			}
+			if !strings.Contains(err.Error(), "interrupted after receiving 10 bytes") {
+				t.Errorf("Expected partial byte report, got: %v", err)
+			}
+			if code := exitCode(err); code != exitInterrupted {
+				t.Errorf("Expected exit code %d, got %d", exitInterrupted, code)
+			}
+
+			data, statErr := os.ReadFile(output)
+			if !tt.expectFile {
+				if statErr == nil {
+					t.Error("Expected partial file to be removed")
+				}
+				return
+			}
+			if statErr != nil {
+				t.Fatalf("Expected partial file to be kept: %v", statErr)
+			}
+			if len(data) != 10 {
+				t.Errorf("Expected 10 bytes in partial file, got %d", len(data))
+			}
+			if !strings.Contains(stderr.String(), "Partial output kept in "+output) {
+				t.Errorf("Expected partial output notice, got: %q", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// jsonAccept is the Accept header --json sends unless -H overrides it
+const jsonAccept = "application/json, */*;q=0.5"
+
+type JSONFieldList []string
+
+func (j *JSONFieldList) String() string {
+	return strings.Join(*j, ", ")
+}
+
+func (j *JSONFieldList) Set(value string) error {
+	*j = append(*j, value)
+	return nil
+}
+
+// buildJSONFields assembles a JSON object from HTTPie-style fields:
+//
+//	key=value      string value
+//	key:=value     raw JSON value (number, bool, null, array or object)
+//	key=@file      file contents as a string
+//	key:=@file     file contents parsed as JSON
+//
+// Keys may address nested objects with brackets, e.g. user[name]=alice.
+func buildJSONFields(fields []string) ([]byte, error) {
+	obj := make(map[string]any)
+
+	for _, field := range fields {
+		key, value, err := parseJSONField(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON field %q: %w", field, err)
+		}
+
+		path, err := splitFieldKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON field %q: %w", field, err)
+		}
+
+		if err := setPointer(obj, path, value); err != nil {
+			return nil, fmt.Errorf("invalid JSON field %q: %w", field, err)
+		}
+	}
+
+	return json.Marshal(obj)
+}
+
+func parseJSONField(field string) (string, any, error) {
+	sep := strings.IndexByte(field, '=')
+	if sep <= 0 {
+		return "", nil, fmt.Errorf("must be in 'key=value' or 'key:=json' format")
+	}
+
+	key, raw := field[:sep], false
+	if strings.HasSuffix(key, ":") {
+		key, raw = key[:len(key)-1], true
+	}
+	if key == "" {
+		return "", nil, fmt.Errorf("empty key")
+	}
+
+	value := field[sep+1:]
+	if strings.HasPrefix(value, "@") {
+		filename := value[1:]
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+		}
+		value = string(content)
+	}
+
+	if !raw {
+		return key, value, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return "", nil, fmt.Errorf("value is not valid JSON: %w", err)
+	}
+	if decoder.More() {
+		return "", nil, fmt.Errorf("value is not valid JSON: unexpected trailing data")
+	}
+
+	return key, decoded, nil
+}
+
+// splitFieldKey turns "a[b][c]" into ["a", "b", "c"]
+func splitFieldKey(key string) ([]string, error) {
+	open := strings.IndexByte(key, '[')
+	if open < 0 {
+		return []string{key}, nil
+	}
+	if open == 0 {
+		return nil, fmt.Errorf("key %q must start with a name", key)
+	}
+
+	path := []string{key[:open]}
+	rest := key[open:]
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return nil, fmt.Errorf("key %q has unbalanced brackets", key)
+		}
+		name := rest[1:end]
+		if name == "" {
+			return nil, fmt.Errorf("key %q has an empty segment", key)
+		}
+		path = append(path, name)
+		rest = rest[end+1:]
+	}
+
+	return path, nil
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildJSONFields(t *testing.T) {
+	dir := t.TempDir()
+	textFile := filepath.Join(dir, "note.txt")
+	jsonFile := filepath.Join(dir, "data.json")
+	os.WriteFile(textFile, []byte("hello\nworld"), 0644)
+	os.WriteFile(jsonFile, []byte(`{"nested": [1, 2]}`), 0644)
+
+	tests := []struct {
+		name     string
+		fields   []string
+		expected string
+	}{
+		{"String", []string{"name=alice"}, `{"name":"alice"}`},
+		{"String that looks like a number", []string{"zip=01234"}, `{"zip":"01234"}`},
+		{"Raw number", []string{"age:=30"}, `{"age":30}`},
+		{"Raw large number keeps precision", []string{"id:=12345678901234567890"}, `{"id":12345678901234567890}`},
+		{"Raw bool and null", []string{"ok:=true", "gone:=null"}, `{"gone":null,"ok":true}`},
+		{"Raw array", []string{"tags:=[\"a\",\"b\"]"}, `{"tags":["a","b"]}`},
+		{"Raw nested object", []string{`user:={"name":"alice","roles":["admin"]}`}, `{"user":{"name":"alice","roles":["admin"]}}`},
+		{"Bracket nested object", []string{"user[name]=alice", "user[address][city]=Paris", "user[age]:=30"}, `{"user":{"address":{"city":"Paris"},"age":30,"name":"alice"}}`},
+		{"File as string", []string{"note=@" + textFile}, `{"note":"hello\nworld"}`},
+		{"File as JSON", []string{"data:=@" + jsonFile}, `{"data":{"nested":[1,2]}}`},
+		{"Value containing equals", []string{"query=a=b"}, `{"query":"a=b"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := buildJSONFields(tt.fields)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestBuildJSONFieldsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+	}{
+		{"Missing separator", []string{"name"}},
+		{"Empty key", []string{"=value"}},
+		{"Invalid raw JSON", []string{"age:=thirty"}},
+		{"Trailing raw JSON", []string{"age:=1 2"}},
+		{"Missing file", []string{"data=@/does/not/exist"}},
+		{"Unbalanced brackets", []string{"user[name=alice"}},
+		{"Conflicting nesting", []string{"user=alice", "user[name]=alice"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildJSONFields(tt.fields); err == nil {
+				t.Errorf("Expected error for fields %v", tt.fields)
+			}
+		})
+	}
+}
+
+func TestJSONFieldsRequest(t *testing.T) {
+	var contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		contentType, body = r.Header.Get("Content-Type"), string(data)
+	}))
+	defer server.Close()
+
+	r, _, _ := newTestRequester(t, Config{
+		Method:     "POST",
+		URL:        server.URL,
+		JSONFields: []string{"name=test", "count:=2"},
+	})
+	if err := r.do(server.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %s", contentType)
+	}
+	if body != `{"count":2,"name":"test"}` {
+		t.Errorf("Unexpected body: %s", body)
+	}
+}
+
+func TestJSONMode(t *testing.T) {
+	var method, contentType, accept, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, body = r.Method, string(data)
+		contentType, accept = r.Header.Get("Content-Type"), r.Header.Get("Accept")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		args   []string
+		method string
+		body   string
+		accept string
+	}{
+		{"Positional fields", []string{"--json", server.URL, "name=test", "count:=2", "tags:=[\"a\"]"}, "POST", `{"count":2,"name":"test","tags":["a"]}`, jsonAccept},
+		{"Combined with --json-field", []string{"--json", "--json-field", "a=1", server.URL, "b=2"}, "POST", `{"a":"1","b":"2"}`, jsonAccept},
+		{"No fields", []string{"--json", server.URL}, "GET", "", jsonAccept},
+		{"Accept can be overridden", []string{"--json", "-H", "Accept: application/vnd.api+json", server.URL, "a=1"}, "POST", `{"a":"1"}`, "application/vnd.api+json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(tt.args, &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected success, got exit code %d: %s", code, stderr.String())
+			}
+			if method != tt.method {
+				t.Errorf("Expected method %s, got %s", tt.method, method)
+			}
+			if body != tt.body {
+				t.Errorf("Expected body %s, got %s", tt.body, body)
+			}
+			if tt.body != "" && contentType != "application/json" {
+				t.Errorf("Expected Content-Type application/json, got %s", contentType)
+			}
+			if accept != tt.accept {
+				t.Errorf("Expected Accept %q, got %q", tt.accept, accept)
+			}
+		})
+	}
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--json", server.URL, "not-a-field"}, &stdout, &stderr); code == 0 {
+		t.Error("Expected an argument without '=' to be rejected")
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"http-client/response"
+)
+
+var jwtPattern = regexp.MustCompile(`[A-Za-z0-9_-]{2,}\.[A-Za-z0-9_-]{2,}\.[A-Za-z0-9_-]*`)
+
+// decodeJWT decodes the header and payload segments of a JWT without
+// verifying its signature
+func decodeJWT(token string) (header, payload []byte, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("JWT must have three segments, got %d", len(parts))
+	}
+
+	header, err = decodeJWTSegment(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+
+	payload, err = decodeJWTSegment(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+
+	return header, payload, nil
+}
+
+func decodeJWTSegment(segment string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	}
+
+	return data, nil
+}
+
+// findJWTs returns the distinct strings in text that decode as JWTs
+func findJWTs(text string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+
+	for _, candidate := range jwtPattern.FindAllString(text, -1) {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		header, _, err := decodeJWT(candidate)
+		if err != nil {
+			continue
+		}
+
+		var fields map[string]any
+		json.Unmarshal(header, &fields)
+		if _, ok := fields["alg"]; !ok {
+			continue
+		}
+
+		tokens = append(tokens, candidate)
+	}
+
+	return tokens
+}
+
+// printJWTs decodes JWTs found in the named response header and in the
+// body, leaving the body in place for the formatter. Only text bodies are
+// searched, so downloads and other binary responses still stream.
+func printJWTs(w io.Writer, resp *http.Response, headerName string) error {
+	var sources []string
+	var tokens []string
+
+	if headerName != "" {
+		for _, value := range resp.Header.Values(headerName) {
+			for _, token := range findJWTs(value) {
+				sources = append(sources, "header "+http.CanonicalHeaderKey(headerName))
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	if jwtBody(resp) {
+		body, err := bufferBody(resp)
+		if err != nil {
+			return err
+		}
+
+		for _, token := range findJWTs(string(body)) {
+			sources = append(sources, "body")
+			tokens = append(tokens, token)
+		}
+	}
+
+	for i, token := range tokens {
+		header, payload, _ := decodeJWT(token)
+		fmt.Fprintf(w, "JWT in %s:\n", sources[i])
+		fmt.Fprintf(w, "  header:  %s\n", indentJSON(header))
+		fmt.Fprintf(w, "  payload: %s\n", indentJSON(payload))
+	}
+
+	return nil
+}
+
+// jwtBody reports whether resp's body may hold a JWT worth searching for:
+// text such as JSON, or a bare token, that isn't still compressed
+func jwtBody(resp *http.Response) bool {
+	if resp.Header.Get("Content-Encoding") != "" && !resp.Uncompressed {
+		return false
+	}
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return response.IsText(contentType) || mediaType == "application/jwt"
+}
+
+func indentJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "  ", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const sampleJWT = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+	"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+
+func TestDecodeJWT(t *testing.T) {
+	header, payload, err := decodeJWT(sampleJWT)
+	if err != nil {
+		t.Fatalf("Failed to decode JWT: %v", err)
+	}
+
+	var h map[string]any
+	if err := json.Unmarshal(header, &h); err != nil {
+		t.Fatalf("Header is not JSON: %v", err)
+	}
+	if h["alg"] != "HS256" || h["typ"] != "JWT" {
+		t.Errorf("Unexpected header: %s", header)
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("Payload is not JSON: %v", err)
+	}
+	if claims["sub"] != "1234567890" || claims["name"] != "John Doe" || claims["iat"] != float64(1516239022) {
+		t.Errorf("Unexpected claims: %s", payload)
+	}
+}
+
+func TestDecodeJWTErrors(t *testing.T) {
+	tests := []string{
+		"not-a-jwt",
+		"a.b",
+		"!!!.eyJzdWIiOiIxIn0.sig",
+		"eyJhbGciOiJIUzI1NiJ9.bm90IGpzb24.sig",
+	}
+
+	for _, token := range tests {
+		if _, _, err := decodeJWT(token); err == nil {
+			t.Errorf("Expected error decoding %q", token)
+		}
+	}
+}
+
+func TestFindJWTs(t *testing.T) {
+	text := fmt.Sprintf(`{"access_token":"%s","version":"1.2.3","host":"api.example.com","again":"%s"}`, sampleJWT, sampleJWT)
+
+	tokens := findJWTs(text)
+	if len(tokens) != 1 || tokens[0] != sampleJWT {
+		t.Errorf("Expected only the sample JWT, got %v", tokens)
+	}
+}
+
+func TestDecodeJWTFromResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session="+sampleJWT+"; HttpOnly")
+		fmt.Fprintf(w, `{"token":"%s"}`, sampleJWT)
+	}))
+	defer server.Close()
+
+	r, stdout, stderr := newTestRequester(t, Config{URL: server.URL, DecodeJWT: true, JWTHeader: "set-cookie"})
+	if err := r.do(server.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	output := stderr.String()
+	if !strings.Contains(output, "JWT in header Set-Cookie:") || !strings.Contains(output, "JWT in body:") {
+		t.Errorf("Expected JWTs from header and body, got:\n%s", output)
+	}
+	if strings.Count(output, `"name": "John Doe"`) != 2 {
+		t.Errorf("Expected decoded claims for both JWTs, got:\n%s", output)
+	}
+	if !strings.HasSuffix(stdout.String(), `{"token":"`+sampleJWT+`"}`) {
+		t.Errorf("Expected body to still be printed, got:\n%s", stdout.String())
+	}
+}
+
+func TestDecodeJWTSkipsBinaryBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		found       bool
+	}{
+		{"JSON", "application/json", true},
+		{"Bare token", "application/jwt", true},
+		{"Binary", "application/octet-stream", false},
+		{"Image", "image/png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := io.NopCloser(strings.NewReader(sampleJWT))
+			resp := &http.Response{Header: http.Header{"Content-Type": {tt.contentType}}, Body: body}
+			var stderr bytes.Buffer
+			if err := printJWTs(&stderr, resp, ""); err != nil {
+				t.Fatal(err)
+			}
+			if found := strings.Contains(stderr.String(), "JWT in body:"); found != tt.found {
+				t.Errorf("Expected found=%t, got %q", tt.found, stderr.String())
+			}
+			if !tt.found && resp.Body != body {
+				t.Error("Expected a binary body to be left unread")
+			}
+		})
+	}
+}
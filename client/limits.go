@@ -0,0 +1,125 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a flag value accepting sizes like "512", "10K", "5M" or "1G"
+type ByteSize int64
+
+func (b *ByteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *ByteSize) Set(value string) error {
+	size, err := parseSize(value)
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(size)
+	return nil
+}
+
+// parseSize parses a byte count with an optional binary suffix (K, M, G, T)
+func parseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	str = strings.TrimSuffix(strings.TrimSuffix(str, "B"), "I")
+
+	multiplier := int64(1)
+	if str != "" {
+		switch str[len(str)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			str = str[:len(str)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g., '512', '10K', '5M', '1G')", s)
+	}
+	if n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+
+	return n * multiplier, nil
+}
+
+// checkContentLength refuses a response whose advertised size exceeds max
+// without touching the body
+func checkContentLength(resp *http.Response, max int64) error {
+	if max > 0 && resp.ContentLength > max {
+		return fmt.Errorf("response size of %d bytes exceeds --max-filesize of %d bytes", resp.ContentLength, max)
+	}
+	return nil
+}
+
+// isHeaderLimitError reports whether err is the transport refusing a
+// response whose headers exceed Transport.MaxResponseHeaderBytes. net/http
+// doesn't export a sentinel for it, so the message is matched.
+func isHeaderLimitError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "server response headers exceeded")
+}
+
+// bodyLimit returns the streaming guard to apply to a response body. When the
+// server doesn't advertise a Content-Length, --max-filesize can't be checked
+// up front and falls back to guarding the stream like --max-body.
+func bodyLimit(resp *http.Response, maxFileSize, maxBody int64) int64 {
+	limit := maxBody
+	if resp.ContentLength < 0 && maxFileSize > 0 && (limit <= 0 || maxFileSize < limit) {
+		limit = maxFileSize
+	}
+	return limit
+}
+
+// limitedBody fails reads once more than limit bytes have been streamed
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func newLimitedBody(body io.ReadCloser, limit int64) *limitedBody {
+	return &limitedBody{
+		ReadCloser: body,
+		limit:      limit,
+		remaining:  limit,
+	}
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.tooLarge()
+	}
+
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	if int64(n) > l.remaining {
+		n = int(l.remaining)
+		l.remaining = -1
+		return n, l.tooLarge()
+	}
+
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func (l *limitedBody) tooLarge() error {
+	return fmt.Errorf("response body exceeds limit of %d bytes", l.limit)
+}
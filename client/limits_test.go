@@ -0,0 +1,194 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    int64
+		expectError bool
+	}{
+		{"512", 512, false},
+		{"10K", 10 << 10, false},
+		{"10k", 10 << 10, false},
+		{"5M", 5 << 20, false},
+		{"5MB", 5 << 20, false},
+		{"1GiB", 1 << 30, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"-1", 0, true},
+		{"8388607T", 8388607 << 40, false},
+		{"8388608T", 0, true},
+		{"99999999999G", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := parseSize(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for size %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for size %q: %v", tt.input, err)
+			}
+			if size != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, size)
+			}
+		})
+	}
+}
+
+func TestMaxFileSizeRefusesByContentLength(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	r, _, _ := newTestRequester(t, Config{URL: server.URL, MaxFileSize: 10})
+	err := r.execute()
+	if err == nil {
+		t.Fatal("Expected response to be refused")
+	}
+	if !strings.Contains(err.Error(), "exceeds --max-filesize") {
+		t.Errorf("Expected Content-Length refusal, got: %v", err)
+	}
+}
+
+func TestCheckContentLengthDoesNotReadBody(t *testing.T) {
+	body := &countingReader{Reader: strings.NewReader(strings.Repeat("x", 100))}
+	resp := &http.Response{ContentLength: 100, Body: io.NopCloser(body)}
+
+	if err := checkContentLength(resp, 10); err == nil {
+		t.Error("Expected oversized Content-Length to be refused")
+	}
+	if body.n != 0 {
+		t.Errorf("Expected body to be untouched, but %d bytes were read", body.n)
+	}
+
+	if err := checkContentLength(resp, 100); err != nil {
+		t.Errorf("Expected Content-Length at the limit to pass: %v", err)
+	}
+}
+
+func TestMaxFileSizeFallsBackToStreamingGuard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before the body is complete forces chunked encoding,
+		// so no Content-Length is advertised
+		for i := 0; i < 10; i++ {
+			w.Write([]byte(strings.Repeat("x", 10)))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength != -1 {
+		t.Fatalf("Expected unknown Content-Length, got %d", resp.ContentLength)
+	}
+	if err := checkContentLength(resp, 50); err != nil {
+		t.Fatalf("Unknown Content-Length should not be refused up front: %v", err)
+	}
+
+	limit := bodyLimit(resp, 50, 0)
+	if limit != 50 {
+		t.Fatalf("Expected streaming guard of 50 bytes, got %d", limit)
+	}
+
+	data, err := io.ReadAll(newLimitedBody(resp.Body, limit))
+	if err == nil {
+		t.Fatal("Expected streaming guard to abort the read")
+	}
+	if len(data) != 50 {
+		t.Errorf("Expected exactly 50 bytes before aborting, got %d", len(data))
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		contentLength int64
+		maxFileSize   int64
+		maxBody       int64
+		expected      int64
+	}{
+		{"No limits", -1, 0, 0, 0},
+		{"Known length uses max-body only", 100, 50, 0, 0},
+		{"Unknown length falls back to max-filesize", -1, 50, 0, 50},
+		{"Unknown length uses smaller max-body", -1, 50, 20, 20},
+		{"Unknown length uses smaller max-filesize", -1, 20, 50, 20},
+		{"Max-body alone", 100, 0, 30, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{ContentLength: tt.contentLength}
+			if got := bodyLimit(resp, tt.maxFileSize, tt.maxBody); got != tt.expected {
+				t.Errorf("Expected limit %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestMaxHeaderBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Padding", strings.Repeat("x", 8<<10))
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		maxHeaderBytes int64
+		expectError    bool
+	}{
+		{"Oversized headers rejected", 4 << 10, true},
+		{"Headers under the cap accepted", 64 << 10, false},
+		{"No cap uses transport default", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _, _ := newTestRequester(t, Config{URL: server.URL, MaxHeaderBytes: tt.maxHeaderBytes})
+
+			err := r.do(server.URL, true, r.printResponse)
+			if !tt.expectError {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected oversized headers to be rejected")
+			}
+			if !strings.Contains(err.Error(), "--max-header-bytes") {
+				t.Errorf("Expected error to mention --max-header-bytes, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the request duration histogram,
+// matching the Prometheus client defaults
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metrics collects what --metrics-prom reports for a run. A nil *metrics
+// ignores every observation, so callers don't need to check for it.
+type metrics struct {
+	mutex          sync.Mutex
+	requests       map[string]int
+	responseBytes  int64
+	bucketCounts   []int
+	latencySum     float64
+	latencyCount   int
+	retries        int
+	rateLimitWaits int
+	rateLimitTime  time.Duration
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:     make(map[string]int),
+		bucketCounts: make([]int, len(latencyBuckets)),
+	}
+}
+
+// observeRequest records a finished request. code is the status code, or
+// "error" when no response was received.
+func (m *metrics) observeRequest(code string, bytes int64, latency time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.requests[code]++
+	m.responseBytes += bytes
+
+	seconds := latency.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			m.bucketCounts[i]++
+		}
+	}
+	m.latencySum += seconds
+	m.latencyCount++
+}
+
+func (m *metrics) observeRetry() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.retries++
+}
+
+// observeRateLimitWait records time spent in the rate limiter. Passing an
+// open limiter takes microseconds, so only waits of a millisecond or more
+// are counted as waits.
+func (m *metrics) observeRateLimitWait(d time.Duration) {
+	if m == nil || d < time.Millisecond {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.rateLimitWaits++
+	m.rateLimitTime += d
+}
+
+// merge adds the observations of other to m, for runs like a collection
+// where each request has its own requester
+func (m *metrics) merge(other *metrics) {
+	if m == nil || other == nil {
+		return
+	}
+	other.mutex.Lock()
+	defer other.mutex.Unlock()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for code, n := range other.requests {
+		m.requests[code] += n
+	}
+	m.responseBytes += other.responseBytes
+	for i, n := range other.bucketCounts {
+		m.bucketCounts[i] += n
+	}
+	m.latencySum += other.latencySum
+	m.latencyCount += other.latencyCount
+	m.retries += other.retries
+	m.rateLimitWaits += other.rateLimitWaits
+	m.rateLimitTime += other.rateLimitTime
+}
+
+// write renders the metrics in the Prometheus text exposition format
+func (m *metrics) write(w io.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	fmt.Fprintln(w, "# HELP http_client_requests_total Requests sent, by response status code or \"error\" when none was received.")
+	fmt.Fprintln(w, "# TYPE http_client_requests_total counter")
+	codes := make([]string, 0, len(m.requests))
+	for code := range m.requests {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "http_client_requests_total{code=%q} %d\n", code, m.requests[code])
+	}
+
+	fmt.Fprintln(w, "# HELP http_client_response_bytes_total Response body bytes received.")
+	fmt.Fprintln(w, "# TYPE http_client_response_bytes_total counter")
+	fmt.Fprintf(w, "http_client_response_bytes_total %d\n", m.responseBytes)
+
+	fmt.Fprintln(w, "# HELP http_client_request_duration_seconds Time from sending a request to reading the whole response.")
+	fmt.Fprintln(w, "# TYPE http_client_request_duration_seconds histogram")
+	for i, bound := range latencyBuckets {
+		fmt.Fprintf(w, "http_client_request_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), m.bucketCounts[i])
+	}
+	fmt.Fprintf(w, "http_client_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.latencyCount)
+	fmt.Fprintf(w, "http_client_request_duration_seconds_sum %g\n", m.latencySum)
+	fmt.Fprintf(w, "http_client_request_duration_seconds_count %d\n", m.latencyCount)
+
+	fmt.Fprintln(w, "# HELP http_client_retries_total Requests retried.")
+	fmt.Fprintln(w, "# TYPE http_client_retries_total counter")
+	fmt.Fprintf(w, "http_client_retries_total %d\n", m.retries)
+
+	fmt.Fprintln(w, "# HELP http_client_rate_limit_waits_total Requests that waited on the rate limiter.")
+	fmt.Fprintln(w, "# TYPE http_client_rate_limit_waits_total counter")
+	fmt.Fprintf(w, "http_client_rate_limit_waits_total %d\n", m.rateLimitWaits)
+
+	fmt.Fprintln(w, "# HELP http_client_rate_limit_wait_seconds_total Time spent waiting on the rate limiter.")
+	fmt.Fprintln(w, "# TYPE http_client_rate_limit_wait_seconds_total counter")
+	fmt.Fprintf(w, "http_client_rate_limit_wait_seconds_total %g\n", m.rateLimitTime.Seconds())
+}
+
+// writeFile replaces path atomically, as the node_exporter textfile
+// collector may read it at any time
+func (m *metrics) writeFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	m.write(tmp)
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// metricsTransport observes every request that goes through the client
+type metricsTransport struct {
+	base    http.RoundTripper
+	metrics *metrics
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.metrics.observeRequest("error", 0, time.Since(start))
+		return nil, err
+	}
+
+	resp.Body = &metricsBody{
+		ReadCloser: resp.Body,
+		done: func(n int64) {
+			t.metrics.observeRequest(strconv.Itoa(resp.StatusCode), n, time.Since(start))
+		},
+	}
+	return resp, nil
+}
+
+// metricsBody reports the bytes read once the body is closed
+type metricsBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+func (b *metricsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *metricsBody) Close() error {
+	b.once.Do(func() { b.done(b.n) })
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMetricsProm(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 2 {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte("12345"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "http_client.prom")
+	var stdout, stderr bytes.Buffer
+	Run([]string{"--repeat", "3", "--rate", "100/s", "--metrics-prom", path, server.URL}, &stdout, &stderr)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected metrics file: %v", err)
+	}
+	output := string(data)
+
+	expected := []string{
+		"# HELP http_client_requests_total ",
+		"# TYPE http_client_requests_total counter\n",
+		"http_client_requests_total{code=\"200\"} 2\n",
+		"http_client_requests_total{code=\"404\"} 1\n",
+		"# TYPE http_client_response_bytes_total counter\n",
+		"http_client_response_bytes_total 15\n",
+		"# TYPE http_client_request_duration_seconds histogram\n",
+		"http_client_request_duration_seconds_bucket{le=\"10\"} 3\n",
+		"http_client_request_duration_seconds_bucket{le=\"+Inf\"} 3\n",
+		"http_client_request_duration_seconds_count 3\n",
+		"http_client_retries_total 0\n",
+		"# TYPE http_client_rate_limit_waits_total counter\n",
+		"# TYPE http_client_rate_limit_wait_seconds_total counter\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected %q in metrics:\n%s", line, output)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if !strings.HasPrefix(line, "# ") && !strings.HasPrefix(line, "http_client_") {
+			t.Errorf("Unexpected line in metrics: %q", line)
+		}
+	}
+}
+
+func TestMetricsHistogramBuckets(t *testing.T) {
+	m := newMetrics()
+	m.observeRequest("200", 0, 20*time.Millisecond)
+	m.observeRequest("200", 0, 300*time.Millisecond)
+	m.observeRequest("error", 0, 20*time.Second)
+
+	var buf bytes.Buffer
+	m.write(&buf)
+	output := buf.String()
+
+	for _, line := range []string{
+		"http_client_request_duration_seconds_bucket{le=\"0.01\"} 0\n",
+		"http_client_request_duration_seconds_bucket{le=\"0.025\"} 1\n",
+		"http_client_request_duration_seconds_bucket{le=\"0.5\"} 2\n",
+		"http_client_request_duration_seconds_bucket{le=\"10\"} 2\n",
+		"http_client_request_duration_seconds_bucket{le=\"+Inf\"} 3\n",
+		"http_client_requests_total{code=\"error\"} 1\n",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected %q in metrics:\n%s", line, output)
+		}
+	}
+}
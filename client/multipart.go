@@ -0,0 +1,299 @@
+package client
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// gzipPartSuffix marks a file form field whose content is gzip-compressed
+const gzipPartSuffix = ";gzip"
+
+// formPart is one -f field, checked before anything is streamed
+type formPart struct {
+	key      string
+	value    string // text field value
+	path     string // file to send, "-" for stdin; empty for text fields
+	filename string // name the file part is sent as
+	size     int64  // size of the file at path
+	gzip     bool   // compress the file part
+	stdin    bool   // text field whose value is read from stdin
+}
+
+// parseFormParts parses -f fields, checking that every file exists so a
+// bad path fails before the request is started
+func parseFormParts(forms []string) ([]formPart, error) {
+	parts := make([]formPart, 0, len(forms))
+	for _, form := range forms {
+		key, value, found := strings.Cut(form, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid form data format: %s", form)
+		}
+
+		part := formPart{key: key}
+		switch {
+		case strings.HasPrefix(value, "@"):
+			part.path, part.gzip = strings.CutSuffix(value[1:], gzipPartSuffix)
+			part.filename = filepath.Base(part.path)
+			if part.path == "-" {
+				part.filename = "stdin"
+				break
+			}
+			info, err := os.Stat(part.path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file %s: %w", part.path, err)
+			}
+			part.size = info.Size()
+		case value == "-":
+			part.stdin = true
+		default:
+			part.value = value
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// fromStdin reports whether the part reads stdin, which can only be done once
+func (p formPart) fromStdin() bool {
+	return p.stdin || p.path == "-"
+}
+
+// multipartBody streams an encoded form through a pipe, so files are read
+// from disk as the request is sent instead of being held in memory. The
+// writer only starts on the first Read, so a request that is never sent
+// (--dry-run, a failed dial) doesn't leave it blocked with files open.
+type multipartBody struct {
+	reader   *io.PipeReader
+	start    sync.Once
+	parts    []formPart
+	boundary string
+	size     int64 // -1 when a part can't be sized up front
+}
+
+func newMultipartBody(parts []formPart, boundary string) *multipartBody {
+	return &multipartBody{
+		parts:    parts,
+		boundary: boundary,
+		size:     multipartLength(parts, boundary),
+	}
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.start.Do(func() {
+		reader, writer := io.Pipe()
+		go func() {
+			writer.CloseWithError(writeFormParts(writer, b.parts, b.boundary))
+		}()
+		b.reader = reader
+	})
+	return b.reader.Read(p)
+}
+
+// Close stops the writer, or keeps it from starting when nothing was read
+func (b *multipartBody) Close() error {
+	b.start.Do(func() {
+		b.reader, _ = io.Pipe()
+	})
+	return b.reader.Close()
+}
+
+// canReopen reports whether the body can be built again, e.g. to follow a
+// 307 redirect; not when it reads stdin
+func (b *multipartBody) canReopen() bool {
+	for _, part := range b.parts {
+		if part.fromStdin() {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *multipartBody) reopen() (io.ReadCloser, error) {
+	return newMultipartBody(b.parts, b.boundary), nil
+}
+
+// writeFormParts encodes parts to w, reading files as it goes
+func writeFormParts(w io.Writer, parts []formPart, boundary string) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return fmt.Errorf("failed to set multipart boundary: %w", err)
+	}
+
+	for _, part := range parts {
+		var err error
+		switch {
+		case part.path != "":
+			err = writeFilePart(writer, part)
+		case part.stdin:
+			var field io.Writer
+			if field, err = writer.CreateFormField(part.key); err == nil {
+				if _, err = io.Copy(field, os.Stdin); err != nil {
+					err = fmt.Errorf("failed to read from stdin: %w", err)
+				}
+			}
+		default:
+			if err = writer.WriteField(part.key, part.value); err != nil {
+				err = fmt.Errorf("failed to write form field: %w", err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	return nil
+}
+
+func writeFilePart(writer *multipart.Writer, part formPart) error {
+	var src io.Reader = os.Stdin
+	if part.path != "-" {
+		file, err := os.Open(part.path)
+		if err != nil {
+			return fmt.Errorf("failed to open file %s: %w", part.path, err)
+		}
+		defer file.Close()
+		src = file
+	}
+
+	if part.gzip {
+		return writeGzipFormFile(writer, part.key, part.filename, src)
+	}
+
+	dst, err := writer.CreateFormFile(part.key, part.filename)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to copy file content: %w", err)
+	}
+	return nil
+}
+
+// multipartLength works out the encoded size of parts without reading any
+// file: the form is encoded with empty files and their sizes are added.
+// It's -1 when a part's size isn't known up front (stdin or gzip).
+func multipartLength(parts []formPart, boundary string) int64 {
+	var counter byteCounter
+	writer := multipart.NewWriter(&counter)
+	writer.SetBoundary(boundary)
+
+	var files int64
+	for _, part := range parts {
+		switch {
+		case part.fromStdin() || part.gzip:
+			return -1
+		case part.path != "":
+			writer.CreateFormFile(part.key, part.filename)
+			files += part.size
+		default:
+			writer.WriteField(part.key, part.value)
+		}
+	}
+	writer.Close()
+	return counter.n + files
+}
+
+// byteCounter counts the bytes written to it
+type byteCounter struct {
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// countStdinForms counts form fields read from stdin: "key=-" for a text
+// field or "key=@-" for a file part
+func countStdinForms(forms []string) int {
+	count := 0
+	for _, form := range forms {
+		_, value, _ := strings.Cut(form, "=")
+		value = strings.TrimSuffix(value, gzipPartSuffix)
+		if value == "-" || value == "@-" {
+			count++
+		}
+	}
+	return count
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeGzipFormFile adds a file part like multipart.Writer.CreateFormFile,
+// compressing the content and tagging the part with Content-Encoding: gzip
+func writeGzipFormFile(writer *multipart.Writer, key, filename string, src io.Reader) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(key), quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Encoding", "gzip")
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+
+	gz := gzip.NewWriter(part)
+	if _, err := io.Copy(gz, src); err != nil {
+		return fmt.Errorf("failed to compress file content: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress file content: %w", err)
+	}
+
+	return nil
+}
+
+// checkUploadSize stats every file part before anything is streamed and
+// refuses the upload when together they exceed max, listing the files from
+// largest to smallest. Parts read from stdin can't be sized up front and
+// aren't counted.
+func checkUploadSize(forms []string, max int64) error {
+	if max <= 0 {
+		return nil
+	}
+
+	type upload struct {
+		name string
+		size int64
+	}
+
+	var uploads []upload
+	var total int64
+	for _, form := range forms {
+		_, value, _ := strings.Cut(form, "=")
+		filename, ok := strings.CutPrefix(strings.TrimSuffix(value, gzipPartSuffix), "@")
+		if !ok || filename == "-" {
+			continue
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			return fmt.Errorf("failed to stat file %s: %w", filename, err)
+		}
+		uploads = append(uploads, upload{filename, info.Size()})
+		total += info.Size()
+	}
+
+	if total <= max {
+		return nil
+	}
+
+	sort.SliceStable(uploads, func(i, j int) bool { return uploads[i].size > uploads[j].size })
+	files := make([]string, len(uploads))
+	for i, u := range uploads {
+		files[i] = fmt.Sprintf("%s (%d bytes)", u.name, u.size)
+	}
+	return fmt.Errorf("file parts total %d bytes, exceeding --max-upload-size of %d bytes: %s", total, max, strings.Join(files, ", "))
+}
@@ -0,0 +1,293 @@
+package client
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGzipFormPart(t *testing.T) {
+	content := strings.Repeat("compress me ", 100)
+	path := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	body, contentType, err := buildFormData([]string{"plain=@" + path, "log=@" + path + ";gzip"}, 0)
+	if err != nil {
+		t.Fatalf("Failed to build form data: %v", err)
+	}
+
+	_, params, _ := mime.ParseMediaType(contentType)
+	reader := multipart.NewReader(body, params["boundary"])
+
+	plain, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("Failed to read plain part: %v", err)
+	}
+	if encoding := plain.Header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Expected unmarked part to be sent as is, got Content-Encoding %q", encoding)
+	}
+
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("Failed to read gzip part: %v", err)
+	}
+	if part.FormName() != "log" || part.FileName() != "log.txt" {
+		t.Errorf("Expected part log with file name log.txt, got %q and %q", part.FormName(), part.FileName())
+	}
+	if encoding := part.Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Errorf("Expected Content-Encoding gzip, got %q", encoding)
+	}
+
+	compressed, _ := io.ReadAll(part)
+	if len(compressed) >= len(content) {
+		t.Errorf("Expected compressed part to be smaller than %d bytes, got %d", len(content), len(compressed))
+	}
+
+	gz, err := gzip.NewReader(strings.NewReader(string(compressed)))
+	if err != nil {
+		t.Fatalf("Part is not gzip-encoded: %v", err)
+	}
+	decompressed, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to decompress part: %v", err)
+	}
+	if string(decompressed) != content {
+		t.Error("Expected part to decompress to the original file content")
+	}
+}
+
+// setStdin replaces os.Stdin with a pipe holding content
+func setStdin(t *testing.T, content string) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	go func() {
+		writer.Write([]byte(content))
+		writer.Close()
+	}()
+
+	stdin := os.Stdin
+	os.Stdin = reader
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		reader.Close()
+	})
+}
+
+func TestStdinFormPart(t *testing.T) {
+	tests := []struct {
+		name     string
+		form     string
+		filename string
+	}{
+		{"Text field", "notes=-", ""},
+		{"File part", "upload=@-", "stdin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setStdin(t, "piped\ncontent")
+
+			var parts []receivedPart
+			server := newMultipartServer(t, &parts)
+
+			r, _, _ := newTestRequester(t, Config{
+				Method: "POST",
+				URL:    server.URL,
+				Form:   []string{"title=report", tt.form},
+			})
+			if err := r.execute(); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			name, _, _ := strings.Cut(tt.form, "=")
+			expected := []receivedPart{
+				{"title", "", "report"},
+				{name, tt.filename, "piped\ncontent"},
+			}
+			if len(parts) != len(expected) {
+				t.Fatalf("Expected %d parts, got %d: %+v", len(expected), len(parts), parts)
+			}
+			for i, part := range parts {
+				if part != expected[i] {
+					t.Errorf("Part %d: expected %+v, got %+v", i, expected[i], part)
+				}
+			}
+		})
+	}
+}
+
+func TestMultipleStdinFormParts(t *testing.T) {
+	_, _, err := buildFormData([]string{"a=-", "b=@-"}, 0)
+	if err == nil {
+		t.Fatal("Expected more than one stdin part to be rejected")
+	}
+	if !strings.Contains(err.Error(), "only one form field can be read from stdin") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMaxUploadSize(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.bin")
+	large := filepath.Join(dir, "large.bin")
+	os.WriteFile(small, make([]byte, 300), 0644)
+	os.WriteFile(large, make([]byte, 800), 0644)
+
+	forms := []string{"note=hello", "a=@" + small, "b=@" + large + ";gzip"}
+
+	tests := []struct {
+		name        string
+		max         int64
+		expectError bool
+	}{
+		{"No limit", 0, false},
+		{"Under the limit", 2000, false},
+		{"Exactly the limit", 1100, false},
+		{"Over the limit", 1000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := buildFormData(forms, tt.max)
+			if !tt.expectError {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected the upload to be refused")
+			}
+			expected := "file parts total 1100 bytes, exceeding --max-upload-size of 1000 bytes: " +
+				large + " (800 bytes), " + small + " (300 bytes)"
+			if err.Error() != expected {
+				t.Errorf("Expected %q, got %q", expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestMultipartBodyLength(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.csv")
+	os.WriteFile(report, []byte(strings.Repeat("a,b,c\n", 1000)), 0644)
+	empty := filepath.Join(dir, "empty.txt")
+	os.WriteFile(empty, nil, 0644)
+
+	tests := []struct {
+		name  string
+		forms []string
+		sized bool
+	}{
+		{"Files and fields", []string{"title=Q3 \"final\"", "report=@" + report, "empty=@" + empty}, true},
+		{"Fields only", []string{"a=1", "b=2"}, true},
+		{"Gzip part", []string{"report=@" + report + ";gzip"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _, err := buildFormData(tt.forms, 0)
+			if err != nil {
+				t.Fatalf("Failed to build form data: %v", err)
+			}
+			size := body.(*multipartBody).size
+
+			encoded, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if !tt.sized {
+				if size != -1 {
+					t.Errorf("Expected unknown length, got %d", size)
+				}
+				return
+			}
+			if size != int64(len(encoded)) {
+				t.Errorf("Expected length %d, computed %d", len(encoded), size)
+			}
+		})
+	}
+}
+
+func TestMultipartBodyMissingFile(t *testing.T) {
+	_, _, err := buildFormData([]string{"upload=@" + filepath.Join(t.TempDir(), "missing.bin")}, 0)
+	if err == nil || !strings.Contains(err.Error(), "failed to open file") {
+		t.Errorf("Expected a missing file to fail before streaming, got %v", err)
+	}
+}
+
+func TestMultipartBodyStartsOnRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, []byte("payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bodies of requests that are never sent don't start a writer
+	before := runtime.NumGoroutine()
+	for i := 0; i < 5; i++ {
+		if _, _, err := buildFormData([]string{"upload=@" + path}, 0); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if after := runtime.NumGoroutine(); after != before {
+		t.Errorf("Expected no writers before the bodies are read, got %d more goroutines", after-before)
+	}
+
+	body, _, _ := buildFormData([]string{"upload=@" + path}, 0)
+	body.(io.Closer).Close()
+	if _, err := body.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Errorf("Expected a closed body to stay closed, got %v", err)
+	}
+}
+
+func TestMultipartUploadIsSentWithLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(path, []byte(strings.Repeat("x", 1<<20)), 0644)
+
+	var contentLength int64
+	var transferEncoding []string
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A 307 makes the client send the body a second time
+		if r.URL.Path == "/old" {
+			io.Copy(io.Discard, r.Body)
+			http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+			return
+		}
+		contentLength, transferEncoding = r.ContentLength, r.TransferEncoding
+		r.ParseMultipartForm(1 << 10)
+		if file, _, err := r.FormFile("upload"); err == nil {
+			data, _ := io.ReadAll(file)
+			received = len(data)
+		}
+	}))
+	defer server.Close()
+
+	r, stdout, _ := newTestRequester(t, Config{Method: "POST", URL: server.URL + "/old", Form: []string{"upload=@" + path}})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if !strings.HasPrefix(stdout.String(), "HTTP/1.1 200 OK") {
+		t.Errorf("Expected the redirect to be followed, got %q", stdout.String())
+	}
+	if contentLength <= 1<<20 || len(transferEncoding) != 0 {
+		t.Errorf("Expected a Content-Length, got %d and %v", contentLength, transferEncoding)
+	}
+	if received != 1<<20 {
+		t.Errorf("Expected %d bytes of file content, got %d", 1<<20, received)
+	}
+}
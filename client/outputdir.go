@@ -0,0 +1,91 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxOutputSuffix bounds the search for a free file name in --output-dir
+const maxOutputSuffix = 10000
+
+func (r *requester) createOutput(resp *http.Response) (*os.File, error) {
+	if r.config.OutputDir == "" {
+		name := r.config.Output
+		if r.config.GzipOutput {
+			name = gzipOutputName(name)
+		}
+		return os.Create(name)
+	}
+
+	if err := os.MkdirAll(r.config.OutputDir, 0755); err != nil {
+		return nil, err
+	}
+	name, ok := dispositionFileName(resp.Header)
+	if !ok {
+		name = outputFileName(resp.Request.URL)
+	}
+	if r.config.GzipOutput {
+		name = gzipOutputName(name)
+	}
+	return createUnique(r.config.OutputDir, name)
+}
+
+// outputFileName derives a file name from the last segment of the URL path,
+// falling back to index.html for directory-like URLs
+func outputFileName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." || name == ".." || strings.HasSuffix(u.Path, "/") {
+		return "index.html"
+	}
+	return name
+}
+
+// dispositionFileName returns the file name a Content-Disposition header
+// suggests (filename* is preferred when both are given). Only the base name
+// is used, so a server can't write outside the output directory.
+func dispositionFileName(h http.Header) (string, bool) {
+	value := h.Get("Content-Disposition")
+	if value == "" {
+		return "", false
+	}
+	_, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return "", false
+	}
+
+	name := filepath.Base(filepath.FromSlash(strings.ReplaceAll(params["filename"], "\\", "/")))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return "", false
+	}
+	return name, true
+}
+
+// createUnique creates name in dir, adding a numeric suffix before the
+// extension ("report-1.json", "report-2.json", ...) when it already exists
+func createUnique(dir, name string) (*os.File, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+
+	for i := 0; i < maxOutputSuffix; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s-%d%s", stem, i, ext)
+		}
+
+		file, err := os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return file, err
+	}
+
+	return nil, fmt.Errorf("no free file name for %s in %s", name, dir)
+}
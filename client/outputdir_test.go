@@ -0,0 +1,147 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputDirNamesFilesFromURL(t *testing.T) {
+	var requests int32
+	server := newLinkedPagesServer(t, &requests)
+	dir := filepath.Join(t.TempDir(), "downloads")
+
+	r, _, _ := newTestRequester(t, Config{
+		URL:       server.URL + "/items",
+		Paginate:  true,
+		OutputDir: dir,
+	})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"items":   "[1,2]",
+		"items-1": "[3,4]",
+		"items-2": "[5]",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Expected file %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Expected %s to contain %s, got %s", name, content, data)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(expected) {
+		t.Errorf("Expected %d files, got %d", len(expected), len(entries))
+	}
+}
+
+func TestCreateUniqueKeepsExtension(t *testing.T) {
+	dir := t.TempDir()
+	for _, want := range []string{"report.json", "report-1.json", "report-2.json"} {
+		file, err := createUnique(dir, "report.json")
+		if err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		file.Close()
+		if got := filepath.Base(file.Name()); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestOutputFileName(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com/files/report.pdf", "report.pdf"},
+		{"https://example.com/files/report.pdf?version=2", "report.pdf"},
+		{"https://example.com/files/", "index.html"},
+		{"https://example.com", "index.html"},
+		{"https://example.com/a/..", "index.html"},
+		{"https://example.com/a%20b.txt", "a b.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			if got := outputFileName(u); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestOutputFlagsConflict(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"-o", "out.bin", "-O", "http://example.com/file"}, &stdout, &stderr)
+	if code != exitUsage {
+		t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+	}
+	if !bytes.Contains(stderr.Bytes(), []byte("cannot be combined")) {
+		t.Errorf("Expected conflict message, got: %q", stderr.String())
+	}
+}
+
+func TestDispositionFileName(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+		ok       bool
+	}{
+		{`attachment; filename="report.csv"`, "report.csv", true},
+		{`attachment; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`, "résumé.pdf", true},
+		{`attachment; filename="../../etc/passwd"`, "passwd", true},
+		{`attachment; filename="..\\windows\\evil.exe"`, "evil.exe", true},
+		{`attachment; filename=".."`, "", false},
+		{`inline`, "", false},
+		{``, "", false},
+		{`attachment; filename="unterminated`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			h := http.Header{}
+			if tt.header != "" {
+				h.Set("Content-Disposition", tt.header)
+			}
+			name, ok := dispositionFileName(h)
+			if name != tt.expected || ok != tt.ok {
+				t.Errorf("Expected (%q, %t), got (%q, %t)", tt.expected, tt.ok, name, ok)
+			}
+		})
+	}
+}
+
+func TestOutputDirUsesContentDisposition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="export.csv"`)
+		w.Write([]byte("a,b\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	r, _, _ := newTestRequester(t, Config{URL: server.URL + "/download?id=1", OutputDir: dir})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "export.csv"))
+	if err != nil {
+		t.Fatalf("Expected file named after Content-Disposition: %v", err)
+	}
+	if string(data) != "a,b\n" {
+		t.Errorf("Expected body to be saved, got %q", data)
+	}
+}
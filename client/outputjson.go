@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// responseJSON is the --output-format json document. The body is a string
+// when it is UTF-8 text and base64 otherwise, as body_encoding says.
+type responseJSON struct {
+	URL string `json:"url"`
+	headersJSON
+	Timing       timingsJSON `json:"timing"`
+	Body         string      `json:"body"`
+	BodyEncoding string      `json:"body_encoding"`
+}
+
+// printResponseJSON writes the response as a single JSON document, indented
+// when --pretty applies
+func (r *requester) printResponseJSON(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	doc := responseJSON{
+		URL:          resp.Request.URL.String(),
+		headersJSON:  newHeadersJSON(resp),
+		Body:         string(body),
+		BodyEncoding: "text",
+	}
+	if r.config.BodyBase64 || !utf8.Valid(body) {
+		doc.Body = base64.StdEncoding.EncodeToString(body)
+		doc.BodyEncoding = "base64"
+	}
+	if timer := requestTimer(resp.Request); timer != nil {
+		doc.Timing = timer.timings(time.Now()).json()
+	}
+
+	encoder := json.NewEncoder(r.stdout)
+	encoder.SetEscapeHTML(false)
+	if r.config.PrettyPrint {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOutputFormatJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Tag", "a")
+		w.Header().Add("X-Tag", "b")
+		if r.URL.Path == "/binary" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte{0xff, 0x00, 0xfe})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1, "html": "<b>"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		status   int
+		body     string
+		encoding string
+	}{
+		{"Text body", []string{server.URL + "/users"}, 201, `{"id": 1, "html": "<b>"}`, "text"},
+		{"Binary body", []string{server.URL + "/binary"}, 200, base64.StdEncoding.EncodeToString([]byte{0xff, 0x00, 0xfe}), "base64"},
+		{"Forced base64", []string{"--body-base64", server.URL + "/users"}, 201, base64.StdEncoding.EncodeToString([]byte(`{"id": 1, "html": "<b>"}`)), "base64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(append([]string{"--output-format", "json"}, tt.args...), &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+			}
+			if strings.Count(stdout.String(), "\n") != 1 {
+				t.Errorf("Expected a single JSON line, got %q", stdout.String())
+			}
+
+			var doc struct {
+				URL          string         `json:"url"`
+				Proto        string         `json:"proto"`
+				Status       int            `json:"status"`
+				Reason       string         `json:"reason"`
+				Headers      map[string]any `json:"headers"`
+				Timing       map[string]any `json:"timing"`
+				Body         string         `json:"body"`
+				BodyEncoding string         `json:"body_encoding"`
+			}
+			if err := json.Unmarshal([]byte(stdout.String()), &doc); err != nil {
+				t.Fatalf("Expected a JSON document, got %q: %v", stdout.String(), err)
+			}
+			if doc.URL != tt.args[len(tt.args)-1] || doc.Proto != "HTTP/1.1" || doc.Status != tt.status {
+				t.Errorf("Unexpected url, proto or status: %+v", doc)
+			}
+			if doc.Body != tt.body || doc.BodyEncoding != tt.encoding {
+				t.Errorf("Expected body %q (%s), got %q (%s)", tt.body, tt.encoding, doc.Body, doc.BodyEncoding)
+			}
+			if tags, ok := doc.Headers["X-Tag"].([]any); !ok || len(tags) != 2 {
+				t.Errorf("Expected repeated header as an array, got %v", doc.Headers["X-Tag"])
+			}
+			if total, ok := doc.Timing["total_ms"].(float64); !ok || total <= 0 {
+				t.Errorf("Expected a positive total_ms, got %v", doc.Timing["total_ms"])
+			}
+		})
+	}
+
+	for _, args := range [][]string{
+		{"--output-format", "xml", server.URL},
+		{"--output-format", "json", "-o", "out.json", server.URL},
+	} {
+		var stdout, stderr strings.Builder
+		if code := Run(args, &stdout, &stderr); code != exitUsage {
+			t.Errorf("Expected exit code %d for %v, got %d", exitUsage, args, code)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// paginate fetches the initial URL and keeps following next pages until
+// none is left or --max-pages is reached. The next page comes from the Link
+// header, a JSON cursor field (--next-jsonpath) or an incremented page number
+// (--page-param). Pages are printed as they arrive, collected and printed as
+// one JSON array with --paginate-merge, or streamed one item per line with
+// --paginate-ndjson.
+func (r *requester) paginate() error {
+	var pages [][]byte
+	var last *http.Response
+
+	pageURL := r.config.URL
+	seen := make(map[string]bool)
+
+	for page := 1; ; page++ {
+		seen[pageURL] = true
+
+		var next string
+		err := r.do(pageURL, page == 1, func(resp *http.Response) error {
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return fmt.Errorf("failed to read page %d: %w", page, err)
+			}
+
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				next, err = r.nextPage(pageURL, resp, body)
+				if err != nil {
+					return fmt.Errorf("page %d: %w", page, err)
+				}
+			}
+
+			switch {
+			case r.config.PaginateMerge:
+				pages = append(pages, body)
+				last = resp
+				return nil
+			case r.config.PaginateNDJSON:
+				items, err := pageItems(body, r.config.PaginateItems)
+				if err != nil {
+					return fmt.Errorf("page %d: %w", page, err)
+				}
+				return writeNDJSON(r.stdout, items)
+			}
+
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return r.printResponse(resp)
+		})
+		if err != nil {
+			return err
+		}
+
+		if next == "" || (r.config.MaxPages > 0 && page >= r.config.MaxPages) {
+			break
+		}
+
+		if seen[next] {
+			break
+		}
+		pageURL = next
+	}
+
+	if !r.config.PaginateMerge {
+		return nil
+	}
+
+	merged, err := mergeJSONArrays(pages, r.config.PaginateItems)
+	if err != nil {
+		return err
+	}
+
+	last.Body = io.NopCloser(bytes.NewReader(merged))
+	if err := r.printHeaders(last); err != nil {
+		return err
+	}
+	last.Header.Set("Content-Type", "application/json")
+
+	formattedBody, err := r.formatBody(last)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(r.stdout, string(formattedBody))
+	return nil
+}
+
+// nextPage returns the absolute URL of the page after pageURL, or "" when
+// there are no more pages. It builds on the URL the page was actually
+// fetched from, so the query parameters of the first request (-q) carry
+// over to every page.
+func (r *requester) nextPage(pageURL string, resp *http.Response, body []byte) (string, error) {
+	if resp.Request != nil && resp.Request.URL != nil {
+		pageURL = resp.Request.URL.String()
+	}
+
+	if r.config.PageParam != "" {
+		items, err := pageItems(body, r.config.PaginateItems)
+		if err != nil {
+			return "", err
+		}
+		if len(items) == 0 {
+			return "", nil
+		}
+		return nextPageNumber(pageURL, r.config.PageParam)
+	}
+
+	next, err := nextPageURL(resp, body, r.config.PaginateField)
+	if err != nil || next == "" {
+		return "", err
+	}
+
+	if r.config.CursorParam != "" {
+		return setQueryParam(pageURL, r.config.CursorParam, next)
+	}
+
+	nextURL, err := resolveReference(pageURL, next)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page URL %q: %w", next, err)
+	}
+	return nextURL, nil
+}
+
+// nextPageURL returns the next page reference from the JSON field when one
+// is configured, or from the Link header otherwise. An empty result means
+// there are no more pages.
+func nextPageURL(resp *http.Response, body []byte, field string) (string, error) {
+	if field == "" {
+		return linkNext(resp.Header.Values("Link")), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return "", fmt.Errorf("response is not JSON, cannot read %q: %w", field, err)
+	}
+
+	value, ok := lookupJSONPath(doc, field)
+	if !ok || value == nil {
+		return "", nil
+	}
+
+	switch next := value.(type) {
+	case string:
+		return next, nil
+	case json.Number:
+		// Numeric cursors such as {"next_id": 1042}
+		return next.String(), nil
+	}
+	return "", fmt.Errorf("field %q is not a string", field)
+}
+
+// nextPageNumber returns pageURL with the page number in param incremented.
+// A URL without the parameter is taken to be page 1.
+func nextPageNumber(pageURL, param string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	number := 1
+	if value := u.Query().Get(param); value != "" {
+		number, err = strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("page number %s=%q is not an integer", param, value)
+		}
+	}
+	return setQueryParam(pageURL, param, strconv.Itoa(number+1))
+}
+
+// setQueryParam returns rawURL with the query parameter name set to value
+func setQueryParam(rawURL, name, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(name, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// pageItems returns the items of a page: the page itself when it is a JSON
+// array, or the array at the dotted path given with --paginate-items
+func pageItems(body []byte, path string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	if path == "" {
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("page is not a JSON array: %w", err)
+		}
+		return items, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("page is not JSON, cannot read %q: %w", path, err)
+	}
+
+	value, ok := lookupJSONPath(doc, path)
+	list, isList := value.([]any)
+	if !ok || !isList {
+		return nil, fmt.Errorf("page has no JSON array at %q", path)
+	}
+	for _, item := range list {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, raw)
+	}
+	return items, nil
+}
+
+// writeNDJSON writes each item compacted onto its own line
+func writeNDJSON(w io.Writer, items []json.RawMessage) error {
+	var line bytes.Buffer
+	for _, item := range items {
+		line.Reset()
+		if err := json.Compact(&line, item); err != nil {
+			return err
+		}
+		line.WriteByte('\n')
+		if _, err := w.Write(line.Bytes()); err != nil {
+			return fmt.Errorf("failed to write item: %w", err)
+		}
+	}
+	return nil
+}
+
+// linkNext extracts the rel="next" target from RFC 8288 Link header values
+func linkNext(values []string) string {
+	for _, value := range values {
+		for len(value) > 0 {
+			start := strings.IndexByte(value, '<')
+			end := strings.IndexByte(value, '>')
+			if start < 0 || end < start {
+				break
+			}
+
+			target := value[start+1 : end]
+			value = value[end+1:]
+
+			params := value
+			if comma := strings.IndexByte(value, ','); comma >= 0 {
+				params = value[:comma]
+				value = value[comma+1:]
+			} else {
+				value = ""
+			}
+
+			for _, param := range strings.Split(params, ";") {
+				key, val, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// lookupJSONPath walks a dotted path like "links.next" or "items[0].id"
+// through decoded JSON. A leading "$." as in JSONPath is accepted.
+func lookupJSONPath(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		key, indexes, _ := strings.Cut(segment, "[")
+		if key != "" {
+			obj, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			current, ok = obj[key]
+			if !ok {
+				return nil, false
+			}
+		}
+		if indexes == "" {
+			continue
+		}
+
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			i, err := strconv.Atoi(index)
+			list, ok := current.([]any)
+			if err != nil || !ok || i < 0 || i >= len(list) {
+				return nil, false
+			}
+			current = list[i]
+		}
+	}
+	return current, true
+}
+
+func resolveReference(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+// mergeJSONArrays concatenates the items of every page into one array
+func mergeJSONArrays(pages [][]byte, itemsPath string) ([]byte, error) {
+	merged := []json.RawMessage{}
+
+	for i, page := range pages {
+		items, err := pageItems(page, itemsPath)
+		if err != nil {
+			return nil, fmt.Errorf("page %d cannot be merged: %w", i+1, err)
+		}
+		merged = append(merged, items...)
+	}
+
+	return json.Marshal(merged)
+}
@@ -0,0 +1,390 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newLinkedPagesServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch page {
+		case "1":
+			w.Header().Set("Link", `</items?page=2>; rel="next", </items?page=3>; rel="last"`)
+			fmt.Fprint(w, `[1,2]`)
+		case "2":
+			w.Header().Set("Link", `<`+server.URL+`/items?page=1>; rel="prev", <`+server.URL+`/items?page=3>; rel="next"`)
+			fmt.Fprint(w, `[3,4]`)
+		case "3":
+			fmt.Fprint(w, `[5]`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPaginateLinkHeaderMerge(t *testing.T) {
+	var requests int32
+	server := newLinkedPagesServer(t, &requests)
+
+	r, stdout, _ := newTestRequester(t, Config{
+		URL:           server.URL + "/items",
+		Paginate:      true,
+		PaginateMerge: true,
+	})
+
+	if err := r.paginate(); err != nil {
+		t.Fatalf("Pagination failed: %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 pages to be fetched, got %d", requests)
+	}
+	if !strings.HasSuffix(stdout.String(), "\n\n[1,2,3,4,5]") {
+		t.Errorf("Expected merged array, got:\n%s", stdout.String())
+	}
+}
+
+func TestPaginateConcatenatesPages(t *testing.T) {
+	var requests int32
+	server := newLinkedPagesServer(t, &requests)
+
+	r, stdout, _ := newTestRequester(t, Config{URL: server.URL + "/items", Paginate: true})
+
+	if err := r.paginate(); err != nil {
+		t.Fatalf("Pagination failed: %v", err)
+	}
+
+	output := stdout.String()
+	if strings.Count(output, "200 OK") != 3 {
+		t.Errorf("Expected 3 responses to be printed, got:\n%s", output)
+	}
+	for _, body := range []string{"[1,2]", "[3,4]", "[5]"} {
+		if !strings.Contains(output, body) {
+			t.Errorf("Expected output to contain page %s", body)
+		}
+	}
+}
+
+func TestPaginateMaxPages(t *testing.T) {
+	var requests int32
+	server := newLinkedPagesServer(t, &requests)
+
+	r, stdout, _ := newTestRequester(t, Config{
+		URL:           server.URL + "/items",
+		Paginate:      true,
+		PaginateMerge: true,
+		MaxPages:      2,
+	})
+
+	if err := r.paginate(); err != nil {
+		t.Fatalf("Pagination failed: %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 pages to be fetched, got %d", requests)
+	}
+	if !strings.HasSuffix(stdout.String(), "[1,2,3,4]") {
+		t.Errorf("Expected first two pages merged, got:\n%s", stdout.String())
+	}
+}
+
+func TestPaginateRespectsRateLimit(t *testing.T) {
+	var requests int32
+	server := newLinkedPagesServer(t, &requests)
+
+	r, _, _ := newTestRequester(t, Config{
+		URL:       server.URL + "/items",
+		Paginate:  true,
+		RateLimit: "1/100ms",
+	})
+
+	start := time.Now()
+	if err := r.paginate(); err != nil {
+		t.Fatalf("Pagination failed: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected pages to be paced by the rate limiter, took %v", elapsed)
+	}
+}
+
+func TestPaginateJSONField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"items":[1],"links":{"next":"?cursor=b"}}`)
+		case "b":
+			fmt.Fprint(w, `{"items":[2],"links":{"next":"?cursor=c"}}`)
+		case "c":
+			fmt.Fprint(w, `{"items":[3],"links":{"next":null}}`)
+		}
+	}))
+	defer server.Close()
+
+	r, stdout, _ := newTestRequester(t, Config{
+		URL:           server.URL,
+		Paginate:      true,
+		PaginateField: "links.next",
+	})
+
+	if err := r.paginate(); err != nil {
+		t.Fatalf("Pagination failed: %v", err)
+	}
+
+	for _, item := range []string{`"items":[1]`, `"items":[2]`, `"items":[3]`} {
+		if !strings.Contains(stdout.String(), item) {
+			t.Errorf("Expected output to contain %s", item)
+		}
+	}
+}
+
+func TestPaginateCursorParam(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"id":1},{"id":2}],"next":"b"}`)
+		case "b":
+			fmt.Fprint(w, `{"data":[{"id":3}],"next":1042}`)
+		case "1042":
+			fmt.Fprint(w, `{"data":[],"next":null}`)
+		}
+	}))
+	defer server.Close()
+
+	r, stdout, _ := newTestRequester(t, Config{
+		URL:            server.URL + "?limit=2",
+		Paginate:       true,
+		PaginateField:  "$.next",
+		CursorParam:    "after",
+		PaginateItems:  "data",
+		PaginateNDJSON: true,
+	})
+
+	if err := r.paginate(); err != nil {
+		t.Fatalf("Pagination failed: %v", err)
+	}
+
+	expected := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestPaginatePageParam(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "", "1":
+			fmt.Fprint(w, `[1,2]`)
+		case "2":
+			fmt.Fprint(w, `[3]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   Config
+		requests int32
+		expected string
+	}{
+		{"Merged", Config{PageParam: "page", PaginateMerge: true}, 3, "[1,2,3]"},
+		{"NDJSON", Config{PageParam: "page", PaginateNDJSON: true}, 3, "1\n2\n3\n"},
+		{"Max pages", Config{PageParam: "page", PaginateNDJSON: true, MaxPages: 1}, 1, "1\n2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			tt.config.URL = server.URL + "/items"
+			tt.config.Paginate = true
+			r, stdout, _ := newTestRequester(t, tt.config)
+
+			if err := r.paginate(); err != nil {
+				t.Fatalf("Pagination failed: %v", err)
+			}
+			if requests != tt.requests {
+				t.Errorf("Expected %d pages to be fetched, got %d", tt.requests, requests)
+			}
+			if !strings.HasSuffix(stdout.String(), tt.expected) {
+				t.Errorf("Expected output to end with %q, got %q", tt.expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestPaginateKeepsQueryParams(t *testing.T) {
+	var missing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sort") != "name" {
+			atomic.AddInt32(&missing, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Query().Get("page") == "2", r.URL.Query().Get("after") == "b":
+			fmt.Fprint(w, `{"data":[3],"next":null}`)
+		case r.URL.Query().Get("page") == "3":
+			fmt.Fprint(w, `{"data":[]}`)
+		default:
+			fmt.Fprint(w, `{"data":[1,2],"next":"b"}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"Page number", Config{PageParam: "page"}},
+		{"Cursor", Config{PaginateField: "$.next", CursorParam: "after"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&missing, 0)
+			tt.config.URL = server.URL + "/items"
+			tt.config.Query = []string{"sort=name"}
+			tt.config.Paginate = true
+			tt.config.PaginateItems = "data"
+			tt.config.PaginateNDJSON = true
+			r, stdout, _ := newTestRequester(t, tt.config)
+
+			if err := r.paginate(); err != nil {
+				t.Fatalf("Pagination failed: %v", err)
+			}
+			if stdout.String() != "1\n2\n3\n" {
+				t.Errorf("Expected all pages, got %q", stdout.String())
+			}
+			if missing != 0 {
+				t.Errorf("Expected every page request to keep sort=name, %d did not", missing)
+			}
+		})
+	}
+}
+
+func TestNextPageNumber(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://a.test/items", "https://a.test/items?page=2"},
+		{"https://a.test/items?page=0&size=10", "https://a.test/items?page=1&size=10"},
+		{"https://a.test/items?page=7", "https://a.test/items?page=8"},
+	}
+
+	for _, tt := range tests {
+		got, err := nextPageNumber(tt.url, "page")
+		if err != nil || got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q (%v)", tt.expected, tt.url, got, err)
+		}
+	}
+
+	if _, err := nextPageNumber("https://a.test/?page=last", "page"); err == nil {
+		t.Error("Expected error for a page number that isn't an integer")
+	}
+}
+
+func TestPaginateFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"Page and cursor", []string{"--paginate", "--page-param", "page", "--next-jsonpath", "$.next"}, "cannot be combined with --next-jsonpath"},
+		{"Cursor param alone", []string{"--paginate", "--cursor-param", "after"}, "--cursor-param requires --next-jsonpath"},
+		{"Merge and NDJSON", []string{"--paginate", "--paginate-merge", "--paginate-ndjson"}, "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(append(tt.args, "http://example.com"), &stdout, &stderr); code != exitUsage {
+				t.Fatalf("Expected exit code %d, got %d", exitUsage, code)
+			}
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("Expected %q in stderr, got %q", tt.want, stderr.String())
+			}
+		})
+	}
+}
+
+func TestLinkNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{"Single next", []string{`<https://a.test/?p=2>; rel="next"`}, "https://a.test/?p=2"},
+		{"Next after prev", []string{`<https://a.test/?p=1>; rel="prev", <https://a.test/?p=3>; rel="next"`}, "https://a.test/?p=3"},
+		{"Unquoted rel", []string{`</p/2>; rel=next`}, "/p/2"},
+		{"Multiple rel values", []string{`</p/2>; rel="next last"`}, "/p/2"},
+		{"Separate headers", []string{`</p/1>; rel="first"`, `</p/2>; rel="next"`}, "/p/2"},
+		{"No next", []string{`</p/1>; rel="prev"`}, ""},
+		{"No header", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkNext(tt.values); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMergeJSONArraysRejectsObjects(t *testing.T) {
+	if _, err := mergeJSONArrays([][]byte{[]byte(`[1]`), []byte(`{"a":1}`)}, ""); err == nil {
+		t.Error("Expected error when a page is not an array")
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	doc := map[string]any{
+		"links": map[string]any{"next": "/page/2"},
+		"items": []any{map[string]any{"id": "a"}, map[string]any{"id": "b", "tags": []any{"x", "y"}}},
+	}
+
+	tests := []struct {
+		path     string
+		expected any
+		found    bool
+	}{
+		{"links.next", "/page/2", true},
+		{"$.links.next", "/page/2", true},
+		{"items[1].id", "b", true},
+		{"items[1].tags[1]", "y", true},
+		{"items[2].id", nil, false},
+		{"items[x]", nil, false},
+		{"links[0]", nil, false},
+		{"links.prev", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, found := lookupJSONPath(doc, tt.path)
+			if found != tt.found || value != tt.expected {
+				t.Errorf("Expected %v (%t), got %v (%t)", tt.expected, tt.found, value, found)
+			}
+		})
+	}
+}
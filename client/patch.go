@@ -0,0 +1,200 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	jsonPatchContentType  = "application/json-patch+json"
+	mergePatchContentType = "application/merge-patch+json"
+)
+
+type PatchList []string
+
+func (p *PatchList) String() string {
+	return strings.Join(*p, ", ")
+}
+
+func (p *PatchList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// patchOperation is a single RFC 6902 JSON Patch operation
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// buildJSONPatch assembles an RFC 6902 document from specs like
+// "op=replace;path=/a/b;value=1"
+func buildJSONPatch(specs []string) ([]byte, error) {
+	ops := make([]patchOperation, 0, len(specs))
+
+	for _, spec := range specs {
+		op, err := parsePatchOperation(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON patch %q: %w", spec, err)
+		}
+		ops = append(ops, op)
+	}
+
+	return json.Marshal(ops)
+}
+
+func parsePatchOperation(spec string) (patchOperation, error) {
+	var op patchOperation
+	var hasValue bool
+
+	for _, field := range strings.Split(spec, ";") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return op, fmt.Errorf("field %q must be in 'key=value' format", field)
+		}
+
+		switch strings.TrimSpace(parts[0]) {
+		case "op":
+			op.Op = parts[1]
+		case "path":
+			op.Path = parts[1]
+		case "from":
+			op.From = parts[1]
+		case "value":
+			op.Value = parseJSONValue(parts[1])
+			hasValue = true
+		default:
+			return op, fmt.Errorf("unknown field %q", parts[0])
+		}
+	}
+
+	if err := validatePointer(op.Path); err != nil {
+		return op, fmt.Errorf("path: %w", err)
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if !hasValue {
+			return op, fmt.Errorf("%s operation requires a value", op.Op)
+		}
+	case "remove":
+		if hasValue {
+			return op, fmt.Errorf("remove operation does not take a value")
+		}
+	case "move", "copy":
+		if op.From == "" {
+			return op, fmt.Errorf("%s operation requires a from path", op.Op)
+		}
+		if err := validatePointer(op.From); err != nil {
+			return op, fmt.Errorf("from: %w", err)
+		}
+	case "":
+		return op, fmt.Errorf("missing op")
+	default:
+		return op, fmt.Errorf("unknown op %q (use add, remove, replace, move, copy or test)", op.Op)
+	}
+
+	return op, nil
+}
+
+// validatePointer checks that path is an RFC 6901 JSON Pointer
+func validatePointer(path string) error {
+	if path == "" {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("JSON pointer %q must start with '/'", path)
+	}
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '~' {
+			continue
+		}
+		if i+1 >= len(path) || (path[i+1] != '0' && path[i+1] != '1') {
+			return fmt.Errorf("JSON pointer %q has invalid escape (use ~0 or ~1)", path)
+		}
+	}
+
+	return nil
+}
+
+// buildMergePatch assembles an RFC 7386 merge patch from pairs like
+// "/a/b=1". A null value removes the member on the server.
+func buildMergePatch(pairs []string) ([]byte, error) {
+	patch := make(map[string]any)
+
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid merge patch %q: must be in 'path=value' format", pair)
+		}
+
+		path := parts[0]
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		if path == "/" {
+			return nil, fmt.Errorf("invalid merge patch %q: empty path", pair)
+		}
+		if err := validatePointer(path); err != nil {
+			return nil, fmt.Errorf("invalid merge patch %q: %w", pair, err)
+		}
+
+		var value any
+		if err := json.Unmarshal(parseJSONValue(parts[1]), &value); err != nil {
+			return nil, fmt.Errorf("invalid merge patch %q: %w", pair, err)
+		}
+
+		if err := setPointer(patch, splitPointer(path), value); err != nil {
+			return nil, fmt.Errorf("invalid merge patch %q: %w", pair, err)
+		}
+	}
+
+	return json.Marshal(patch)
+}
+
+func splitPointer(path string) []string {
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens
+}
+
+func setPointer(obj map[string]any, tokens []string, value any) error {
+	key := tokens[0]
+	if len(tokens) == 1 {
+		if _, isObject := obj[key].(map[string]any); isObject {
+			return fmt.Errorf("%q is already set as an object", key)
+		}
+		obj[key] = value
+		return nil
+	}
+
+	child, exists := obj[key]
+	if !exists {
+		child = make(map[string]any)
+		obj[key] = child
+	}
+
+	childObj, ok := child.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%q is already set to a non-object value", key)
+	}
+
+	return setPointer(childObj, tokens[1:], value)
+}
+
+// parseJSONValue keeps valid JSON literals as-is and encodes anything else
+// as a JSON string
+func parseJSONValue(s string) json.RawMessage {
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	encoded, _ := json.Marshal(s)
+	return encoded
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuildJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		specs    []string
+		expected string
+	}{
+		{
+			"Replace number",
+			[]string{"op=replace;path=/a/b;value=1"},
+			`[{"op":"replace","path":"/a/b","value":1}]`,
+		},
+		{
+			"Add string and remove",
+			[]string{"op=add;path=/name;value=test", "op=remove;path=/old"},
+			`[{"op":"add","path":"/name","value":"test"},{"op":"remove","path":"/old"}]`,
+		},
+		{
+			"Move and copy",
+			[]string{"op=move;from=/a;path=/b", "op=copy;from=/b;path=/c"},
+			`[{"op":"move","path":"/b","from":"/a"},{"op":"copy","path":"/c","from":"/b"}]`,
+		},
+		{
+			"Test with null and object values",
+			[]string{"op=test;path=/x;value=null", `op=add;path=/y;value={"k":[1,2]}`},
+			`[{"op":"test","path":"/x","value":null},{"op":"add","path":"/y","value":{"k":[1,2]}}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := buildJSONPatch(tt.specs)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(patch) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, patch)
+			}
+		})
+	}
+}
+
+func TestBuildJSONPatchValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"Unknown op", "op=delete;path=/a"},
+		{"Missing op", "path=/a;value=1"},
+		{"Relative path", "op=replace;path=a;value=1"},
+		{"Bad escape", "op=replace;path=/a~2;value=1"},
+		{"Missing value", "op=add;path=/a"},
+		{"Remove with value", "op=remove;path=/a;value=1"},
+		{"Move without from", "op=move;path=/a"},
+		{"Unknown field", "op=add;path=/a;value=1;extra=2"},
+		{"Malformed field", "op=add;path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildJSONPatch([]string{tt.spec}); err == nil {
+				t.Errorf("Expected error for spec %q", tt.spec)
+			}
+		})
+	}
+}
+
+func TestBuildMergePatch(t *testing.T) {
+	patch, err := buildMergePatch([]string{"/a/b=1", "a/c=text", "/d=null", "/e~1f=true"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"a":{"b":1,"c":"text"},"d":null,"e/f":true}`
+	if string(patch) != expected {
+		t.Errorf("Expected %s, got %s", expected, patch)
+	}
+
+	if _, err := buildMergePatch([]string{"/a=1", "/a/b=2"}); err == nil {
+		t.Error("Expected error when nesting under a non-object value")
+	}
+	if _, err := buildMergePatch([]string{"novalue"}); err == nil {
+		t.Error("Expected error for missing value")
+	}
+}
+
+func TestPatchContentTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		contentType string
+		body        string
+	}{
+		{
+			"JSON Patch",
+			Config{JSONPatch: []string{"op=replace;path=/a;value=1"}},
+			jsonPatchContentType,
+			`[{"op":"replace","path":"/a","value":1}]`,
+		},
+		{
+			"Merge Patch",
+			Config{MergePatch: []string{"/a=1"}},
+			mergePatchContentType,
+			`{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contentType, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				contentType, body = r.Header.Get("Content-Type"), string(data)
+			}))
+			defer server.Close()
+
+			config := tt.config
+			config.Method = "PATCH"
+			config.URL = server.URL
+			config.Timeout = 5 * time.Second
+
+			r, _, _ := newTestRequester(t, config)
+			if err := r.execute(); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if contentType != tt.contentType {
+				t.Errorf("Expected Content-Type %s, got %s", tt.contentType, contentType)
+			}
+			if body != tt.body {
+				t.Errorf("Expected body %s, got %s", tt.body, body)
+			}
+		})
+	}
+}
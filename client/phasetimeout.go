@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errFirstByteTimeout is the cancellation cause when --first-byte-timeout
+// fires. It wraps DeadlineExceeded so it's reported like other timeouts.
+var errFirstByteTimeout = fmt.Errorf("first byte timeout: %w", context.DeadlineExceeded)
+
+// firstByteTrace cancels the request with errFirstByteTimeout when no
+// response byte arrives within timeout of the request being written. The
+// returned stop function releases the timer.
+func firstByteTrace(timeout time.Duration, cancel context.CancelCauseFunc) (*httptrace.ClientTrace, func()) {
+	var mutex sync.Mutex
+	var timer *time.Timer
+
+	stop := func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+
+	trace := &httptrace.ClientTrace{
+		// Redirects write a new request, so each hop gets its own window
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(timeout, func() { cancel(errFirstByteTimeout) })
+		},
+		GotFirstResponseByte: stop,
+	}
+	return trace, stop
+}
+
+// phaseTimeoutError names the per-phase timeout flag behind a failed
+// request, or returns nil when none of them fired. net/http doesn't export
+// sentinels for its transport timeouts, so those messages are matched.
+func phaseTimeoutError(ctx context.Context, err error, config Config) error {
+	switch {
+	case config.FirstByteTimeout > 0 && context.Cause(ctx) == errFirstByteTimeout:
+		return fmt.Errorf("no response byte within --first-byte-timeout of %s: %w", config.FirstByteTimeout, errFirstByteTimeout)
+	case config.TLSHandshakeTimeout > 0 && strings.Contains(err.Error(), "TLS handshake timeout"):
+		return fmt.Errorf("TLS handshake exceeded --tls-handshake-timeout of %s: %w", config.TLSHandshakeTimeout, err)
+	case config.ResponseHeaderTimeout > 0 && strings.Contains(err.Error(), "timeout awaiting response headers"):
+		return fmt.Errorf("response headers exceeded --response-header-timeout of %s: %w", config.ResponseHeaderTimeout, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhaseTimeouts(t *testing.T) {
+	// Accepts connections but never answers, stalling the TLS handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	slowHeaders := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte("late"))
+	}))
+	defer slowHeaders.Close()
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			"TLS handshake",
+			Config{URL: "https://" + listener.Addr().String(), TLSHandshakeTimeout: 100 * time.Millisecond},
+			"--tls-handshake-timeout of 100ms",
+		},
+		{
+			"Response headers",
+			Config{URL: slowHeaders.URL, ResponseHeaderTimeout: 100 * time.Millisecond},
+			"--response-header-timeout of 100ms",
+		},
+		{
+			"First byte",
+			Config{URL: slowHeaders.URL, FirstByteTimeout: 100 * time.Millisecond},
+			"--first-byte-timeout of 100ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _, _ := newTestRequester(t, tt.config)
+
+			start := time.Now()
+			err := r.execute()
+			if err == nil {
+				t.Fatal("Expected the phase timeout to fire")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error to mention %q, got: %v", tt.expected, err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected the request to fail quickly, took %s", elapsed)
+			}
+			if category := errorCategory(err); category != "timeout" {
+				t.Errorf("Expected timeout category, got %q", category)
+			}
+		})
+	}
+}
+
+func TestFirstByteTimeoutAllowsSlowBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte(" second"))
+	}))
+	defer server.Close()
+
+	r, stdout, _ := newTestRequester(t, Config{URL: server.URL, FirstByteTimeout: 100 * time.Millisecond})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "first second") {
+		t.Errorf("Expected the full body, got: %s", stdout.String())
+	}
+}
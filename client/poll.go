@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statusClause is one comparison like "status>=200"
+type statusClause struct {
+	op    string
+	value int
+}
+
+func (c statusClause) matches(status int) bool {
+	switch c.op {
+	case "==":
+		return status == c.value
+	case "!=":
+		return status != c.value
+	case "<":
+		return status < c.value
+	case "<=":
+		return status <= c.value
+	case ">":
+		return status > c.value
+	default:
+		return status >= c.value
+	}
+}
+
+// UntilCondition is a flag value holding status comparisons joined by &&,
+// such as "status==200" or "status>=200 && status<300"
+type UntilCondition struct {
+	raw     string
+	clauses []statusClause
+}
+
+func (u *UntilCondition) String() string {
+	return u.raw
+}
+
+func (u *UntilCondition) Set(value string) error {
+	var clauses []statusClause
+	for _, part := range strings.Split(value, "&&") {
+		expr := strings.ReplaceAll(part, " ", "")
+		rest, ok := strings.CutPrefix(expr, "status")
+		if !ok {
+			return fmt.Errorf("invalid condition %q (expected e.g. 'status==200')", strings.TrimSpace(part))
+		}
+
+		var op string
+		for _, candidate := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+			if strings.HasPrefix(rest, candidate) {
+				op = candidate
+				break
+			}
+		}
+		code, err := strconv.Atoi(strings.TrimPrefix(rest, op))
+		if op == "" || err != nil {
+			return fmt.Errorf("invalid condition %q (expected e.g. 'status==200')", strings.TrimSpace(part))
+		}
+		clauses = append(clauses, statusClause{op: op, value: code})
+	}
+
+	u.raw = value
+	u.clauses = clauses
+	return nil
+}
+
+func (u *UntilCondition) matches(status int) bool {
+	for _, clause := range u.clauses {
+		if !clause.matches(status) {
+			return false
+		}
+	}
+	return true
+}
+
+// BodyPattern is a flag value holding a compiled regular expression
+type BodyPattern struct {
+	*regexp.Regexp
+}
+
+func (p *BodyPattern) String() string {
+	if p.Regexp == nil {
+		return ""
+	}
+	return p.Regexp.String()
+}
+
+func (p *BodyPattern) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return fmt.Errorf("invalid regular expression: %w", err)
+	}
+	p.Regexp = re
+	return nil
+}
+
+// polling reports whether --until or --until-body was given
+func (c Config) polling() bool {
+	return c.Until.raw != "" || c.UntilBody.Regexp != nil
+}
+
+// poll repeats the request every --poll-interval until the response meets
+// --until and --until-body, then prints it. When --poll-timeout runs out
+// first, the last response is printed and the run fails.
+func (r *requester) poll() error {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		var met bool
+		var last *http.Response
+
+		err := r.do(r.config.URL, true, func(resp *http.Response) error {
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return fmt.Errorf("failed to read response body: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			met = r.config.Until.matches(resp.StatusCode) &&
+				(r.config.UntilBody.Regexp == nil || r.config.UntilBody.Match(body))
+			if met {
+				return r.printResponse(resp)
+			}
+			last = resp
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+		if met {
+			return nil
+		}
+
+		if r.config.PollTimeout > 0 && time.Since(start)+r.config.PollInterval > r.config.PollTimeout {
+			if err := r.printResponse(last); err != nil {
+				return err
+			}
+			return &exitError{
+				code: exitAssertion,
+				err:  fmt.Errorf("condition not met after %d attempts within --poll-timeout %s", attempt, r.config.PollTimeout),
+			}
+		}
+
+		select {
+		case <-time.After(r.config.PollInterval):
+		case <-r.ctx.Done():
+			return interruptedError(0)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUntilCondition(t *testing.T) {
+	tests := []struct {
+		condition   string
+		status      int
+		expected    bool
+		expectError bool
+	}{
+		{"status==200", 200, true, false},
+		{"status==200", 202, false, false},
+		{"status != 202", 200, true, false},
+		{"status>=200 && status<300", 204, true, false},
+		{"status>=200 && status<300", 302, false, false},
+		{"status<=404", 404, true, false},
+		{"status>500", 503, true, false},
+		{"code==200", 0, false, true},
+		{"status=200", 0, false, true},
+		{"status==abc", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			var until UntilCondition
+			err := until.Set(tt.condition)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for condition %q", tt.condition)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := until.matches(tt.status); got != tt.expected {
+				t.Errorf("Expected %v for status %d, got %v", tt.expected, tt.status, got)
+			}
+		})
+	}
+}
+
+func newJobServer(t *testing.T, pending int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= int32(pending) {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"state":"running"}`))
+			return
+		}
+		w.Write([]byte(`{"state":"done"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestPollUntilStatus(t *testing.T) {
+	server, calls := newJobServer(t, 2)
+
+	config := Config{URL: server.URL, PollInterval: 10 * time.Millisecond, PollTimeout: 5 * time.Second}
+	config.Until.Set("status==200")
+	r, stdout, _ := newTestRequester(t, config)
+
+	if err := r.execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 requests, got %d", *calls)
+	}
+	if out := stdout.String(); !strings.Contains(out, "done") || strings.Contains(out, "running") {
+		t.Errorf("Expected only the final response to be printed, got: %s", out)
+	}
+}
+
+func TestPollUntilBody(t *testing.T) {
+	server, calls := newJobServer(t, 2)
+
+	config := Config{URL: server.URL, PollInterval: 10 * time.Millisecond, PollTimeout: 5 * time.Second}
+	config.UntilBody.Set(`"state":"done"`)
+	r, _, _ := newTestRequester(t, config)
+
+	if err := r.execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 requests, got %d", *calls)
+	}
+}
+
+func TestPollTimeout(t *testing.T) {
+	server, _ := newJobServer(t, 1000)
+
+	config := Config{URL: server.URL, PollInterval: 20 * time.Millisecond, PollTimeout: 100 * time.Millisecond}
+	config.Until.Set("status==200")
+	r, stdout, _ := newTestRequester(t, config)
+
+	err := r.execute()
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != exitAssertion {
+		t.Fatalf("Expected exit code %d, got: %v", exitAssertion, err)
+	}
+	if !strings.Contains(err.Error(), "--poll-timeout") {
+		t.Errorf("Expected poll timeout error, got: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "running") {
+		t.Errorf("Expected the last response to be printed, got: %s", stdout.String())
+	}
+}
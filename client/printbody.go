@@ -0,0 +1,63 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// printRequestBody writes the assembled request body to w and puts it back
+// on the request so it can still be sent. Binary bodies are summarized.
+func printRequestBody(w io.Writer, req *http.Request) error {
+	if req.Body == nil {
+		return nil
+	}
+
+	body, err := bufferRequestBody(req)
+	if err != nil {
+		return err
+	}
+
+	if isBinary(body) {
+		contentType := req.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(body)
+		}
+		fmt.Fprintf(w, "[binary body: %d bytes, %s]\n", len(body), contentType)
+		return nil
+	}
+
+	w.Write(body)
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// bufferRequestBody reads the request body into memory and puts it back so
+// the request can still be sent, and resent on redirects
+func bufferRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return body, nil
+}
+
+func isBinary(data []byte) bool {
+	return !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newEchoBodyServer(t *testing.T, received *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*received = string(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPrintBodyJSON(t *testing.T) {
+	var received string
+	server := newEchoBodyServer(t, &received)
+
+	r, _, stderr := newTestRequester(t, Config{
+		Method:     "POST",
+		URL:        server.URL,
+		JSONFields: []string{"name=test"},
+		PrintBody:  true,
+	})
+	if err := r.do(server.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if stderr.String() != "{\"name\":\"test\"}\n" {
+		t.Errorf("Unexpected printed body: %q", stderr.String())
+	}
+	if received != `{"name":"test"}` {
+		t.Errorf("Expected body to still be sent, server got %q", received)
+	}
+}
+
+func TestPrintBodyForm(t *testing.T) {
+	var received string
+	server := newEchoBodyServer(t, &received)
+
+	file := filepath.Join(t.TempDir(), "note.txt")
+	os.WriteFile(file, []byte("file contents"), 0644)
+
+	r, _, stderr := newTestRequester(t, Config{
+		Method:    "POST",
+		URL:       server.URL,
+		Form:      []string{"title=hello", "upload=@" + file},
+		PrintBody: true,
+	})
+	if err := r.do(server.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	printed := stderr.String()
+	boundary := regexp.MustCompile(`^--([0-9a-f]+)\r\n`).FindStringSubmatch(printed)
+	if boundary == nil {
+		t.Fatalf("Expected printed body to start with a multipart boundary, got:\n%s", printed)
+	}
+	for _, want := range []string{
+		`Content-Disposition: form-data; name="title"` + "\r\n\r\nhello\r\n",
+		`Content-Disposition: form-data; name="upload"; filename="note.txt"`,
+		"file contents\r\n--" + boundary[1] + "--\r\n",
+	} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Expected printed body to contain %q, got:\n%s", want, printed)
+		}
+	}
+	if received != printed {
+		t.Error("Expected the printed body to be exactly what was sent")
+	}
+}
+
+func TestPrintBodyBinarySummary(t *testing.T) {
+	var received string
+	server := newEchoBodyServer(t, &received)
+
+	file := filepath.Join(t.TempDir(), "blob.bin")
+	os.WriteFile(file, []byte{0x00, 0xff, 0x10, 0x80}, 0644)
+
+	r, _, stderr := newTestRequester(t, Config{
+		Method:    "POST",
+		URL:       server.URL,
+		Headers:   []string{"Content-Type: application/octet-stream"},
+		Data:      "@" + file,
+		PrintBody: true,
+	})
+	if err := r.do(server.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if stderr.String() != "[binary body: 4 bytes, application/octet-stream]\n" {
+		t.Errorf("Unexpected summary: %q", stderr.String())
+	}
+	if len(received) != 4 {
+		t.Errorf("Expected 4 bytes to be sent, got %d", len(received))
+	}
+}
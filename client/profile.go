@@ -0,0 +1,163 @@
+package client
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileFile is the config file looked up under the home directory when
+// --config isn't given
+const profileFile = ".go-http-client/config.yaml"
+
+// profileConfig is the layout of the config file: named profiles, each a
+// set of defaults for one environment
+type profileConfig struct {
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+type profile struct {
+	BaseURL string      `yaml:"base_url"`
+	Headers []string    `yaml:"headers"`
+	Timeout string      `yaml:"timeout"`
+	Proxy   string      `yaml:"proxy"`
+	Auth    profileAuth `yaml:"auth"`
+}
+
+type profileAuth struct {
+	User              string   `yaml:"user"`
+	Password          string   `yaml:"password"`
+	Bearer            string   `yaml:"bearer"`
+	Header            string   `yaml:"header"`
+	OAuthClientID     string   `yaml:"oauth2_client_id"`
+	OAuthClientSecret string   `yaml:"oauth2_client_secret"`
+	OAuthTokenURL     string   `yaml:"oauth2_token_url"`
+	OAuthScopes       []string `yaml:"oauth2_scopes"`
+}
+
+// defaultProfilePath returns ~/.go-http-client/config.yaml
+func defaultProfilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, profileFile), nil
+}
+
+// loadProfile reads the config file at path and returns the named profile
+func loadProfile(path, name string) (profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return profile{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file profileConfig
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return profile{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	p, ok := file.Profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	return p, nil
+}
+
+// applyProfile fills in the settings the command line left unset. Headers
+// are the exception: the profile's go first and -H headers are applied on
+// top of them, so a -H of the same name wins.
+func applyProfile(config *Config, p profile, set map[string]bool, headers *HeaderList, scopes *ScopeList) error {
+	setAny := func(names ...string) bool {
+		for _, name := range names {
+			if set[name] {
+				return true
+			}
+		}
+		return false
+	}
+
+	if p.BaseURL != "" && !setAny("base-url") {
+		config.BaseURL = p.BaseURL
+	}
+	if len(p.Headers) > 0 {
+		*headers = append(append(HeaderList{}, p.Headers...), *headers...)
+	}
+	if p.Timeout != "" && !setAny("timeout") {
+		timeout, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid profile timeout %q: %w", p.Timeout, err)
+		}
+		config.Timeout = timeout
+	}
+	if p.Proxy != "" && !setAny("proxy") {
+		config.Proxy = p.Proxy
+	}
+
+	// Credentials are taken from the profile only when no authentication
+	// was chosen on the command line, so that e.g. --bearer isn't mixed
+	// with the profile's basic auth user
+	if setAny("user", "password", "digest", "bearer", "bearer-command", "auth-header", "auth-value",
+		"client-id", "client-secret", "token-url", "scope", "oauth2-auth-code") {
+		return nil
+	}
+	p.Auth.apply(config)
+	if len(p.Auth.OAuthScopes) > 0 && len(*scopes) == 0 {
+		*scopes = append(ScopeList{}, p.Auth.OAuthScopes...)
+	}
+	return nil
+}
+
+// apply sets the credentials in config, except for the OAuth2 scopes,
+// which parseFlags collects separately
+func (a profileAuth) apply(config *Config) {
+	config.Username = a.User
+	config.Password = a.Password
+	config.BearerToken = a.Bearer
+	config.CustomHeader = a.Header
+	config.ClientID = a.OAuthClientID
+	config.ClientSecret = a.OAuthClientSecret
+	config.TokenURL = a.OAuthTokenURL
+}
+
+// empty reports whether no credentials are configured
+func (a profileAuth) empty() bool {
+	return a.User == "" && a.Password == "" && a.Bearer == "" && a.Header == "" &&
+		a.OAuthClientID == "" && a.OAuthClientSecret == "" && a.OAuthTokenURL == "" && len(a.OAuthScopes) == 0
+}
+
+// setFlags returns the names of the flags given on the command line,
+// including the aliases of each: giving -t also marks --timeout as set.
+// Aliases are the flags registered against the same variable.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	aliases := make(map[uintptr][]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if ptr, ok := flagPointer(f); ok {
+			aliases[ptr] = append(aliases[ptr], f.Name)
+		}
+	})
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+		if ptr, ok := flagPointer(f); ok {
+			for _, name := range aliases[ptr] {
+				set[name] = true
+			}
+		}
+	})
+	return set
+}
+
+// flagPointer returns the address of the variable behind f's value
+func flagPointer(f *flag.Flag) (uintptr, bool) {
+	v := reflect.ValueOf(f.Value)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return 0, false
+	}
+	return v.Pointer(), true
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testProfiles = `profiles:
+  staging:
+    base_url: https://staging.example.com
+    headers:
+      - "X-Team: payments"
+      - "Accept: application/json"
+    timeout: 5s
+    proxy: http://proxy.example.com:3128
+    auth:
+      user: alice:secret
+  broken:
+    timeout: soon
+`
+
+func TestProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testProfiles), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		baseURL  string
+		headers  []string
+		timeout  time.Duration
+		proxy    string
+		username string
+		bearer   string
+	}{
+		{
+			"Profile defaults",
+			[]string{"--profile", "staging"},
+			"https://staging.example.com", []string{"X-Team: payments", "Accept: application/json"},
+			5 * time.Second, "http://proxy.example.com:3128", "alice", "",
+		},
+		{
+			"Flags override the profile",
+			[]string{"--profile", "staging", "--base-url", "http://localhost:8080", "--timeout", "1s", "-x", "http://other:8080", "-H", "Accept: text/plain"},
+			"http://localhost:8080", []string{"X-Team: payments", "Accept: application/json", "Accept: text/plain"},
+			time.Second, "http://other:8080", "alice", "",
+		},
+		{
+			"Auth on the command line replaces the profile's",
+			[]string{"--profile", "staging", "--bearer", "token"},
+			"https://staging.example.com", []string{"X-Team: payments", "Accept: application/json"},
+			5 * time.Second, "http://proxy.example.com:3128", "", "token",
+		},
+		{
+			"Short flags override the profile",
+			[]string{"--profile", "staging", "-t", "2s", "-b", "token"},
+			"https://staging.example.com", []string{"X-Team: payments", "Accept: application/json"},
+			2 * time.Second, "http://proxy.example.com:3128", "", "token",
+		},
+		{
+			"Any auth flag replaces the profile's",
+			[]string{"--profile", "staging", "--client-id", "id", "--client-secret", "secret", "--token-url", "https://auth.example.com/token"},
+			"https://staging.example.com", []string{"X-Team: payments", "Accept: application/json"},
+			5 * time.Second, "http://proxy.example.com:3128", "", "",
+		},
+		{
+			"No profile",
+			nil,
+			"", nil, defaultTimeout, "", "", "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr strings.Builder
+			args := append([]string{"--config", path}, tt.args...)
+			if len(tt.args) == 0 {
+				args = nil
+			}
+			config, err := parseFlags(append(args, "/users"), &stderr)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v (%s)", err, stderr.String())
+			}
+			if config.BaseURL != tt.baseURL {
+				t.Errorf("Expected base URL %q, got %q", tt.baseURL, config.BaseURL)
+			}
+			if !reflect.DeepEqual([]string(config.Headers), tt.headers) {
+				t.Errorf("Expected headers %q, got %q", tt.headers, config.Headers)
+			}
+			if config.Timeout != tt.timeout {
+				t.Errorf("Expected timeout %v, got %v", tt.timeout, config.Timeout)
+			}
+			if config.Proxy != tt.proxy {
+				t.Errorf("Expected proxy %q, got %q", tt.proxy, config.Proxy)
+			}
+			if config.Username != tt.username || config.BearerToken != tt.bearer {
+				t.Errorf("Expected user %q and bearer %q, got %q and %q", tt.username, tt.bearer, config.Username, config.BearerToken)
+			}
+		})
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testProfiles), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"Unknown profile", []string{"--config", path, "--profile", "prod"}, `profile "prod" not found`},
+		{"Invalid timeout", []string{"--config", path, "--profile", "broken"}, "invalid profile timeout"},
+		{"Missing file", []string{"--config", path + ".missing", "--profile", "staging"}, "failed to read config file"},
+		{"Config without profile", []string{"--config", path}, "--config requires --profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(append(tt.args, "http://example.com"), &stdout, &stderr); code != exitUsage {
+				t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+			}
+			if !strings.Contains(stderr.String(), tt.expected) {
+				t.Errorf("Expected %q in stderr, got %q", tt.expected, stderr.String())
+			}
+		})
+	}
+}
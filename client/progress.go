@@ -0,0 +1,115 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// progressInterval is how often the progress bar is redrawn
+	progressInterval = 200 * time.Millisecond
+	progressWidth    = 30
+)
+
+// progressReader redraws a progress bar on w as a body passes through it:
+// percent and a bar when the total size is known, then the bytes so far
+// and the average throughput. The final state is drawn, followed by a
+// newline, once the body ends or fails.
+type progressReader struct {
+	r     io.Reader
+	w     io.Writer
+	total int64
+	n     int64
+	start time.Time
+	drawn time.Time
+	done  bool
+	now   func() time.Time
+}
+
+func newProgressReader(r io.Reader, w io.Writer, total int64) *progressReader {
+	return &progressReader{r: r, w: w, total: total, start: time.Now(), now: time.Now}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+
+	switch {
+	case err != nil:
+		p.finish()
+	case p.now().Sub(p.drawn) >= progressInterval:
+		p.draw()
+	}
+	return n, err
+}
+
+// finish draws the final state; later calls do nothing
+func (p *progressReader) finish() {
+	if p.done {
+		return
+	}
+	p.done = true
+	p.draw()
+	fmt.Fprintln(p.w)
+}
+
+func (p *progressReader) draw() {
+	p.drawn = p.now()
+	fmt.Fprintf(p.w, "\r%s", p.line())
+}
+
+func (p *progressReader) line() string {
+	var rate int64
+	if elapsed := p.now().Sub(p.start).Seconds(); elapsed > 0 {
+		rate = int64(float64(p.n) / elapsed)
+	}
+
+	if p.total <= 0 {
+		return fmt.Sprintf("%s  %s/s", formatBytes(p.n), formatBytes(rate))
+	}
+
+	percent := min(p.n*100/p.total, 100)
+	filled := int(percent) * progressWidth / 100
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressWidth-filled)
+	return fmt.Sprintf("%3d%% [%s] %s / %s  %s/s", percent, bar, formatBytes(p.n), formatBytes(p.total), formatBytes(rate))
+}
+
+// formatBytes prints n with the binary suffixes --max-filesize and the
+// other size flags accept
+func formatBytes(n int64) string {
+	const units = "KMGT"
+	if n < 1<<10 {
+		return fmt.Sprintf("%dB", n)
+	}
+	value, unit := float64(n)/(1<<10), 0
+	for value >= 1<<10 && unit < len(units)-1 {
+		value /= 1 << 10
+		unit++
+	}
+	return fmt.Sprintf("%.1f%ciB", value, units[unit])
+}
+
+// showDownloadProgress reports whether a body saved to a file gets a
+// progress bar: only for a person watching stderr, and not with
+// --no-progress
+func (r *requester) showDownloadProgress() bool {
+	return !r.config.NoProgress && isTerminal(r.stderr)
+}
+
+// progressBody is a request body reporting upload progress. Closing it
+// ends the progress line even when the upload was cut short.
+type progressBody struct {
+	*progressReader
+	closer io.Closer
+}
+
+func newProgressBody(body io.ReadCloser, w io.Writer, total int64) *progressBody {
+	return &progressBody{progressReader: newProgressReader(body, w, total), closer: body}
+}
+
+func (b *progressBody) Close() error {
+	b.finish()
+	return b.closer.Close()
+}
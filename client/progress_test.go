@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		expected string
+	}{
+		{"Known size", 2048, "100% [==============================] 2.0KiB / 2.0KiB  1.0KiB/s\n"},
+		{"Unknown size", -1, "2.0KiB  1.0KiB/s\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			p := newProgressReader(strings.NewReader(strings.Repeat("x", 2048)), &out, tt.total)
+			p.start = start
+			p.now = func() time.Time { return start.Add(2 * time.Second) }
+
+			data, err := io.ReadAll(p)
+			if err != nil || len(data) != 2048 {
+				t.Fatalf("Expected the body to pass through, got %d bytes: %v", len(data), err)
+			}
+
+			lines := strings.Split(out.String(), "\r")
+			if last := lines[len(lines)-1]; last != tt.expected {
+				t.Errorf("Expected final line %q, got %q", tt.expected, last)
+			}
+			if strings.Count(out.String(), "\n") != 1 {
+				t.Errorf("Expected a single trailing newline, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestProgressReaderPartial(t *testing.T) {
+	p := &progressReader{total: 1000, n: 250, now: time.Now, start: time.Now()}
+	if line := p.line(); !strings.HasPrefix(line, " 25% [=======                       ] 250B / 1000B") {
+		t.Errorf("Unexpected progress line %q", line)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:             "0B",
+		1023:          "1023B",
+		1536:          "1.5KiB",
+		5 << 20:       "5.0MiB",
+		3 << 30:       "3.0GiB",
+		(1 << 40) * 2: "2.0TiB",
+	}
+	for n, expected := range tests {
+		if got := formatBytes(n); got != expected {
+			t.Errorf("formatBytes(%d): expected %s, got %s", n, expected, got)
+		}
+	}
+}
+
+func TestUploadProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "upload.bin")
+	os.WriteFile(path, []byte(strings.Repeat("x", 4096)), 0644)
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{"File body", Config{Data: "@" + path}, "100% [==============================] 4.0KiB / 4.0KiB"},
+		{"Form body", Config{Form: []string{"upload=@" + path}}, "100% [==============================]"},
+		{"No body", Config{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.URL = server.URL
+			tt.config.Method = "POST"
+			tt.config.Progress = true
+			r, _, stderr := newTestRequester(t, tt.config)
+			if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			if tt.expected == "" {
+				if stderr.Len() != 0 {
+					t.Errorf("Expected no progress without a body, got %q", stderr.String())
+				}
+				return
+			}
+			lines := strings.Split(stderr.String(), "\r")
+			last := lines[len(lines)-1]
+			if !strings.HasPrefix(last, tt.expected) || !strings.HasSuffix(last, "/s\n") {
+				t.Errorf("Expected final progress line %q, got %q", tt.expected, last)
+			}
+		})
+	}
+}
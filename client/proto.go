@@ -0,0 +1,200 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	protoContentType   = "application/proto"
+	grpcWebContentType = "application/grpc-web+proto"
+
+	// grpcWebTrailer marks the gRPC-web frame that carries the trailers
+	grpcWebTrailer = 0x80
+)
+
+// protoCodec converts JSON request bodies to binary protobuf and protobuf
+// responses back to JSON, using message types from a descriptor set
+type protoCodec struct {
+	request  protoreflect.MessageDescriptor
+	response protoreflect.MessageDescriptor
+	grpcWeb  bool
+}
+
+// loadProtoCodec reads the FileDescriptorSet given with --proto, as written
+// by `protoc --include_imports --descriptor_set_out` or `buf build`, and
+// looks up the --message and --response-message types in it
+func loadProtoCodec(config Config) (*protoCodec, error) {
+	data, err := os.ReadFile(config.ProtoFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load descriptor set: %w", err)
+	}
+
+	codec := &protoCodec{grpcWeb: config.GRPCWeb}
+	if codec.request, err = findMessage(files, config.ProtoMessage); err != nil {
+		return nil, err
+	}
+	codec.response = codec.request
+	if config.ProtoResponseMessage != "" {
+		if codec.response, err = findMessage(files, config.ProtoResponseMessage); err != nil {
+			return nil, err
+		}
+	}
+	return codec, nil
+}
+
+func findMessage(files *protoregistry.Files, name string) (protoreflect.MessageDescriptor, error) {
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(strings.TrimPrefix(name, ".")))
+	if err != nil {
+		return nil, fmt.Errorf("message %q not found in descriptor set", name)
+	}
+	message, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message type", name)
+	}
+	return message, nil
+}
+
+// encodeBody converts a JSON body to the request message, framed for
+// gRPC-web when --grpc-web is set, and returns it with its Content-Type
+func (c *protoCodec) encodeBody(body io.Reader) (io.Reader, string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	message := dynamicpb.NewMessage(c.request)
+	if err := protojson.Unmarshal(data, message); err != nil {
+		return nil, "", fmt.Errorf("failed to encode body as %s: %w", c.request.FullName(), err)
+	}
+	encoded, err := proto.Marshal(message)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode body as %s: %w", c.request.FullName(), err)
+	}
+
+	if !c.grpcWeb {
+		return bytes.NewReader(encoded), protoContentType, nil
+	}
+	frame := make([]byte, 5, 5+len(encoded))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(encoded)))
+	return bytes.NewReader(append(frame, encoded...)), grpcWebContentType, nil
+}
+
+// decodeResponse replaces a protobuf or gRPC-web response body with the
+// response message as JSON, moving gRPC-web trailers into resp.Trailer.
+// Other bodies, such as the JSON errors Connect sends, are left alone.
+func (c *protoCodec) decodeResponse(resp *http.Response) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case protoContentType, "application/protobuf", "application/x-protobuf", "application/grpc-web", grpcWebContentType:
+	default:
+		return nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if strings.HasPrefix(mediaType, "application/grpc-web") {
+		if resp.Trailer == nil {
+			resp.Trailer = http.Header{}
+		}
+		if data, err = parseGRPCWebFrames(data, resp.Trailer); err != nil {
+			return err
+		}
+	}
+
+	message := dynamicpb.NewMessage(c.response)
+	if err := proto.Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to decode response as %s: %w", c.response.FullName(), err)
+	}
+	marshaled, err := protojson.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to decode response as %s: %w", c.response.FullName(), err)
+	}
+	// protojson varies its spacing on purpose; compact it so output is stable
+	var decoded bytes.Buffer
+	if err := json.Compact(&decoded, marshaled); err != nil {
+		return fmt.Errorf("failed to decode response as %s: %w", c.response.FullName(), err)
+	}
+
+	resp.Body = io.NopCloser(&decoded)
+	resp.ContentLength = int64(decoded.Len())
+	resp.Header.Set("Content-Type", "application/json")
+	return nil
+}
+
+// grpcStatusError reports a gRPC status other than OK, read from the
+// trailers or, in a trailers-only reply, the headers
+func grpcStatusError(resp *http.Response) error {
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status == "" || status == "0" {
+		return nil
+	}
+	return fmt.Errorf("grpc-status %s: %s", status, message)
+}
+
+// parseGRPCWebFrames returns the message in a gRPC-web body and adds the
+// headers in its trailer frame to trailer
+func parseGRPCWebFrames(data []byte, trailer http.Header) ([]byte, error) {
+	var message []byte
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, fmt.Errorf("truncated gRPC-web frame")
+		}
+		flags, size := data[0], binary.BigEndian.Uint32(data[1:5])
+		if uint32(len(data)-5) < size {
+			return nil, fmt.Errorf("truncated gRPC-web frame")
+		}
+		payload := data[5 : 5+size]
+		data = data[5+size:]
+
+		if flags&grpcWebTrailer == 0 {
+			message = payload
+			continue
+		}
+		// The trailer frame is a header block without the closing blank line
+		block := append(bytes.Clone(payload), "\r\n\r\n"...)
+		headers, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(block))).ReadMIMEHeader()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse gRPC-web trailers: %w", err)
+		}
+		for key, values := range headers {
+			for _, value := range values {
+				trailer.Add(key, value)
+			}
+		}
+	}
+	return message, nil
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// testDescriptorSet describes greet.HelloRequest {string name = 1} and
+// greet.HelloReply {string message = 1; int32 count = 2}
+func testDescriptorSet() *descriptorpb.FileDescriptorSet {
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     kind.Enum(),
+		}
+	}
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("greet.proto"),
+		Package: proto.String("greet"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("HelloRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			}},
+			{Name: proto.String("HelloReply"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("message", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("count", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			}},
+		},
+	}}}
+}
+
+func writeDescriptorSet(t *testing.T) string {
+	t.Helper()
+	data, err := proto.Marshal(testDescriptorSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "greet.pb")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newGreetServer answers HelloRequest with a HelloReply, as plain protobuf
+// or framed for gRPC-web with the given status in the trailers
+func newGreetServer(t *testing.T, grpcStatus string) *httptest.Server {
+	t.Helper()
+	files, err := protodesc.NewFiles(testDescriptorSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(name string) protoreflect.MessageDescriptor {
+		descriptor, _ := files.FindDescriptorByName(protoreflect.FullName(name))
+		return descriptor.(protoreflect.MessageDescriptor)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		grpcWeb := r.Header.Get("Content-Type") == grpcWebContentType
+		if grpcWeb {
+			body = body[5:]
+		}
+
+		request := dynamicpb.NewMessage(lookup("greet.HelloRequest"))
+		if err := proto.Unmarshal(body, request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name := request.Get(request.Descriptor().Fields().ByName("name")).String()
+
+		reply := dynamicpb.NewMessage(lookup("greet.HelloReply"))
+		reply.Set(reply.Descriptor().Fields().ByName("message"), protoreflect.ValueOfString("Hello, "+name))
+		reply.Set(reply.Descriptor().Fields().ByName("count"), protoreflect.ValueOfInt32(2))
+		data, _ := proto.Marshal(reply)
+
+		if !grpcWeb {
+			w.Header().Set("Content-Type", protoContentType)
+			w.Write(data)
+			return
+		}
+		w.Header().Set("Content-Type", grpcWebContentType)
+		trailer := []byte("grpc-status: " + grpcStatus + "\r\ngrpc-message: not allowed\r\n")
+		for _, frame := range []struct {
+			flags   byte
+			payload []byte
+		}{{0, data}, {grpcWebTrailer, trailer}} {
+			header := make([]byte, 5)
+			header[0] = frame.flags
+			binary.BigEndian.PutUint32(header[1:], uint32(len(frame.payload)))
+			w.Write(append(header, frame.payload...))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProto(t *testing.T) {
+	descriptors := writeDescriptorSet(t)
+
+	tests := []struct {
+		name       string
+		grpcStatus string
+		args       []string
+		code       int
+		stderr     string
+	}{
+		{"Connect", "0", nil, 0, ""},
+		{"gRPC-web", "0", []string{"--grpc-web"}, 0, ""},
+		{"gRPC-web error status", "7", []string{"--grpc-web"}, exitFailure, "grpc-status 7: not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newGreetServer(t, tt.grpcStatus)
+			args := append([]string{
+				"--body-only", "--raw",
+				"--proto", descriptors, "--message", "greet.HelloRequest", "--response-message", "greet.HelloReply",
+				"-d", `{"name": "Ada"}`,
+			}, tt.args...)
+
+			var stdout, stderr strings.Builder
+			code := Run(append(args, server.URL), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr %q)", tt.code, code, stderr.String())
+			}
+			if stdout.String() != `{"message":"Hello, Ada","count":2}` {
+				t.Errorf("Expected the reply as JSON, got %q", stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected %q in stderr, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestProtoErrors(t *testing.T) {
+	descriptors := writeDescriptorSet(t)
+	server := newGreetServer(t, "0")
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"Message without descriptors", []string{"--message", "greet.HelloRequest"}, exitUsage, "--proto and --message must be given together"},
+		{"Unknown message", []string{"--proto", descriptors, "--message", "greet.Missing"}, exitFailure, `message "greet.Missing" not found`},
+		{"Body doesn't match", []string{"--proto", descriptors, "--message", "greet.HelloRequest", "-d", `{"nope": 1}`}, exitFailure, "failed to encode body as greet.HelloRequest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(append(tt.args, server.URL), &stdout, &stderr); code != tt.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr %q)", tt.code, code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("Expected %q in stderr, got %q", tt.want, stderr.String())
+			}
+		})
+	}
+}
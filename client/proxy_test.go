@@ -0,0 +1,196 @@
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// socks5Server accepts unauthenticated CONNECT requests and relays them,
+// counting the connections it has proxied
+func socks5Server(t *testing.T, connections *int32) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				// Greeting: version, methods; answer "no authentication"
+				header := make([]byte, 2)
+				if _, err := io.ReadFull(conn, header); err != nil {
+					return
+				}
+				io.ReadFull(conn, make([]byte, header[1]))
+				conn.Write([]byte{5, 0})
+
+				// Request: version, CONNECT, reserved, address type
+				request := make([]byte, 4)
+				if _, err := io.ReadFull(conn, request); err != nil {
+					return
+				}
+				var host string
+				switch request[3] {
+				case 1:
+					ip := make([]byte, 4)
+					io.ReadFull(conn, ip)
+					host = net.IP(ip).String()
+				case 3:
+					length := make([]byte, 1)
+					io.ReadFull(conn, length)
+					name := make([]byte, length[0])
+					io.ReadFull(conn, name)
+					host = string(name)
+				default:
+					return
+				}
+				port := make([]byte, 2)
+				io.ReadFull(conn, port)
+
+				target, err := net.Dial("tcp", net.JoinHostPort(host, fmt.Sprint(binary.BigEndian.Uint16(port))))
+				if err != nil {
+					conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer target.Close()
+				atomic.AddInt32(connections, 1)
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+				go io.Copy(target, conn)
+				io.Copy(conn, target)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestProxy(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "origin")
+	}))
+	defer origin.Close()
+
+	var proxied int32
+	httpProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		fmt.Fprintf(w, "proxied %s", r.URL)
+	}))
+	defer httpProxy.Close()
+
+	var socksConnections int32
+	socksAddr := socks5Server(t, &socksConnections)
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+		counter  *int32
+	}{
+		{"HTTP proxy", Config{Proxy: httpProxy.URL}, "proxied " + origin.URL + "/", &proxied},
+		{"Bare host:port", Config{Proxy: strings.TrimPrefix(httpProxy.URL, "http://")}, "proxied " + origin.URL + "/", &proxied},
+		{"SOCKS5 proxy", Config{Proxy: "socks5://" + socksAddr}, "origin", &socksConnections},
+		{"No proxy", Config{Proxy: httpProxy.URL, NoProxy: "127.0.0.1"}, "origin", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before int32
+			if tt.counter != nil {
+				before = atomic.LoadInt32(tt.counter)
+			}
+
+			tt.config.URL = origin.URL + "/"
+			r, stdout, _ := newTestRequester(t, tt.config)
+			if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			if !strings.HasSuffix(stdout.String(), tt.expected) {
+				t.Errorf("Expected body %q, got %q", tt.expected, stdout.String())
+			}
+			if tt.counter != nil && atomic.LoadInt32(tt.counter) == before {
+				t.Error("Expected the request to go through the proxy")
+			}
+		})
+	}
+}
+
+func TestHTTP10Proxy(t *testing.T) {
+	var originProto atomic.Value
+	origin := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		originProto.Store(r.Proto)
+		fmt.Fprint(w, "origin")
+	}))
+	defer origin.Close()
+
+	var tunnels int32
+	var seen atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			seen.Store(fmt.Sprintf("%s %s %s", r.Proto, r.RequestURI, r.Header.Get("Proxy-Authorization")))
+			fmt.Fprint(w, "proxied")
+			return
+		}
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer target.Close()
+		atomic.AddInt32(&tunnels, 1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go io.Copy(target, conn)
+		io.Copy(conn, target)
+	}))
+	defer proxy.Close()
+
+	send := func(config Config, url string) (string, error) {
+		config.HTTPVersion = "1.0"
+		config.Insecure = true
+		config.URL = url
+		r, stdout, _ := newTestRequester(t, config)
+		err := r.do(url, true, r.printResponse)
+		return stdout.String(), err
+	}
+
+	proxyWithAuth := strings.Replace(proxy.URL, "http://", "http://ann:secret@", 1)
+	body, err := send(Config{Proxy: proxyWithAuth}, "http://example.test/items?id=1")
+	if err != nil || !strings.HasSuffix(body, "proxied") {
+		t.Fatalf("Expected the proxy to answer, got %q (%v)", body, err)
+	}
+	if got := seen.Load(); got != "HTTP/1.0 http://example.test/items?id=1 Basic YW5uOnNlY3JldA==" {
+		t.Errorf("Expected an HTTP/1.0 absolute-URI request with credentials, got %q", got)
+	}
+
+	body, err = send(Config{Proxy: proxy.URL}, origin.URL+"/")
+	if err != nil || !strings.HasSuffix(body, "origin") {
+		t.Fatalf("Expected the origin through the tunnel, got %q (%v)", body, err)
+	}
+	if atomic.LoadInt32(&tunnels) != 1 || originProto.Load() != "HTTP/1.0" {
+		t.Errorf("Expected one CONNECT tunnel carrying HTTP/1.0, got %d tunnels and %v", tunnels, originProto.Load())
+	}
+
+	if _, err := send(Config{Proxy: "socks5://127.0.0.1:1"}, origin.URL+"/"); err == nil || !strings.Contains(err.Error(), "--http-version 1.0 does not support socks5 proxies") {
+		t.Errorf("Expected SOCKS proxies to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitAppliesToEveryMode(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Polling succeeds on the third attempt
+		if atomic.AddInt32(&requests, 1)%3 != 0 {
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	until := UntilCondition{}
+	until.Set("status==200")
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"Repeat", Config{Repeat: 3}},
+		{"Poll", Config{Until: until, PollTimeout: 5 * time.Second}},
+		{"Benchmark", Config{Benchmark: true, Requests: 3, Concurrency: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			tt.config.URL = server.URL
+			tt.config.RateLimit = "1/100ms"
+			r, _, _ := newTestRequester(t, tt.config)
+
+			start := time.Now()
+			if err := r.execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := atomic.LoadInt32(&requests); got != 3 {
+				t.Fatalf("Expected 3 requests, got %d", got)
+			}
+			if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+				t.Errorf("Expected 3 requests to be paced over at least 200ms, took %v", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// decodeHex decodes --data-hex input. Whitespace and colons between bytes
+// are ignored, so dumps like "de ad be ef" or "de:ad:be:ef" can be pasted.
+func decodeHex(s string) ([]byte, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == ':' || r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	cleaned = strings.TrimPrefix(strings.TrimPrefix(cleaned, "0x"), "0X")
+
+	data, err := hex.DecodeString(cleaned)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --data-hex: %w", err)
+	}
+	return data, nil
+}
+
+// decodeBase64 decodes --data-base64 input in the standard or URL-safe
+// alphabet, with or without padding
+func decodeBase64(s string) ([]byte, error) {
+	cleaned := strings.Join(strings.Fields(s), "")
+
+	var firstErr error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		data, err := encoding.DecodeString(cleaned)
+		if err == nil {
+			return data, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, fmt.Errorf("invalid --data-base64: %w", firstErr)
+}
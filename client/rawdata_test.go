@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRawDataBody(t *testing.T) {
+	var received []byte
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	expected := []byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"Hex", []string{"--data-hex", "DEADBEEF0001"}},
+		{"Hex with separators", []string{"--data-hex", "de:ad:be:ef 00 01"}},
+		{"Base64", []string{"--data-base64", "3q2+7wAB"}},
+		{"Base64 URL-safe unpadded", []string{"--data-base64", "3q2-7wAB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received, method = nil, ""
+
+			var stdout, stderr bytes.Buffer
+			if code := Run(append(tt.args, server.URL), &stdout, &stderr); code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+			}
+			if !bytes.Equal(received, expected) {
+				t.Errorf("Expected body %x, got %x", expected, received)
+			}
+			if method != "POST" {
+				t.Errorf("Expected POST, got %s", method)
+			}
+		})
+	}
+}
+
+func TestRawDataInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+		message  string
+	}{
+		{"Odd hex length", []string{"--data-hex", "ABC"}, exitFailure, "invalid --data-hex"},
+		{"Bad hex digit", []string{"--data-hex", "ZZ"}, exitFailure, "invalid --data-hex"},
+		{"Bad base64", []string{"--data-base64", "!!!"}, exitFailure, "invalid --data-base64"},
+		{"Conflicting flags", []string{"--data-hex", "00", "-d", "x"}, exitUsage, "Only one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(append(tt.args, "http://127.0.0.1:1"), &stdout, &stderr)
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, code)
+			}
+			if !strings.Contains(stderr.String(), tt.message) {
+				t.Errorf("Expected %q on stderr, got: %s", tt.message, stderr.String())
+			}
+		})
+	}
+}
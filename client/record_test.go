@@ -0,0 +1,116 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Path", r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"recorded":true}`))
+	}))
+	url := server.URL + "/things?id=1"
+	dir := t.TempDir()
+
+	var recorded, recordErr bytes.Buffer
+	if code := Run([]string{"--headers-json", "-X", "POST", "-d", "payload", "--record", dir, url}, &recorded, &recordErr); code != 0 {
+		t.Fatalf("Recording failed with exit code %d: %s", code, recordErr.String())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 recording, got %d", len(entries))
+	}
+
+	// Replay must not need the network
+	server.Close()
+
+	var replayed, replayErr bytes.Buffer
+	if code := Run([]string{"--headers-json", "-X", "POST", "-d", "payload", "--replay", dir, url}, &replayed, &replayErr); code != 0 {
+		t.Fatalf("Replay failed with exit code %d: %s", code, replayErr.String())
+	}
+
+	if replayed.String() != recorded.String() {
+		t.Errorf("Replayed output differs.\nRecorded:\n%s\nReplayed:\n%s", recorded.String(), replayed.String())
+	}
+	if !strings.Contains(replayed.String(), `"status":202`) || !strings.HasSuffix(replayed.String(), `{"recorded":true}`) {
+		t.Errorf("Unexpected replayed output:\n%s", replayed.String())
+	}
+}
+
+func TestReplayMiss(t *testing.T) {
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"--replay", dir, "http://example.test/missing"}, &stdout, &stderr)
+
+	if code == 0 {
+		t.Fatal("Expected replay miss to fail")
+	}
+	if !strings.Contains(stderr.String(), "no recorded response for GET http://example.test/missing") {
+		t.Errorf("Expected miss to be reported, got: %s", stderr.String())
+	}
+}
+
+func TestReplayKeysOnBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"-X", "POST", "-d", "one", "--record", dir, server.URL}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Recording failed: %s", stderr.String())
+	}
+	server.Close()
+
+	if code := Run([]string{"-X", "POST", "-d", "two", "--replay", dir, server.URL}, &stdout, &stderr); code == 0 {
+		t.Error("Expected a different body to miss the recording")
+	}
+}
+
+func TestRecordThenReplayMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		w.Write([]byte("uploaded " + r.FormValue("name")))
+	}))
+	dir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "report.txt")
+	os.WriteFile(file, []byte("contents"), 0644)
+	args := []string{"-f", "name=report", "-f", "file=@" + file, "--body-only"}
+
+	var recorded, stderr bytes.Buffer
+	if code := Run(append(args, "--record", dir, server.URL), &recorded, &stderr); code != 0 {
+		t.Fatalf("Recording failed: %s", stderr.String())
+	}
+	server.Close()
+
+	// The form gets a new random boundary, which must not cause a miss
+	var replayed bytes.Buffer
+	if code := Run(append(args, "--replay", dir, server.URL), &replayed, &stderr); code != 0 {
+		t.Fatalf("Replay failed: %s", stderr.String())
+	}
+	if replayed.String() != "uploaded report" || replayed.String() != recorded.String() {
+		t.Errorf("Expected the recorded upload to replay, got %q (recorded %q)", replayed.String(), recorded.String())
+	}
+
+	// A different file still misses
+	os.WriteFile(file, []byte("changed"), 0644)
+	if code := Run(append(args, "--replay", dir, server.URL), &replayed, &stderr); code == 0 {
+		t.Error("Expected a different upload to miss the recording")
+	}
+}
+
+func TestRecordReplayExclusive(t *testing.T) {
+	if _, err := buildHTTPClient(Config{RecordDir: t.TempDir(), ReplayDir: t.TempDir()}); err == nil {
+		t.Error("Expected --record and --replay together to be rejected")
+	}
+}
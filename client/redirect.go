@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// defaultMaxRedirects matches net/http's own limit and applies when a
+// Config leaves MaxRedirects unset
+const defaultMaxRedirects = 10
+
+// checkRedirect is the client's CheckRedirect: it stops at the first
+// redirect with --no-follow, enforces --max-redirects and, with
+// --show-redirects, reports each hop as it's followed
+func (r *requester) checkRedirect(req *http.Request, via []*http.Request) error {
+	if r.config.NoFollow {
+		return http.ErrUseLastResponse
+	}
+
+	if r.config.ShowRedirects && req.Response != nil {
+		resp := req.Response
+		fmt.Fprintf(r.stderr, "* Redirect: %s %s\n", resp.Proto, resp.Status)
+		fmt.Fprintf(r.stderr, "*   Location: %s\n", req.URL)
+	}
+
+	limit := r.config.MaxRedirects
+	if limit == 0 {
+		limit = defaultMaxRedirects
+	}
+	if len(via) > limit {
+		return fmt.Errorf("stopped after %d redirects (--max-redirects)", limit)
+	}
+	return nil
+}
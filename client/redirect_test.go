@@ -0,0 +1,108 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// redirectChain redirects /hop/N to /hop/N-1 until /hop/0, which answers
+func redirectChain() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		if n > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", n-1), http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, "arrived")
+	}))
+}
+
+func TestRedirects(t *testing.T) {
+	server := redirectChain()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		config    Config
+		expectErr bool
+		status    string
+	}{
+		{"Follows by default", Config{}, false, "200 OK"},
+		{"No follow prints the redirect", Config{NoFollow: true}, false, "302 Found"},
+		{"Within max redirects", Config{MaxRedirects: 3}, false, "200 OK"},
+		{"Beyond max redirects", Config{MaxRedirects: 2}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.URL = server.URL + "/hop/3"
+			r, stdout, _ := newTestRequester(t, tt.config)
+
+			err := r.do(r.config.URL, true, r.printResponse)
+			if tt.expectErr {
+				if err == nil || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+					t.Errorf("Expected redirect limit error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(stdout.String(), "HTTP/1.1 "+tt.status+"\n") {
+				t.Errorf("Expected %s, got %q", tt.status, stdout.String())
+			}
+		})
+	}
+}
+
+func TestShowRedirects(t *testing.T) {
+	server := redirectChain()
+	defer server.Close()
+
+	r, _, stderr := newTestRequester(t, Config{URL: server.URL + "/hop/2", ShowRedirects: true})
+	if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "* Redirect: HTTP/1.1 302 Found\n" +
+		"*   Location: " + server.URL + "/hop/1\n" +
+		"* Redirect: HTTP/1.1 302 Found\n" +
+		"*   Location: " + server.URL + "/hop/0\n"
+	if stderr.String() != expected {
+		t.Errorf("Expected audit trail %q, got %q", expected, stderr.String())
+	}
+}
+
+func TestFollowFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		noFollow bool
+	}{
+		{"Default", nil, false},
+		{"No follow", []string{"--no-follow"}, true},
+		{"Follow false", []string{"--follow=false"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr strings.Builder
+			config, err := parseFlags(append(tt.args, "http://example.com"), &stderr)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if config.NoFollow != tt.noFollow {
+				t.Errorf("Expected NoFollow %t, got %t", tt.noFollow, config.NoFollow)
+			}
+		})
+	}
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--max-redirects", "0", "http://example.com"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for --max-redirects 0, got %d", exitUsage, code)
+	}
+}
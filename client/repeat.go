@@ -0,0 +1,45 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// repeat sends the request --repeat times in sequence. With
+// --output-pattern each body is saved to its own numbered file, counting
+// from 1; otherwise every response is printed.
+func (r *requester) repeat() error {
+	for i := 1; i <= max(r.config.Repeat, 1); i++ {
+		handle := r.printResponse
+		if r.config.OutputPattern != "" {
+			name := fmt.Sprintf(r.config.OutputPattern, i)
+			handle = func(resp *http.Response) error {
+				if err := r.printHeaders(resp); err != nil {
+					return err
+				}
+				file, err := os.Create(name)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				return r.writeBody(file, resp, false)
+			}
+		}
+
+		if err := r.do(r.config.URL, true, handle); err != nil {
+			return fmt.Errorf("iteration %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateOutputPattern requires exactly one %d and no other verbs
+func validateOutputPattern(pattern string) error {
+	rest := strings.ReplaceAll(pattern, "%%", "")
+	if strings.Count(rest, "%d") != 1 || strings.Contains(strings.Replace(rest, "%d", "", 1), "%") {
+		return fmt.Errorf("--output-pattern %q must contain exactly one %%d", pattern)
+	}
+	return nil
+}
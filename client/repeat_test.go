@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOutputPattern(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"call":%d}`, atomic.AddInt32(&requests, 1))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	r, _, _ := newTestRequester(t, Config{
+		URL:           server.URL,
+		Repeat:        3,
+		OutputPattern: filepath.Join(dir, "out-%d.json"),
+	})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		name := fmt.Sprintf("out-%d.json", i)
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+			continue
+		}
+		if expected := fmt.Sprintf(`{"call":%d}`, i); string(data) != expected {
+			t.Errorf("Expected %s to contain %s, got %s", name, expected, data)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 files, got %d", len(entries))
+	}
+}
+
+func TestRepeatPrintsEachResponse(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("pong\n"))
+	}))
+	defer server.Close()
+
+	r, stdout, _ := newTestRequester(t, Config{URL: server.URL, Repeat: 3})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+	if n := strings.Count(stdout.String(), "pong\n"); n != 3 {
+		t.Errorf("Expected 3 bodies printed, got %d", n)
+	}
+}
+
+func TestValidateOutputPattern(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		expectError bool
+	}{
+		{"out-%d.json", false},
+		{"100%%-%d.json", false},
+		{"out.json", true},
+		{"out-%d-%d.json", true},
+		{"out-%s.json", true},
+		{"out-%d-%v.json", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			err := validateOutputPattern(tt.pattern)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected pattern %q to be rejected", tt.pattern)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const replHelp = `Commands:
+  METHOD PATH [BODY]     send a request, e.g. GET /users or POST /users {"name":"a"}
+  set header KEY: VALUE  send a header with every following request
+  unset header KEY       stop sending a header
+  show headers           list the headers set in this session
+  show cookies           list the cookies stored for the base URL
+  help                   show this help
+  exit                   leave the REPL
+`
+
+var replMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// repl reads commands from in line by line and runs them against the URL
+// given on the command line, which acts as the base for relative paths. The
+// cookie jar, authentication and headers carry over from one command to the
+// next. A failed command is reported and the session continues.
+func (r *requester) repl(in io.Reader) error {
+	base := r.config.URL
+	scanner := bufio.NewScanner(in)
+
+	for {
+		fmt.Fprint(r.stderr, "> ")
+		if !scanner.Scan() {
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "exit" || line == "quit" {
+			return nil
+		}
+
+		if err := r.replCommand(base, line); err != nil {
+			fmt.Fprintf(r.stderr, "Error: %v\n", err)
+		}
+		if r.ctx.Err() != nil {
+			return interruptedError(0)
+		}
+	}
+	fmt.Fprintln(r.stderr)
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read REPL input: %w", err)
+	}
+	return nil
+}
+
+func (r *requester) replCommand(base, line string) error {
+	fields := strings.Fields(line)
+
+	switch {
+	case line == "help":
+		fmt.Fprint(r.stdout, replHelp)
+		return nil
+
+	case len(fields) >= 3 && fields[0] == "set" && fields[1] == "header":
+		header := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "set"), " header"))
+		key, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid header %q (expected 'set header Key: Value')", header)
+		}
+		r.unsetHeader(key)
+		r.config.Headers = append(r.config.Headers, strings.TrimSpace(key)+": "+strings.TrimSpace(value))
+		return nil
+
+	case len(fields) == 3 && fields[0] == "unset" && fields[1] == "header":
+		r.unsetHeader(fields[2])
+		return nil
+
+	case line == "show headers":
+		for _, header := range r.config.Headers {
+			fmt.Fprintln(r.stdout, header)
+		}
+		return nil
+
+	case line == "show cookies":
+		u, err := url.Parse(base)
+		if err != nil {
+			return fmt.Errorf("invalid base URL: %w", err)
+		}
+		for _, cookie := range r.client.Jar.Cookies(u) {
+			fmt.Fprintf(r.stdout, "%s=%s\n", cookie.Name, cookie.Value)
+		}
+		return nil
+	}
+
+	method := strings.ToUpper(fields[0])
+	if !slices.Contains(replMethods, method) || len(fields) < 2 {
+		return fmt.Errorf("unknown command %q (type 'help' for commands)", line)
+	}
+
+	target, err := joinURL(base, fields[1])
+	if err != nil {
+		return err
+	}
+
+	body := strings.TrimSpace(strings.SplitN(line, " ", 2)[1])
+	body = strings.TrimSpace(strings.TrimPrefix(body, fields[1]))
+
+	r.config.Method = method
+	r.config.Data = body
+	return r.do(target, true, r.printResponse)
+}
+
+// unsetHeader drops every session header named key
+func (r *requester) unsetHeader(key string) {
+	key = strings.TrimSpace(key)
+	r.config.Headers = slices.DeleteFunc(r.config.Headers, func(header string) bool {
+		name, _, _ := strings.Cut(header, ":")
+		return strings.EqualFold(strings.TrimSpace(name), key)
+	})
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			fmt.Fprint(w, "logged in")
+		case "/api/me":
+			cookie, _ := r.Cookie("session")
+			var session string
+			if cookie != nil {
+				session = cookie.Value
+			}
+			fmt.Fprintf(w, "session=%s tenant=%s", session, r.Header.Get("X-Tenant"))
+		case "/api/echo":
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s %s", r.Method, body)
+		}
+	}))
+	defer server.Close()
+
+	script := strings.Join([]string{
+		"GET /api/me",
+		"POST /api/login",
+		"set header X-Tenant: acme",
+		"get /api/me",
+		"PUT /api/echo {\"a\": 1}",
+		"unset header X-Tenant",
+		"GET /api/me",
+		"show cookies",
+		"FROB /api/me",
+		"exit",
+		"GET /api/never",
+	}, "\n")
+
+	r, stdout, stderr := newTestRequester(t, Config{URL: server.URL + "/", REPL: true})
+	if err := r.repl(strings.NewReader(script)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"session= tenant=",
+		"logged in",
+		"session=abc tenant=acme",
+		`PUT {"a": 1}`,
+		"session=abc tenant=",
+		"session=abc\n",
+	}
+	out := stdout.String()
+	for _, want := range expected {
+		i := strings.Index(out, want)
+		if i < 0 {
+			t.Fatalf("Expected %q in order in output, got:\n%s", want, stdout.String())
+		}
+		out = out[i+len(want):]
+	}
+
+	if !strings.Contains(stderr.String(), `Error: unknown command "FROB /api/me"`) {
+		t.Errorf("Expected unknown command error, got: %s", stderr.String())
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// requestHash is the one definition of request identity shared by features
+// that need to recognize a repeated request, like --record and --replay. It
+// digests the method, the URL with its query sorted, the named headers and
+// the body, so query parameter and header order don't change the result.
+// Headers not named are ignored.
+func requestHash(req *http.Request, body []byte, headers ...string) string {
+	h := sha256.New()
+
+	writeField(h, strings.ToUpper(req.Method))
+	writeField(h, normalizedURL(req.URL))
+
+	names := make([]string, 0, len(headers))
+	for _, name := range headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	sort.Strings(names)
+	names = slices.Compact(names)
+
+	for _, name := range names {
+		values := slices.Clone(req.Header.Values(name))
+		sort.Strings(values)
+		writeField(h, name)
+		writeField(h, strings.Join(values, "\n"))
+	}
+
+	writeField(h, string(body))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField writes s with a length prefix, so adjacent fields can't run
+// into each other
+func writeField(h hash.Hash, s string) {
+	fmt.Fprintf(h, "%d:%s", len(s), s)
+}
+
+// normalizedURL renders u with a lowercase scheme and host, no fragment and
+// the query sorted by key and then value
+func normalizedURL(u *url.URL) string {
+	normalized := *u
+	normalized.Scheme = strings.ToLower(u.Scheme)
+	normalized.Host = strings.ToLower(u.Host)
+	normalized.Fragment = ""
+	normalized.RawFragment = ""
+
+	query := u.Query()
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	normalized.RawQuery = query.Encode()
+
+	return normalized.String()
+}
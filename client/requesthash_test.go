@@ -0,0 +1,87 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+)
+
+func newHashRequest(t *testing.T, method, rawURL string, header http.Header) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req
+}
+
+func TestRequestHashStable(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *http.Request
+	}{
+		{
+			"Identical requests",
+			newHashRequest(t, "GET", "https://example.com/a?x=1", nil),
+			newHashRequest(t, "GET", "https://example.com/a?x=1", nil),
+		},
+		{
+			"Query order",
+			newHashRequest(t, "GET", "https://example.com/a?x=1&y=2&x=0", nil),
+			newHashRequest(t, "GET", "https://example.com/a?y=2&x=0&x=1", nil),
+		},
+		{
+			"Header order",
+			newHashRequest(t, "GET", "https://example.com/a", http.Header{"Accept": {"a", "b"}, "X-Tenant": {"t"}}),
+			newHashRequest(t, "GET", "https://example.com/a", http.Header{"X-Tenant": {"t"}, "Accept": {"b", "a"}}),
+		},
+		{
+			"Host case and fragment",
+			newHashRequest(t, "GET", "https://Example.COM/a#top", nil),
+			newHashRequest(t, "GET", "https://example.com/a", nil),
+		},
+		{
+			"Unnamed headers ignored",
+			newHashRequest(t, "GET", "https://example.com/a", http.Header{"Accept": {"a"}, "X-Request-Id": {"1"}}),
+			newHashRequest(t, "GET", "https://example.com/a", http.Header{"Accept": {"a"}, "X-Request-Id": {"2"}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := requestHash(tt.a, []byte("body"), "accept", "X-Tenant")
+			b := requestHash(tt.b, []byte("body"), "X-Tenant", "Accept")
+			if a != b {
+				t.Errorf("Expected equal hashes, got %s and %s", a, b)
+			}
+		})
+	}
+}
+
+func TestRequestHashDistinguishes(t *testing.T) {
+	base := requestHash(newHashRequest(t, "GET", "https://example.com/a?x=1", http.Header{"Accept": {"a"}}), []byte("body"), "Accept")
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		body string
+	}{
+		{"Method", newHashRequest(t, "POST", "https://example.com/a?x=1", http.Header{"Accept": {"a"}}), "body"},
+		{"Path", newHashRequest(t, "GET", "https://example.com/b?x=1", http.Header{"Accept": {"a"}}), "body"},
+		{"Query value", newHashRequest(t, "GET", "https://example.com/a?x=2", http.Header{"Accept": {"a"}}), "body"},
+		{"Named header", newHashRequest(t, "GET", "https://example.com/a?x=1", http.Header{"Accept": {"b"}}), "body"},
+		{"Body", newHashRequest(t, "GET", "https://example.com/a?x=1", http.Header{"Accept": {"a"}}), "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if requestHash(tt.req, []byte(tt.body), "Accept") == base {
+				t.Errorf("Expected %s to change the hash", tt.name)
+			}
+		})
+	}
+}
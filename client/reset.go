@@ -0,0 +1,46 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// resetRetries is how many times --retry-on-reset retries a request
+const resetRetries = 3
+
+// The --retry-on-reset backoff, which --retry-delay and --retry-max-delay
+// don't change
+const (
+	resetBaseDelay = 100 * time.Millisecond
+	resetMaxDelay  = 2 * time.Second
+)
+
+// isConnectionReset reports whether err means the peer dropped the
+// connection, either with a TCP reset or by closing it mid-response
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// canRetryBody reports whether the request body can be rebuilt for another
+// attempt; stdin can only be read once
+func canRetryBody(config Config) bool {
+	return config.Data != "-" && countStdinForms(config.Form) == 0
+}
+
+// bufferBody reads the whole response body into memory and puts it back so
+// it can still be consumed, e.g. so a reset while reading it surfaces before
+// anything has been printed
+func bufferBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
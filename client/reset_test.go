@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// scriptedTransport fails the first failures round trips with err
+type scriptedTransport struct {
+	failures int
+	err      error
+	midBody  bool
+	calls    int
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.calls++
+	if s.calls <= s.failures && !s.midBody {
+		return nil, s.err
+	}
+
+	var body io.Reader = strings.NewReader("ok")
+	if s.calls <= s.failures {
+		body = io.MultiReader(strings.NewReader("partial"), &errReader{s.err})
+	}
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       io.NopCloser(body),
+		Request:    req,
+	}, nil
+}
+
+type errReader struct {
+	err error
+}
+
+func (e *errReader) Read(p []byte) (int, error) {
+	return 0, e.err
+}
+
+func TestRetryOnReset(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport *scriptedTransport
+		retry     bool
+		expectErr bool
+		calls     int
+	}{
+		{"Reset before response is retried", &scriptedTransport{failures: 1, err: fmt.Errorf("read tcp: %w", syscall.ECONNRESET)}, true, false, 2},
+		{"Reset mid-body is retried", &scriptedTransport{failures: 2, err: io.ErrUnexpectedEOF, midBody: true}, true, false, 3},
+		{"Gives up after retries", &scriptedTransport{failures: 10, err: syscall.ECONNRESET}, true, true, resetRetries + 1},
+		{"Other errors are not retried", &scriptedTransport{failures: 1, err: errors.New("no such host")}, true, true, 1},
+		{"Disabled by default", &scriptedTransport{failures: 1, err: syscall.ECONNRESET}, false, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, stdout, _ := newTestRequester(t, Config{URL: "http://example.test", RetryOnReset: tt.retry})
+			r.client.Transport = tt.transport
+
+			err := r.do(r.config.URL, true, r.printResponse)
+			if tt.expectErr && err == nil {
+				t.Error("Expected request to fail")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.transport.calls != tt.calls {
+				t.Errorf("Expected %d attempts, got %d", tt.calls, tt.transport.calls)
+			}
+			if !tt.expectErr && strings.Count(stdout.String(), "200 OK") != 1 {
+				t.Errorf("Expected a single clean response, got:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRetryOnResetSkipsStdinBody(t *testing.T) {
+	r, _, _ := newTestRequester(t, Config{URL: "http://example.test", RetryOnReset: true, Data: "-", Method: "POST"})
+	transport := &scriptedTransport{failures: 1, err: syscall.ECONNRESET}
+	r.client.Transport = transport
+
+	if err := r.do(r.config.URL, true, r.printResponse); err == nil {
+		t.Error("Expected reset to be reported when the body can't be replayed")
+	}
+	if transport.calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", transport.calls)
+	}
+}
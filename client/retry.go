@@ -0,0 +1,44 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"http-client/retry"
+)
+
+// retryStatusError is returned in place of handling a response whose
+// status --retry-on matches while retries remain
+type retryStatusError struct {
+	status     string
+	retryAfter time.Duration
+}
+
+func (e *retryStatusError) Error() string {
+	return "received " + e.status
+}
+
+// retryStatus wraps handle so a response with a retryable status is
+// discarded rather than printed; on the last attempt it's handled as usual
+func (r *requester) retryStatus(handle func(*http.Response) error, retryable bool) func(*http.Response) error {
+	if !retryable {
+		return handle
+	}
+	return func(resp *http.Response) error {
+		if !r.config.RetryOn.Status(resp.StatusCode) {
+			return handle(resp)
+		}
+		after, _ := retry.RetryAfter(resp.Header, time.Now())
+		return &retryStatusError{status: resp.Status, retryAfter: after}
+	}
+}
+
+// retryBackoff is the wait before --retry attempt; a server's Retry-After
+// takes precedence over the exponential backoff, up to --retry-max-delay so
+// a far-off date can't stall the run
+func (r *requester) retryBackoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, r.config.RetryMaxDelay)
+	}
+	return retry.Delay(attempt, r.config.RetryDelay, r.config.RetryMaxDelay, r.config.RetryJitter, r.rand)
+}
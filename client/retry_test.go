@@ -0,0 +1,145 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"http-client/retry"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retries    int
+		retryOn    string
+		retryAfter string
+		calls      int32
+		status     string
+	}{
+		{"Retries 5xx until success", []int{503, 502, 200}, 3, retry.DefaultConditions, "", 3, "200 OK"},
+		{"Prints last response when retries run out", []int{500, 500, 500}, 2, retry.DefaultConditions, "", 3, "500 Internal Server Error"},
+		{"Retries 429", []int{429, 200}, 1, retry.DefaultConditions, "", 2, "200 OK"},
+		{"Zero Retry-After falls back to the backoff", []int{503, 200}, 1, retry.DefaultConditions, "0", 2, "200 OK"},
+		{"Statuses outside --retry-on are not retried", []int{404, 200}, 3, retry.DefaultConditions, "", 1, "404 Not Found"},
+		{"Disabled by default", []int{503, 200}, 0, retry.DefaultConditions, "", 1, "503 Service Unavailable"},
+		{"Custom conditions", []int{404, 200}, 1, "404", "", 2, "200 OK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			retryOn, err := retry.ParseConditions(tt.retryOn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, stdout, stderr := newTestRequester(t, Config{
+				URL:           server.URL,
+				Retry:         tt.retries,
+				RetryDelay:    time.Millisecond,
+				RetryMaxDelay: 10 * time.Millisecond,
+				RetryOn:       retryOn,
+			})
+
+			if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.calls {
+				t.Errorf("Expected %d attempts, got %d", tt.calls, got)
+			}
+			if !strings.HasPrefix(stdout.String(), "HTTP/1.1 "+tt.status+"\n") {
+				t.Errorf("Expected only the %s response to be printed, got %q", tt.status, stdout.String())
+			}
+			if retries := strings.Count(stderr.String(), "retrying"); retries != int(tt.calls)-1 {
+				t.Errorf("Expected %d retry messages, got %q", tt.calls-1, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRetryConnectionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		retryOn string
+		calls   int
+	}{
+		{"Connection failures are retried", retry.DefaultConditions, 2},
+		{"Unless --retry-on leaves them out", "5xx", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryOn, err := retry.ParseConditions(tt.retryOn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, _, _ := newTestRequester(t, Config{
+				URL:           "http://example.test",
+				Retry:         2,
+				RetryDelay:    time.Millisecond,
+				RetryMaxDelay: time.Millisecond,
+				RetryOn:       retryOn,
+			})
+			transport := &scriptedTransport{failures: 1, err: syscall.ECONNREFUSED}
+			r.client.Transport = transport
+
+			err = r.do(r.config.URL, true, r.printResponse)
+			if tt.calls == 2 && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.calls == 1 && err == nil {
+				t.Error("Expected request to fail")
+			}
+			if transport.calls != tt.calls {
+				t.Errorf("Expected %d attempts, got %d", tt.calls, transport.calls)
+			}
+		})
+	}
+}
+
+func TestRetryFlagValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"Negative retries", []string{"--retry", "-1", "http://example.com"}},
+		{"Unknown condition", []string{"--retry-on", "sometimes", "http://example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(tt.args, &stdout, &stderr); code != exitUsage {
+				t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	r, _, _ := newTestRequester(t, Config{RetryDelay: time.Second, RetryMaxDelay: 5 * time.Second, RetryJitter: retry.JitterNone})
+
+	if got := r.retryBackoff(2, 0); got != 2*time.Second {
+		t.Errorf("Expected exponential backoff of 2s, got %s", got)
+	}
+	if got := r.retryBackoff(2, 4*time.Second); got != 4*time.Second {
+		t.Errorf("Expected Retry-After of 4s to take precedence, got %s", got)
+	}
+	if got := r.retryBackoff(2, 24*time.Hour); got != 5*time.Second {
+		t.Errorf("Expected Retry-After of a day to be capped at 5s, got %s", got)
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// shadowResult is the outcome of the mirrored request
+type shadowResult struct {
+	status int
+	body   []byte
+	err    error
+}
+
+// startShadow mirrors req to the --shadow backend in the background. The
+// shadow URL supplies the scheme and host; the path and query follow the
+// primary request. The body is buffered so both requests can send it.
+func (r *requester) startShadow(ctx context.Context, req *http.Request) (<-chan shadowResult, error) {
+	target, err := url.Parse(r.config.ShadowURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shadow URL: %w", err)
+	}
+
+	body, err := bufferRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	shadowReq := req.Clone(ctx)
+	shadowReq.URL.Scheme = target.Scheme
+	shadowReq.URL.Host = target.Host
+	shadowReq.URL.User = target.User
+	shadowReq.Host = ""
+	if body != nil {
+		shadowReq.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	results := make(chan shadowResult, 1)
+	go func() {
+		resp, err := r.client.Do(shadowReq)
+		if err != nil {
+			results <- shadowResult{err: err}
+			return
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		results <- shadowResult{status: resp.StatusCode, body: data, err: err}
+	}()
+
+	return results, nil
+}
+
+// reportShadow prints how the shadow response differs from the primary one.
+// Nothing is printed when they match.
+func reportShadow(w io.Writer, status int, body []byte, shadow shadowResult) {
+	if shadow.err != nil {
+		fmt.Fprintf(w, "Shadow request failed: %v\n", shadow.err)
+		return
+	}
+
+	if shadow.status != status {
+		fmt.Fprintf(w, "Shadow status differs: primary %d, shadow %d\n", status, shadow.status)
+	}
+	if !bytes.Equal(shadow.body, body) {
+		fmt.Fprintf(w, "Shadow body differs: primary %d bytes, shadow %d bytes\n", len(body), len(shadow.body))
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestShadowReportsDifferences(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte("primary:" + r.URL.Path + ":" + string(body)))
+	}))
+	defer primary.Close()
+
+	var shadowPath, shadowBody string
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		shadowPath, shadowBody = r.URL.RequestURI(), string(body)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("shadow"))
+	}))
+	defer shadow.Close()
+
+	r, stdout, stderr := newTestRequester(t, Config{
+		Method:    "POST",
+		URL:       primary.URL + "/orders?id=1",
+		Data:      "payload",
+		ShadowURL: shadow.URL,
+	})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Primary request failed: %v", err)
+	}
+
+	if !strings.HasSuffix(stdout.String(), "primary:/orders:payload") {
+		t.Errorf("Expected the primary response to be printed, got:\n%s", stdout.String())
+	}
+	if shadowPath != "/orders?id=1" || shadowBody != "payload" {
+		t.Errorf("Expected shadow to receive /orders?id=1 with the body, got %q with %q", shadowPath, shadowBody)
+	}
+	for _, line := range []string{"Shadow status differs: primary 200, shadow 500", "Shadow body differs"} {
+		if !strings.Contains(stderr.String(), line) {
+			t.Errorf("Expected %q on stderr, got:\n%s", line, stderr.String())
+		}
+	}
+}
+
+func TestShadowMatchIsSilent(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("same"))
+	})
+	primary := httptest.NewServer(handler)
+	defer primary.Close()
+	shadow := httptest.NewServer(handler)
+	defer shadow.Close()
+
+	r, _, stderr := newTestRequester(t, Config{URL: primary.URL, ShadowURL: shadow.URL})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Primary request failed: %v", err)
+	}
+
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no report for matching responses, got:\n%s", stderr.String())
+	}
+}
+
+func TestShadowFailureDoesNotFailPrimary(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer primary.Close()
+
+	r, _, stderr := newTestRequester(t, Config{URL: primary.URL, ShadowURL: "http://127.0.0.1:1"})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Expected shadow failure not to fail the primary request: %v", err)
+	}
+
+	if !strings.Contains(stderr.String(), "Shadow request failed") {
+		t.Errorf("Expected shadow failure to be reported, got:\n%s", stderr.String())
+	}
+}
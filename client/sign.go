@@ -0,0 +1,23 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"http-client/auth"
+)
+
+// signRequest hands the final request and its body to signer, after every
+// other header has been set so they're covered by the signature
+func signRequest(signer auth.Signer, req *http.Request) error {
+	body, err := bufferRequestBody(req)
+	if err != nil {
+		return err
+	}
+
+	if err := signer.Sign(req, body); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	return nil
+}
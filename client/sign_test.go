@@ -0,0 +1,79 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSignerCommand(t *testing.T) {
+	var signature, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	// Signs with the last line of the canonical request, which is the body
+	script := filepath.Join(t.TempDir(), "sign.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"X-Signature: sig-$(tail -n 1)\"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	r, _, _ := newTestRequester(t, Config{
+		Method:        "POST",
+		URL:           server.URL,
+		Data:          "payload",
+		SignerCommand: script,
+	})
+	if err := r.execute(); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if signature != "sig-payload" {
+		t.Errorf("Expected X-Signature 'sig-payload', got %q", signature)
+	}
+	if body != "payload" {
+		t.Errorf("Expected body to still be sent after signing, got %q", body)
+	}
+}
+
+func TestAWSSigV4Flag(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	var authorization, payloadHash, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--aws-sigv4", "us-east-1/s3", "-X", "PUT", "-d", "hello", server.URL + "/bucket/key"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(authorization, "/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("Expected a SigV4 Authorization header, got %q", authorization)
+	}
+	if payloadHash != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" || body != "hello" {
+		t.Errorf("Expected the body and its hash to be sent, got %q and %q", body, payloadHash)
+	}
+
+	stderr.Reset()
+	if code := Run([]string{"--aws-sigv4", "us-east-1", server.URL}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for a scope without a service, got %d", exitUsage, code)
+	}
+	if !strings.Contains(stderr.String(), "use region/service") {
+		t.Errorf("Expected a usage error, got %q", stderr.String())
+	}
+}
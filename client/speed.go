@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// speedGuard aborts a response whose transfer rate stays below limit bytes
+// per second for window, like curl's --speed-limit and --speed-time. The
+// rate is sampled in the background so a stalled read is caught too.
+type speedGuard struct {
+	io.ReadCloser
+	limit  int64
+	window time.Duration
+	cancel context.CancelFunc
+
+	mutex   sync.Mutex
+	read    int64
+	tripped bool
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newSpeedGuard(body io.ReadCloser, limit int64, window time.Duration, cancel context.CancelFunc) *speedGuard {
+	g := &speedGuard{
+		ReadCloser: body,
+		limit:      limit,
+		window:     window,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	go g.monitor(min(time.Second, window/4))
+	return g
+}
+
+func (g *speedGuard) Read(p []byte) (int, error) {
+	n, err := g.ReadCloser.Read(p)
+	g.mutex.Lock()
+	g.read += int64(n)
+	g.mutex.Unlock()
+	return n, err
+}
+
+func (g *speedGuard) Close() error {
+	g.once.Do(func() { close(g.done) })
+	return g.ReadCloser.Close()
+}
+
+func (g *speedGuard) monitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last int64
+	var slowSince time.Time
+	lastTick := time.Now()
+
+	for {
+		select {
+		case <-g.done:
+			return
+		case now := <-ticker.C:
+			g.mutex.Lock()
+			read := g.read
+			g.mutex.Unlock()
+
+			rate := float64(read-last) / now.Sub(lastTick).Seconds()
+			last, lastTick = read, now
+
+			if rate >= float64(g.limit) {
+				slowSince = time.Time{}
+				continue
+			}
+			if slowSince.IsZero() {
+				slowSince = now.Add(-interval)
+			}
+			if now.Sub(slowSince) >= g.window {
+				g.mutex.Lock()
+				g.tripped = true
+				g.mutex.Unlock()
+				g.cancel()
+				return
+			}
+		}
+	}
+}
+
+// err reports the abort, or nil when the guard never tripped
+func (g *speedGuard) err() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !g.tripped {
+		return nil
+	}
+	return &exitError{
+		code: exitSlowTransfer,
+		err:  fmt.Errorf("transfer slower than --speed-limit of %d bytes/s for %s, aborted after %d bytes", g.limit, g.window, g.read),
+	}
+}
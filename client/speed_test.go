@@ -0,0 +1,56 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpeedLimitAbortsSlowTransfer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Trickle one byte every 50ms, far below the limit, until the client gives up
+		for {
+			if _, err := w.Write([]byte("x")); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(50 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	code := Run([]string{"--speed-limit", "1K", "--speed-time", "200ms", server.URL}, &stdout, &stderr)
+
+	if code != exitSlowTransfer {
+		t.Errorf("Expected exit code %d, got %d (stderr: %s)", exitSlowTransfer, code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "slower than --speed-limit of 1024 bytes/s for 200ms") {
+		t.Errorf("Expected slow transfer error, got: %s", stderr.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the transfer to be aborted quickly, took %s", elapsed)
+	}
+}
+
+func TestSpeedLimitAllowsFastTransfer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 64<<10)))
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"--speed-limit", "1K", "--speed-time", "200ms", server.URL}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("Expected fast transfer to succeed, got exit code %d (stderr: %s)", code, stderr.String())
+	}
+}
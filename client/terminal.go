@@ -0,0 +1,22 @@
+package client
+
+import (
+	"io"
+	"os"
+)
+
+// isTerminal reports whether w is a character device such as a terminal.
+// Anything that is not an *os.File (buffers, pipes wrapped by callers) is
+// treated as non-interactive.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
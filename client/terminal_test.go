@@ -0,0 +1,33 @@
+package client
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsTerminal(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if isTerminal(file) {
+		t.Error("Expected a regular file not to be a terminal")
+	}
+	if isTerminal(&bytes.Buffer{}) {
+		t.Error("Expected a buffer not to be a terminal")
+	}
+}
+
+func TestPrettyRawConflict(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--pretty", "--raw", "http://example.com"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+	}
+	if !bytes.Contains(stderr.Bytes(), []byte("--pretty and --raw cannot be combined")) {
+		t.Errorf("Expected conflict message, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// phaseTimer records when each phase of a request starts and ends, for
+// --trace-time. Phases a reused connection skips stay zero.
+type phaseTimer struct {
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+}
+
+func (p *phaseTimer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.dnsDone = time.Now()
+		},
+		ConnectStart: func(network, addr string) {
+			// Happy Eyeballs may dial several addresses; the first one counts
+			if p.connectStart.IsZero() {
+				p.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				p.connectDone = time.Now()
+			}
+		},
+		TLSHandshakeStart: func() {
+			p.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.tlsDone = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			p.firstByte = time.Now()
+		},
+	}
+}
+
+// phaseTimings is how long each phase of a request took. TTFB runs from
+// the start of the request to the first response byte, Transfer from there
+// to the end of the body.
+type phaseTimings struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	TTFB     time.Duration
+	Transfer time.Duration
+	Total    time.Duration
+}
+
+func (p *phaseTimer) timings(end time.Time) phaseTimings {
+	t := phaseTimings{
+		DNS:     span(p.dnsStart, p.dnsDone),
+		Connect: span(p.connectStart, p.connectDone),
+		TLS:     span(p.tlsStart, p.tlsDone),
+		TTFB:    span(p.start, p.firstByte),
+		Total:   end.Sub(p.start),
+	}
+	if !p.firstByte.IsZero() {
+		t.Transfer = end.Sub(p.firstByte)
+	}
+	return t
+}
+
+func span(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// writeTimings prints one line per request in the style of the other
+// --verbose reports
+func writeTimings(w io.Writer, t phaseTimings) {
+	fmt.Fprintf(w, "* Timing: dns=%s connect=%s tls=%s ttfb=%s transfer=%s total=%s\n",
+		t.DNS.Round(time.Microsecond), t.Connect.Round(time.Microsecond), t.TLS.Round(time.Microsecond),
+		t.TTFB.Round(time.Microsecond), t.Transfer.Round(time.Microsecond), t.Total.Round(time.Microsecond))
+}
+
+// timingsJSON is phaseTimings in milliseconds, as written to JSON
+type timingsJSON struct {
+	DNSMs      float64 `json:"dns_ms"`
+	ConnectMs  float64 `json:"connect_ms"`
+	TLSMs      float64 `json:"tls_ms"`
+	TTFBMs     float64 `json:"ttfb_ms"`
+	TransferMs float64 `json:"transfer_ms"`
+	TotalMs    float64 `json:"total_ms"`
+}
+
+func (t phaseTimings) json() timingsJSON {
+	ms := func(d time.Duration) float64 {
+		return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+	}
+	return timingsJSON{ms(t.DNS), ms(t.Connect), ms(t.TLS), ms(t.TTFB), ms(t.Transfer), ms(t.Total)}
+}
+
+// writeTimingsJSON prints the timings for rawURL as one JSON object per
+// line, in milliseconds
+func writeTimingsJSON(w io.Writer, rawURL string, t phaseTimings) error {
+	data, err := json.Marshal(struct {
+		URL string `json:"url"`
+		timingsJSON
+	}{rawURL, t.json()})
+	if err != nil {
+		return fmt.Errorf("failed to encode timings: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// phaseTimerKey carries the request's phaseTimer in its context, for
+// handlers that report timings themselves
+type phaseTimerKey struct{}
+
+// requestTimer returns the phaseTimer attached to req, if any
+func requestTimer(req *http.Request) *phaseTimer {
+	timer, _ := req.Context().Value(phaseTimerKey{}).(*phaseTimer)
+	return timer
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhaseTimings(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	timer := phaseTimer{
+		start:        start,
+		dnsStart:     at(1),
+		dnsDone:      at(3),
+		connectStart: at(3),
+		connectDone:  at(8),
+		tlsStart:     at(8),
+		tlsDone:      at(20),
+		firstByte:    at(50),
+	}
+	got := timer.timings(at(80))
+	expected := phaseTimings{
+		DNS:      2 * time.Millisecond,
+		Connect:  5 * time.Millisecond,
+		TLS:      12 * time.Millisecond,
+		TTFB:     50 * time.Millisecond,
+		Transfer: 30 * time.Millisecond,
+		Total:    80 * time.Millisecond,
+	}
+	if got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+
+	// A reused connection skips DNS, connect and TLS
+	reused := phaseTimer{start: start, firstByte: at(5)}
+	if got := reused.timings(at(6)); got.DNS != 0 || got.Connect != 0 || got.TLS != 0 || got.TTFB != 5*time.Millisecond {
+		t.Errorf("Expected only TTFB and transfer for a reused connection, got %+v", got)
+	}
+
+	var out bytes.Buffer
+	writeTimings(&out, expected)
+	if line := "* Timing: dns=2ms connect=5ms tls=12ms ttfb=50ms transfer=30ms total=80ms\n"; out.String() != line {
+		t.Errorf("Expected %q, got %q", line, out.String())
+	}
+}
+
+func TestTraceTime(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Run("Text", func(t *testing.T) {
+		r, _, stderr := newTestRequester(t, Config{URL: server.URL, TraceTime: true})
+		r.client.Transport = server.Client().Transport.(*http.Transport).Clone()
+		if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if !strings.HasPrefix(stderr.String(), "* Timing: dns=") || strings.Contains(stderr.String(), "tls=0s") {
+			t.Errorf("Expected a timing line with a TLS handshake, got %q", stderr.String())
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		r, _, stderr := newTestRequester(t, Config{URL: server.URL, TraceTime: true, TraceTimeJSON: true})
+		r.client.Transport = server.Client().Transport.(*http.Transport).Clone()
+		if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+
+		var report map[string]any
+		if err := json.Unmarshal(stderr.Bytes(), &report); err != nil {
+			t.Fatalf("Expected a JSON object, got %q: %v", stderr.String(), err)
+		}
+		for _, key := range []string{"dns_ms", "connect_ms", "tls_ms", "ttfb_ms", "transfer_ms", "total_ms"} {
+			if _, ok := report[key].(float64); !ok {
+				t.Errorf("Expected numeric %s, got %v", key, report[key])
+			}
+		}
+		if report["url"] != server.URL {
+			t.Errorf("Expected url %s, got %v", server.URL, report["url"])
+		}
+		if report["tls_ms"].(float64) <= 0 || report["total_ms"].(float64) < report["ttfb_ms"].(float64) {
+			t.Errorf("Unexpected timings %v", report)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// tlsInfo describes the negotiated TLS parameters for --tls-info
+func tlsInfo(state *tls.ConnectionState) string {
+	if state == nil {
+		return "none"
+	}
+
+	alpn := state.NegotiatedProtocol
+	if alpn == "" {
+		alpn = "none"
+	}
+
+	return fmt.Sprintf("version=%q cipher=%s alpn=%s resumed=%t",
+		tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite), alpn, state.DidResume)
+}
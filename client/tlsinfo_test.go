@@ -0,0 +1,62 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTLSInfo(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	r, stdout, stderr := newTestRequester(t, Config{URL: server.URL, TLSInfo: true})
+	r.client.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+
+	if err := r.execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	info := stderr.String()
+	if !strings.Contains(info, `* TLS: version="TLS 1.`) {
+		t.Errorf("Expected a TLS 1.x version, got: %s", info)
+	}
+	if !strings.Contains(info, "cipher=TLS_") {
+		t.Errorf("Expected a named cipher suite, got: %s", info)
+	}
+	if !strings.Contains(info, "alpn=h2") || !strings.Contains(info, "resumed=false") {
+		t.Errorf("Expected ALPN h2 on a fresh session, got: %s", info)
+	}
+	if strings.Contains(stdout.String(), "* TLS") {
+		t.Errorf("Expected TLS info to stay off stdout, got: %s", stdout.String())
+	}
+}
+
+func TestTLSInfoDescribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    *tls.ConnectionState
+		expected string
+	}{
+		{"Plain HTTP", nil, "none"},
+		{
+			"TLS 1.2 without ALPN",
+			&tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, DidResume: true},
+			`version="TLS 1.2" cipher=TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 alpn=none resumed=true`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tlsInfo(tt.state); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
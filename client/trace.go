@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"time"
+)
+
+// defaultUserAgent is what net/http sends when the request sets none
+const defaultUserAgent = "Go-http-client/1.1"
+
+// printRequestHead writes the request line and headers as they go out,
+// curl-style with "> " prefixes, including the Host, User-Agent and
+// Content-Length that net/http fills in itself
+func printRequestHead(w io.Writer, req *http.Request) {
+	fmt.Fprintf(w, "> %s %s %s\n", req.Method, req.URL.RequestURI(), req.Proto)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(w, "> Host: %s\n", host)
+	if req.Header.Get("User-Agent") == "" {
+		fmt.Fprintf(w, "> User-Agent: %s\n", defaultUserAgent)
+	}
+	if req.ContentLength > 0 {
+		fmt.Fprintf(w, "> Content-Length: %d\n", req.ContentLength)
+	}
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range req.Header[key] {
+			fmt.Fprintf(w, "> %s: %s\n", key, value)
+		}
+	}
+	fmt.Fprintln(w, ">")
+}
+
+// connInfo records how the transport obtained the connection for a request
+type connInfo struct {
+	start    time.Time
+	got      bool
+	reused   bool
+	wasIdle  bool
+	idleTime time.Duration
+	wait     time.Duration
+}
+
+func (c *connInfo) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			c.start = time.Now()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.got = true
+			c.reused = info.Reused
+			c.wasIdle = info.WasIdle
+			c.idleTime = info.IdleTime
+			if !c.start.IsZero() {
+				c.wait = time.Since(c.start)
+			}
+		},
+	}
+}
+
+func (c *connInfo) String() string {
+	return fmt.Sprintf("reused=%t was_idle=%t idle_time=%s wait=%s", c.reused, c.wasIdle, c.idleTime, c.wait)
+}
+
+// RequestStats holds timing collected for a single request
+type RequestStats struct {
+	Start time.Time
+	Total time.Duration
+}
+
+// checkResponseTime flags a request that completed but took longer than max.
+// Unlike the timeout, the request is allowed to finish.
+func checkResponseTime(stats RequestStats, max time.Duration) error {
+	if max <= 0 || stats.Total <= max {
+		return nil
+	}
+
+	return &exitError{
+		code: exitSlowResponse,
+		err:  fmt.Errorf("response took %s, exceeding --max-response-time of %s", stats.Total.Round(time.Millisecond), max),
+	}
+}
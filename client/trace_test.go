@@ -0,0 +1,167 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerboseReportsConnectionReuse(t *testing.T) {
+	var requests int32
+	server := newLinkedPagesServer(t, &requests)
+
+	r, _, stderr := newTestRequester(t, Config{
+		URL:      server.URL + "/items",
+		Paginate: true,
+		MaxPages: 2,
+		Verbose:  true,
+	})
+
+	if err := r.paginate(); err != nil {
+		t.Fatalf("Pagination failed: %v", err)
+	}
+
+	var reports []string
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if strings.HasPrefix(line, "* Connection: ") {
+			reports = append(reports, line)
+		}
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 connection reports, got %d:\n%s", len(reports), stderr.String())
+	}
+	if !strings.Contains(reports[0], "reused=false") {
+		t.Errorf("Expected first request to open a new connection, got: %s", reports[0])
+	}
+	if !strings.Contains(reports[1], "reused=true was_idle=true") {
+		t.Errorf("Expected second request to reuse an idle connection, got: %s", reports[1])
+	}
+	if !strings.Contains(reports[1], "idle_time=") || !strings.Contains(reports[1], "wait=") {
+		t.Errorf("Expected idle time and wait in report, got: %s", reports[1])
+	}
+}
+
+func TestConnectionReportOnlyWhenVerbose(t *testing.T) {
+	var requests int32
+	server := newLinkedPagesServer(t, &requests)
+
+	r, _, stderr := newTestRequester(t, Config{URL: server.URL + "/items"})
+	if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if strings.Contains(stderr.String(), "* Connection:") {
+		t.Errorf("Expected no connection report without --verbose, got:\n%s", stderr.String())
+	}
+}
+
+func TestMaxResponseTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("slow body"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		max      string
+		exitCode int
+	}{
+		{"Slow response fails", "50ms", exitSlowResponse},
+		{"Response within limit passes", "5s", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run([]string{"--max-response-time", tt.max, server.URL}, &stdout, &stderr)
+
+			if code != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.exitCode, code, stderr.String())
+			}
+			if !strings.HasSuffix(stdout.String(), "slow body") {
+				t.Errorf("Expected the request to complete and print its body, got:\n%s", stdout.String())
+			}
+			if tt.exitCode != 0 && !strings.Contains(stderr.String(), "exceeding --max-response-time of "+tt.max) {
+				t.Errorf("Expected actual vs allowed time to be reported, got: %s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestCheckResponseTime(t *testing.T) {
+	stats := RequestStats{Total: 1500 * time.Millisecond}
+
+	err := checkResponseTime(stats, time.Second)
+	if err == nil {
+		t.Fatal("Expected slow response to be flagged")
+	}
+	if err.Error() != "response took 1.5s, exceeding --max-response-time of 1s" {
+		t.Errorf("Unexpected message: %v", err)
+	}
+	if exitCode(err) != exitSlowResponse {
+		t.Errorf("Expected exit code %d, got %d", exitSlowResponse, exitCode(err))
+	}
+
+	if err := checkResponseTime(stats, 0); err != nil {
+		t.Errorf("Expected no check without a limit: %v", err)
+	}
+}
+
+func TestVerbosePrintsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		config    Config
+		expected  string
+		forbidden string
+	}{
+		{
+			name:   "Request line and headers",
+			config: Config{Method: "GET", Headers: []string{"X-Trace: 1"}, Query: []string{"page=2"}},
+			expected: "> GET /items?page=2 HTTP/1.1\n" +
+				"> Host: " + strings.TrimPrefix(server.URL, "http://") + "\n" +
+				"> User-Agent: Go-http-client/1.1\n" +
+				"> X-Trace: 1\n" +
+				">\n",
+		},
+		{
+			name:   "Body with --print-body",
+			config: Config{Method: "POST", Data: `{"a":1}`, PrintBody: true, Headers: []string{"Content-Type: application/json"}},
+			expected: "> Content-Length: 7\n" +
+				"> Content-Type: application/json\n" +
+				">\n" +
+				`{"a":1}` + "\n",
+		},
+		{
+			name:      "Body only when asked",
+			config:    Config{Method: "POST", Data: `{"a":1}`},
+			expected:  "> POST /items HTTP/1.1\n",
+			forbidden: `{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.URL = server.URL + "/items"
+			tt.config.Verbose = true
+			r, _, stderr := newTestRequester(t, tt.config)
+
+			if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if !strings.Contains(stderr.String(), tt.expected) {
+				t.Errorf("Expected stderr to contain:\n%s\ngot:\n%s", tt.expected, stderr.String())
+			}
+			if tt.forbidden != "" && strings.Contains(stderr.String(), tt.forbidden) {
+				t.Errorf("Expected %q not to be printed, got:\n%s", tt.forbidden, stderr.String())
+			}
+		})
+	}
+}
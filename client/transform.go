@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"http-client/response"
+)
+
+// MaskFieldList holds the JSON paths redacted by --mask-field
+type MaskFieldList []string
+
+func (m *MaskFieldList) String() string {
+	return strings.Join(*m, ", ")
+}
+
+func (m *MaskFieldList) Set(value string) error {
+	if _, err := response.NewMaskTransformer([]string{value}); err != nil {
+		return err
+	}
+	*m = append(*m, value)
+	return nil
+}
+
+// transformers builds the response transformers selected by flags, in the
+// order they run after the formatter: masking first, so the newline
+// handling sees the final body
+func (r *requester) transformers() ([]response.Transformer, error) {
+	var transformers []response.Transformer
+
+	if len(r.config.MaskFields) > 0 {
+		mask, err := response.NewMaskTransformer(r.config.MaskFields)
+		if err != nil {
+			return nil, err
+		}
+		transformers = append(transformers, mask)
+	}
+
+	var names []string
+	if r.config.TrimTrailingNewline {
+		names = append(names, response.TrimTrailingNewline)
+	}
+	if r.config.EnsureTrailingNewline {
+		names = append(names, response.EnsureTrailingNewline)
+	}
+	registered, err := response.Lookup(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(transformers, registered...), nil
+}
+
+// transformSavedBody applies the selected transformers to a body that is
+// about to be saved to a file. Bodies are otherwise streamed, so this only
+// buffers when a transformer is selected and the body is text.
+func (r *requester) transformSavedBody(resp *http.Response) error {
+	transformers, err := r.transformers()
+	if err != nil {
+		return err
+	}
+
+	// A body still carrying its Content-Encoding is compressed bytes, unless
+	// --compressed decoded it and only kept the header
+	contentType := resp.Header.Get("Content-Type")
+	encoded := resp.Header.Get("Content-Encoding") != "" && !resp.Uncompressed
+	if len(transformers) == 0 || !response.IsText(contentType) || encoded {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	for _, t := range transformers {
+		if body, err = t.Transform(contentType, body); err != nil {
+			return err
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
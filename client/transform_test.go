@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrailingNewline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/extra":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello\n\n"))
+		case "/missing":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello"))
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("bin\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		config   Config
+		expected string
+	}{
+		{"Trim extra newline", "/extra", Config{TrimTrailingNewline: true}, "hello"},
+		{"Ensure adds missing newline", "/missing", Config{EnsureTrailingNewline: true}, "hello\n"},
+		{"Ensure collapses extra newlines", "/extra", Config{EnsureTrailingNewline: true}, "hello\n"},
+		{"Binary left alone", "/binary", Config{TrimTrailingNewline: true}, "bin\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" on stdout", func(t *testing.T) {
+			tt.config.URL = server.URL + tt.path
+			r, stdout, _ := newTestRequester(t, tt.config)
+			if err := r.execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(stdout.String(), "\n\n"+tt.expected) {
+				t.Errorf("Expected body %q, got output %q", tt.expected, stdout.String())
+			}
+		})
+
+		t.Run(tt.name+" with -o", func(t *testing.T) {
+			tt.config.URL = server.URL + tt.path
+			tt.config.Output = filepath.Join(t.TempDir(), "out")
+			r, _, _ := newTestRequester(t, tt.config)
+			if err := r.execute(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			data, err := os.ReadFile(tt.config.Output)
+			if err != nil {
+				t.Fatalf("Failed to read output: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMaskField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":7,"password":"hunter2","user":{"email":"a@example.com","name":"ann"}}`))
+	}))
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "out.json")
+	for _, args := range [][]string{
+		{"--mask-field", "password", "--mask-field", "user.email", server.URL},
+		{"--mask-field", "password", "--mask-field", "user.email", "-o", output, server.URL},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+		}
+		if out := stdout.String(); strings.Contains(out, "hunter2") || strings.Contains(out, "a@example.com") {
+			t.Errorf("Expected masked values to stay out of the output, got: %s", out)
+		}
+	}
+
+	saved, _ := os.ReadFile(output)
+	expected := `{"id":7,"password":"***","user":{"email":"***","name":"ann"}}`
+	if string(saved) != expected {
+		t.Errorf("Expected saved body %s, got %s", expected, saved)
+	}
+}
+
+func TestMaskFieldValidation(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"--mask-field", "a..b", "http://127.0.0.1:1"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+	}
+	if !strings.Contains(stderr.String(), "invalid mask path") {
+		t.Errorf("Expected invalid path error, got: %s", stderr.String())
+	}
+}
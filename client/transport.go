@@ -0,0 +1,404 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"http-client/cookies"
+	"http-client/transport"
+)
+
+func buildHTTPClient(config Config) (*http.Client, error) {
+	base, err := buildTransport(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// One jar for the whole run, so cookies set by one response (a login,
+	// an earlier page) are sent on later requests to the same site
+	jar := cookies.New()
+	if config.CookieFile != "" {
+		if jar, err = cookies.Load(config.CookieFile); err != nil {
+			return nil, err
+		}
+	}
+	client := &http.Client{Transport: base, Jar: jar}
+
+	switch config.HTTPVersion {
+	case "":
+	case "1.1":
+		// A non-nil, empty TLSNextProto disables HTTP/2 negotiation
+		base.ForceAttemptHTTP2 = false
+		base.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case "1.0":
+		client.Transport = &http10Transport{base: base}
+	default:
+		return nil, fmt.Errorf("unsupported HTTP version %q (use 1.0 or 1.1)", config.HTTPVersion)
+	}
+
+	if config.RecordDir != "" || config.ReplayDir != "" {
+		recorder, err := newRecordReplayTransport(client.Transport, config.RecordDir, config.ReplayDir)
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = recorder
+	}
+
+	if config.Compressed {
+		client.Transport = transport.Decompress(client.Transport)
+	}
+
+	return client, nil
+}
+
+// buildTransport sets up the connection settings shared by every request:
+// TLS, proxy, timeouts, client certificates, the Unix socket and DNS cache
+func buildTransport(config Config) (*http.Transport, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig, err := transport.TLSConfig(transport.TLSOptions{
+		Insecure:     config.Insecure,
+		CAFile:       config.CACert,
+		CertFile:     config.ClientCert,
+		KeyFile:      config.ClientKey,
+		MinVersion:   config.TLSMin,
+		MaxVersion:   config.TLSMax,
+		CipherSuites: config.Ciphers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	base.TLSClientConfig = tlsConfig
+
+	if base.Proxy, err = transport.Proxy(config.Proxy, config.NoProxy); err != nil {
+		return nil, err
+	}
+
+	if config.TLSHandshakeTimeout > 0 {
+		base.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	}
+	if config.ResponseHeaderTimeout > 0 {
+		base.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+	}
+
+	if config.MaxHeaderBytes > 0 {
+		base.MaxResponseHeaderBytes = config.MaxHeaderBytes
+	}
+
+	if config.CertDir != "" {
+		certs, err := loadClientCertificates(config.CertDir)
+		if err != nil {
+			return nil, err
+		}
+		if base.TLSClientConfig == nil {
+			base.TLSClientConfig = &tls.Config{}
+		}
+		base.TLSClientConfig.GetClientCertificate = selectClientCertificate(certs)
+	}
+
+	if config.SNI != "" {
+		// The dial target and Host header still come from the URL; only the
+		// name sent in the ClientHello, and verified against, changes. URLs
+		// from --url-file are checked as each one is sent.
+		if config.URL != "" {
+			if err := checkSNIURL(config.URL); err != nil {
+				return nil, err
+			}
+		}
+		if base.TLSClientConfig == nil {
+			base.TLSClientConfig = &tls.Config{}
+		}
+		base.TLSClientConfig.ServerName = config.SNI
+	}
+
+	// Nothing is resolved or proxied when every connection goes to the socket
+	if config.UnixSocket != "" {
+		base.DialContext = transport.UnixDialer(config.UnixSocket)
+		base.Proxy = nil
+	}
+
+	if config.DNSCacheTTL > 0 && config.UnixSocket == "" {
+		dial := base.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		cache := newDNSCache(config.DNSCacheTTL, net.DefaultResolver.LookupHost)
+		base.DialContext = cache.dialContext(dial)
+	}
+
+	return base, nil
+}
+
+// setRequestProto records the requested protocol version on the request
+func setRequestProto(req *http.Request, version string) error {
+	if version == "" {
+		return nil
+	}
+
+	major, minor, ok := http.ParseHTTPVersion("HTTP/" + version)
+	if !ok {
+		return fmt.Errorf("invalid HTTP version %q", version)
+	}
+
+	req.Proto = "HTTP/" + version
+	req.ProtoMajor = major
+	req.ProtoMinor = minor
+	return nil
+}
+
+// checkSNIURL rejects a URL --sni can't apply to
+func checkSNIURL(rawURL string) error {
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme != "https" {
+		return fmt.Errorf("--sni requires an https URL")
+	}
+	return nil
+}
+
+// http10Transport speaks genuine HTTP/1.0 on the wire. net/http always writes
+// "HTTP/1.1" in the request line and may use chunked encoding, so the request
+// is serialized by hand over a fresh connection that is closed after the
+// response, with the body buffered to send an exact Content-Length. HTTP and
+// HTTPS proxies are used like net/http does: https URLs are tunnelled with
+// CONNECT and http URLs sent to the proxy with the full URL.
+type http10Transport struct {
+	base *http.Transport
+}
+
+func (t *http10Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	var proxyURL *url.URL
+	if t.base.Proxy != nil {
+		var err error
+		if proxyURL, err = t.base.Proxy(req); err != nil {
+			return nil, fmt.Errorf("failed to find a proxy: %w", err)
+		}
+	}
+
+	conn, err := t.dial(req.Context(), req.URL, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := context.AfterFunc(req.Context(), func() {
+		conn.Close()
+	})
+
+	// Only plain http requests go to the proxy itself; https ones were
+	// tunnelled and see the server directly
+	if req.URL.Scheme == "https" {
+		proxyURL = nil
+	}
+	if err := writeHTTP10Request(conn, req, body, proxyURL); err != nil {
+		stop()
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, err
+	}
+
+	// As with net/http, resp.TLS describes the connection to the server,
+	// not to an https proxy
+	if tlsConn, ok := conn.(*tls.Conn); ok && req.URL.Scheme == "https" {
+		state := tlsConn.ConnectionState()
+		resp.TLS = &state
+	}
+
+	resp.Body = &connBody{ReadCloser: resp.Body, conn: conn, stop: stop}
+	return resp, nil
+}
+
+// dial connects to u's server, through proxyURL when it's set
+func (t *http10Transport) dial(ctx context.Context, u *url.URL, proxyURL *url.URL) (net.Conn, error) {
+	addr := hostPort(u)
+
+	dial := t.base.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	if proxyURL == nil {
+		conn, err := dial(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "https" {
+			return conn, nil
+		}
+		return t.handshake(ctx, conn, u.Hostname())
+	}
+
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return nil, fmt.Errorf("--http-version 1.0 does not support %s proxies (use an HTTP proxy or --noproxy)", proxyURL.Scheme)
+	}
+
+	conn, err := dial(ctx, "tcp", hostPort(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	if proxyURL.Scheme == "https" {
+		if conn, err = t.handshake(ctx, conn, proxyURL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	if u.Scheme != "https" {
+		return conn, nil
+	}
+
+	if err := connectTunnel(conn, addr, proxyURL); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return t.handshake(ctx, conn, u.Hostname())
+}
+
+// handshake starts TLS on conn with the transport's settings, verifying
+// serverName unless --sni set another
+func (t *http10Transport) handshake(ctx context.Context, conn net.Conn, serverName string) (net.Conn, error) {
+	tlsConfig := &tls.Config{}
+	if t.base.TLSClientConfig != nil {
+		tlsConfig = t.base.TLSClientConfig.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = serverName
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// connectTunnel asks the proxy on conn to open a tunnel to addr
+func connectTunnel(conn net.Conn, addr string, proxyURL *url.URL) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
+	if auth := proxyAuthorization(proxyURL); auth != "" {
+		fmt.Fprintf(&buf, "Proxy-Authorization: %s\r\n", auth)
+	}
+	buf.WriteString("\r\n")
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write CONNECT request: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy refused the tunnel to %s: %s", addr, resp.Status)
+	}
+	return nil
+}
+
+// proxyAuthorization returns the Basic credentials in the proxy URL, if any
+func proxyAuthorization(proxyURL *url.URL) string {
+	if proxyURL.User == nil {
+		return ""
+	}
+	password, _ := proxyURL.User.Password()
+	credentials := proxyURL.User.Username() + ":" + password
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+// hostPort returns u's host with the scheme's default port filled in
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// writeHTTP10Request writes req and its body. Sent to proxyURL, the request
+// line has the full URL and the proxy's credentials are added.
+func writeHTTP10Request(w io.Writer, req *http.Request, body []byte, proxyURL *url.URL) error {
+	var buf bytes.Buffer
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	target := req.URL.RequestURI()
+	if proxyURL != nil {
+		target = req.URL.String()
+	}
+	fmt.Fprintf(&buf, "%s %s HTTP/1.0\r\n", req.Method, target)
+	fmt.Fprintf(&buf, "Host: %s\r\n", host)
+	if proxyURL != nil {
+		if auth := proxyAuthorization(proxyURL); auth != "" {
+			fmt.Fprintf(&buf, "Proxy-Authorization: %s\r\n", auth)
+		}
+	}
+	if req.Header.Get("User-Agent") == "" {
+		buf.WriteString("User-Agent: Go-http-client/1.0\r\n")
+	}
+
+	header := req.Header.Clone()
+	for _, key := range []string{"Host", "Connection", "Transfer-Encoding", "Content-Length"} {
+		header.Del(key)
+	}
+	if err := header.Write(&buf); err != nil {
+		return fmt.Errorf("failed to write request headers: %w", err)
+	}
+
+	if len(body) > 0 || methodExpectsBody(req.Method) {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(body))
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func methodExpectsBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case "POST", "PUT", "PATCH":
+		return true
+	}
+	return false
+}
+
+// connBody closes the underlying connection along with the response body
+type connBody struct {
+	io.ReadCloser
+	conn net.Conn
+	stop func() bool
+}
+
+func (b *connBody) Close() error {
+	b.stop()
+	err := b.ReadCloser.Close()
+	b.conn.Close()
+	return err
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type capturedRequest struct {
+	proto            string
+	close            bool
+	transferEncoding []string
+	contentLength    int64
+	body             string
+}
+
+func newInspectingServer(t *testing.T, captured *capturedRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*captured = capturedRequest{
+			proto:            r.Proto,
+			close:            r.Close,
+			transferEncoding: r.TransferEncoding,
+			contentLength:    r.ContentLength,
+			body:             string(body),
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// unknownLengthBody hides the length of the body so net/http would normally
+// fall back to chunked encoding
+func unknownLengthBody(s string) io.Reader {
+	return io.MultiReader(strings.NewReader(s))
+}
+
+func TestHTTP10Request(t *testing.T) {
+	var captured capturedRequest
+	server := newInspectingServer(t, &captured)
+
+	client, err := buildHTTPClient(Config{HTTPVersion: "1.0"})
+	if err != nil {
+		t.Fatalf("Failed to build client: %v", err)
+	}
+
+	req, _ := http.NewRequest("POST", server.URL, unknownLengthBody("hello"))
+	if err := setRequestProto(req, "1.0"); err != nil {
+		t.Fatalf("Failed to set protocol: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "ok" {
+		t.Errorf("Expected body 'ok', got %q", body)
+	}
+	if captured.proto != "HTTP/1.0" {
+		t.Errorf("Expected HTTP/1.0 on the wire, got %s", captured.proto)
+	}
+	if !captured.close {
+		t.Error("Expected HTTP/1.0 request to close the connection")
+	}
+	if len(captured.transferEncoding) != 0 {
+		t.Errorf("Expected no Transfer-Encoding, got %v", captured.transferEncoding)
+	}
+	if captured.contentLength != 5 || captured.body != "hello" {
+		t.Errorf("Expected 5 byte body 'hello', got %d bytes %q", captured.contentLength, captured.body)
+	}
+}
+
+func TestHTTP10RequestTLSState(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := buildHTTPClient(Config{HTTPVersion: "1.0", Insecure: true})
+	if err != nil {
+		t.Fatalf("Failed to build client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.TLS == nil || !resp.TLS.HandshakeComplete || len(resp.TLS.PeerCertificates) == 0 {
+		t.Fatalf("Expected the TLS connection state, got %+v", resp.TLS)
+	}
+	if !resp.TLS.PeerCertificates[0].Equal(server.Certificate()) {
+		t.Error("Expected the server's certificate")
+	}
+}
+
+func TestHTTP11RequestUsesChunkedForUnknownLength(t *testing.T) {
+	var captured capturedRequest
+	server := newInspectingServer(t, &captured)
+
+	client, err := buildHTTPClient(Config{HTTPVersion: "1.1"})
+	if err != nil {
+		t.Fatalf("Failed to build client: %v", err)
+	}
+
+	req, _ := http.NewRequest("POST", server.URL, unknownLengthBody("hello"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if captured.proto != "HTTP/1.1" {
+		t.Errorf("Expected HTTP/1.1 on the wire, got %s", captured.proto)
+	}
+	if captured.close {
+		t.Error("Expected HTTP/1.1 request to keep the connection alive")
+	}
+	if len(captured.transferEncoding) != 1 || captured.transferEncoding[0] != "chunked" {
+		t.Errorf("Expected chunked Transfer-Encoding, got %v", captured.transferEncoding)
+	}
+}
+
+func TestBuildHTTPClientRejectsUnknownVersion(t *testing.T) {
+	if _, err := buildHTTPClient(Config{HTTPVersion: "2.0"}); err == nil {
+		t.Error("Expected error for unsupported HTTP version")
+	}
+}
+
+func TestSNIOverride(t *testing.T) {
+	ca := newTestCert(t, "CA", nil, nil)
+	certs := map[string]tls.Certificate{
+		"a.example.test": newTestCert(t, "a.example.test", ca, nil).tlsCertificate(t),
+		"b.example.test": newTestCert(t, "b.example.test", ca, nil).tlsCertificate(t),
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, ok := certs[hello.ServerName]
+			if !ok {
+				cert = certs["a.example.test"]
+			}
+			return &cert, nil
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	for _, sni := range []string{"a.example.test", "b.example.test"} {
+		t.Run(sni, func(t *testing.T) {
+			client, err := buildHTTPClient(Config{URL: server.URL, SNI: sni})
+			if err != nil {
+				t.Fatalf("Failed to build client: %v", err)
+			}
+			client.Transport.(*http.Transport).TLSClientConfig.RootCAs = roots
+			client.Timeout = 5 * time.Second
+
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if got := resp.TLS.PeerCertificates[0].Subject.CommonName; got != sni {
+				t.Errorf("Expected certificate for %s, got %s", sni, got)
+			}
+		})
+	}
+}
+
+func TestSNIRequiresHTTPS(t *testing.T) {
+	if _, err := buildHTTPClient(Config{URL: "http://example.test", SNI: "example.test"}); err == nil {
+		t.Error("Expected --sni to be rejected for an http URL")
+	}
+}
+
+func TestTLSFlags(t *testing.T) {
+	ca := newTestCert(t, "CA", nil, nil)
+	serverCert := newTestCert(t, "localhost", ca, nil)
+	clientCert := newTestCert(t, "client", ca, nil)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := "none"
+		if len(r.TLS.PeerCertificates) > 0 {
+			client = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		fmt.Fprintf(w, "%s %s", tls.VersionName(r.TLS.Version), client)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate(t)},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0600)
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	clientCert.writeFiles(t, certFile, keyFile)
+
+	tests := []struct {
+		name      string
+		config    Config
+		expected  string
+		expectErr bool
+	}{
+		{"Untrusted by default", Config{}, "", true},
+		{"Insecure", Config{Insecure: true}, "TLS 1.3 none", false},
+		{"CA file", Config{CACert: caFile}, "TLS 1.3 none", false},
+		{"Client certificate", Config{CACert: caFile, ClientCert: certFile, ClientKey: keyFile}, "TLS 1.3 client", false},
+		{"Maximum version", Config{CACert: caFile, TLSMax: "1.2"}, "TLS 1.2 none", false},
+		{"Cipher suites", Config{CACert: caFile, TLSMax: "1.2", Ciphers: []string{"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"}}, "TLS 1.2 none", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.URL = server.URL
+			r, stdout, _ := newTestRequester(t, tt.config)
+
+			err := r.do(r.config.URL, true, r.printResponse)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("Expected the handshake to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if !strings.HasSuffix(stdout.String(), tt.expected) {
+				t.Errorf("Expected body %q, got %q", tt.expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestCompressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte("plain"))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte("decoded"))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"--compressed", server.URL}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "X-Accept-Encoding: gzip, deflate, br, zstd\n") {
+		t.Errorf("Expected every supported coding to be requested, got %q", output)
+	}
+	if !strings.Contains(output, "Content-Encoding: gzip\n") {
+		t.Errorf("Expected the original Content-Encoding in the headers, got %q", output)
+	}
+	if !strings.HasSuffix(output, "\n\ndecoded") {
+		t.Errorf("Expected a decompressed body, got %q", output)
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"--compressed", "-H", "Accept-Encoding: identity", server.URL}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	if !strings.HasSuffix(stdout.String(), "\n\nplain") {
+		t.Errorf("Expected -H Accept-Encoding to win, got %q", stdout.String())
+	}
+
+	if code := Run([]string{"--compressed", "--gzip-output", "-o", "out", server.URL}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d with --gzip-output, got %d", exitUsage, code)
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Host, r.URL.Path)
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{"Host from URL", Config{URL: "http://localhost/v1.43/containers/json"}, "localhost /v1.43/containers/json"},
+		{"Host header override", Config{URL: "http://localhost/_ping", Headers: []string{"Host: docker"}}, "docker /_ping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.UnixSocket = path
+			r, stdout, _ := newTestRequester(t, tt.config)
+			if err := r.do(r.config.URL, true, r.printResponse); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if !strings.HasSuffix(stdout.String(), tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// readURLFile returns the URLs listed in path, one per line. Blank lines
+// and lines starting with # are skipped.
+func readURLFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open URL file: %w", err)
+	}
+	defer file.Close()
+
+	var urls []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URL file: %w", err)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no URLs in %s", path)
+	}
+	return urls, nil
+}
+
+// batch sends the request to every URL in --url-file across --parallel
+// workers. With --output-dir each response body is saved there; otherwise
+// each response is printed whole under a "==> URL <==" line once it has
+// arrived, so concurrent responses don't interleave. A failed URL is
+// reported and the others still run.
+func (r *requester) batch() error {
+	urls, err := readURLFile(r.config.URLFile)
+	if err != nil {
+		return err
+	}
+
+	jobs := make(chan string, len(urls))
+	for _, u := range urls {
+		jobs <- u
+	}
+	close(jobs)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	failed := 0
+
+	for w := 0; w < max(1, min(r.config.Parallel, len(urls))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rawURL := range jobs {
+				if r.ctx.Err() != nil {
+					return
+				}
+
+				var output bytes.Buffer
+				err := r.batchRequest(rawURL, &output)
+
+				mutex.Lock()
+				if r.config.OutputDir == "" {
+					fmt.Fprintf(r.stdout, "==> %s <==\n", rawURL)
+					r.stdout.Write(output.Bytes())
+					if output.Len() > 0 && !bytes.HasSuffix(output.Bytes(), []byte("\n")) {
+						fmt.Fprintln(r.stdout)
+					}
+				}
+				if err != nil {
+					failed++
+					fmt.Fprintf(r.stderr, "Error: %s: %v\n", rawURL, err)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if r.ctx.Err() != nil {
+		return interruptedError(0)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d requests failed", failed, len(urls))
+	}
+	return nil
+}
+
+// batchRequest sends one --url-file request with its printed output going
+// to w. Download progress is left off since several bars would overwrite
+// each other, and the worker gets its own retry jitter source since
+// rand.Rand isn't safe to share between goroutines.
+func (r *requester) batchRequest(rawURL string, w *bytes.Buffer) error {
+	if r.config.BaseURL != "" {
+		joined, err := joinURL(r.config.BaseURL, rawURL)
+		if err != nil {
+			return err
+		}
+		rawURL = joined
+	}
+	if r.config.SNI != "" {
+		if err := checkSNIURL(rawURL); err != nil {
+			return err
+		}
+	}
+
+	worker := *r
+	worker.stdout = w
+	worker.config.NoProgress = true
+	worker.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	return worker.do(rawURL, true, worker.printResponse)
+}
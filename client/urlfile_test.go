@@ -0,0 +1,167 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestURLFile(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		io.WriteString(w, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Test")+" "+string(body))
+	}))
+	defer server.Close()
+
+	urls := writeCollection(t, "urls.txt", "# endpoints\n"+server.URL+"/a\n\n  "+server.URL+"/b  \n"+server.URL+"/c\n")
+
+	t.Run("Prints every response", func(t *testing.T) {
+		peak.Store(0)
+		var stdout, stderr strings.Builder
+		code := Run([]string{"--url-file", urls, "--parallel", "3", "-X", "POST", "-H", "X-Test: yes", "-d", "data"}, &stdout, &stderr)
+		if code != 0 {
+			t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+		}
+		for _, path := range []string{"/a", "/b", "/c"} {
+			if !strings.Contains(stdout.String(), "==> "+server.URL+path+" <==") {
+				t.Errorf("Expected a heading for %s, got %q", path, stdout.String())
+			}
+			if !strings.Contains(stdout.String(), "POST "+path+" yes data") {
+				t.Errorf("Expected the response for %s, got %q", path, stdout.String())
+			}
+		}
+		if peak.Load() < 2 {
+			t.Errorf("Expected requests to run in parallel, peak was %d", peak.Load())
+		}
+	})
+
+	t.Run("Sequential by default", func(t *testing.T) {
+		peak.Store(0)
+		var stdout, stderr strings.Builder
+		if code := Run([]string{"--url-file", urls}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+		}
+		if peak.Load() != 1 {
+			t.Errorf("Expected one request at a time, peak was %d", peak.Load())
+		}
+	})
+
+	t.Run("Base URL", func(t *testing.T) {
+		paths := writeCollection(t, "paths.txt", "/a\n/b\n")
+		var stdout, stderr strings.Builder
+		if code := Run([]string{"--url-file", paths, "--base-url", server.URL}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "GET /b") {
+			t.Errorf("Expected the joined URL to be requested, got %q", stdout.String())
+		}
+	})
+
+	t.Run("Output directory", func(t *testing.T) {
+		dir := t.TempDir()
+		var stdout, stderr strings.Builder
+		if code := Run([]string{"--url-file", urls, "--parallel", "2", "--output-dir", dir}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d (stderr %q)", code, stderr.String())
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("Expected %s to be saved: %v", name, err)
+			}
+			if string(data) != "GET /"+name+"  " {
+				t.Errorf("Expected the body of /%s, got %q", name, data)
+			}
+		}
+	})
+
+	t.Run("Failures don't stop the batch", func(t *testing.T) {
+		mixed := writeCollection(t, "mixed.txt", server.URL+"/missing\n"+server.URL+"/a\n")
+		var stdout, stderr strings.Builder
+		code := Run([]string{"--url-file", mixed, "--fail"}, &stdout, &stderr)
+		if code != exitFailure {
+			t.Fatalf("Expected exit code %d, got %d", exitFailure, code)
+		}
+		if !strings.Contains(stdout.String(), "GET /a") {
+			t.Errorf("Expected the other URL to still be requested, got %q", stdout.String())
+		}
+		for _, want := range []string{server.URL + "/missing", "1 of 2 requests failed"} {
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("Expected %q in stderr, got %q", want, stderr.String())
+			}
+		}
+	})
+}
+
+func TestURLFileSNI(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.TLS.ServerName)
+	}))
+	defer server.Close()
+
+	plain := strings.Replace(server.URL, "https://", "http://", 1)
+	urls := writeCollection(t, "urls.txt", server.URL+"/a\n"+plain+"/b\n")
+
+	var stdout, stderr strings.Builder
+	code := Run([]string{"--url-file", urls, "--sni", "api.example.test", "--insecure", "--body-only"}, &stdout, &stderr)
+	if code != exitFailure {
+		t.Fatalf("Expected exit code %d, got %d (stderr %q)", exitFailure, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "api.example.test") {
+		t.Errorf("Expected the https URL to be sent with the SNI name, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), plain+"/b: --sni requires an https URL") {
+		t.Errorf("Expected the http URL to be rejected, got %q", stderr.String())
+	}
+}
+
+func TestURLFileFlags(t *testing.T) {
+	urls := writeCollection(t, "urls.txt", "http://example.com\n")
+	empty := writeCollection(t, "empty.txt", "# nothing\n\n")
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"URL argument", []string{"--url-file", urls, "http://example.com"}, exitUsage, "cannot be combined with a URL argument"},
+		{"Single output file", []string{"--url-file", urls, "-o", "out"}, exitUsage, "use --output-dir"},
+		{"Body from stdin", []string{"--url-file", urls, "-d", "-"}, exitUsage, "body read from stdin"},
+		{"Invalid parallel", []string{"--url-file", urls, "--parallel", "0"}, exitUsage, "--parallel must be at least 1"},
+		{"Empty file", []string{"--url-file", empty}, exitFailure, "no URLs in"},
+		{"Missing file", []string{"--url-file", filepath.Join(t.TempDir(), "none.txt")}, exitFailure, "failed to open URL file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if code := Run(tt.args, &stdout, &stderr); code != tt.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr %q)", tt.code, code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("Expected %q in stderr, got %q", tt.want, stderr.String())
+			}
+		})
+	}
+}
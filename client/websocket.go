@@ -0,0 +1,299 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"http-client/response"
+)
+
+// wsCloseTimeout is how long to wait for the server to answer a close frame
+const wsCloseTimeout = time.Second
+
+// wsSession is one WebSocket connection. Only the sender writes messages;
+// control frames (pings, pongs and close) are safe to send alongside it.
+type wsSession struct {
+	r       *requester
+	conn    *websocket.Conn
+	logMu   sync.Mutex
+	closing atomic.Bool
+}
+
+// websocket upgrades the connection to the URL and exchanges messages until
+// either side closes it. Messages come from -d, or from stdin one per line,
+// and incoming messages are printed to stdout as they arrive.
+func (r *requester) websocket(stdin io.Reader) error {
+	conn, err := r.dialWebSocket()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	s := &wsSession{r: r, conn: conn}
+	conn.SetPingHandler(func(data string) error {
+		s.logf("< ping %q", data)
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(wsCloseTimeout))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return nil
+		}
+		return err
+	})
+	conn.SetPongHandler(func(data string) error {
+		s.logf("< pong %q", data)
+		return nil
+	})
+
+	received := make(chan error, 1)
+	go func() {
+		received <- s.receive()
+	}()
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- s.send(stdin)
+	}()
+
+	var ping <-chan time.Time
+	if r.config.WSPing > 0 {
+		ticker := time.NewTicker(r.config.WSPing)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case err := <-received:
+			return err
+		case err := <-sent:
+			sent = nil
+			if err != nil {
+				s.close(websocket.CloseInternalServerErr)
+				return err
+			}
+			// With no count to wait for, the end of the input ends the session
+			if r.config.WSMessages == 0 {
+				s.close(r.config.WSCloseCode)
+			}
+		case <-ping:
+			s.logf("> ping")
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsCloseTimeout)); err != nil {
+				return fmt.Errorf("failed to send ping: %w", err)
+			}
+		case <-r.ctx.Done():
+			s.close(websocket.CloseGoingAway)
+			return interruptedError(0)
+		}
+	}
+}
+
+// dialWebSocket performs the upgrade handshake with the same headers,
+// query parameters, authentication and connection settings as an HTTP
+// request. http and https URLs are taken to mean ws and wss.
+func (r *requester) dialWebSocket() (*websocket.Conn, error) {
+	wsURL, err := webSocketURL(r.config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	addHeaders(req, r.config.Headers)
+	addQueryParams(req, r.config.Query)
+	if r.authenticator != nil {
+		if err := r.authenticator.Apply(req); err != nil {
+			return nil, fmt.Errorf("failed to apply authentication: %w", err)
+		}
+	}
+	if r.signer != nil {
+		if err := signRequest(r.signer, req); err != nil {
+			return nil, err
+		}
+	}
+	if req.Host != "" && req.Host != req.URL.Host {
+		req.Header.Set("Host", req.Host)
+	}
+
+	base, err := buildTransport(r.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	dialer := &websocket.Dialer{
+		NetDialContext:   base.DialContext,
+		Proxy:            base.Proxy,
+		TLSClientConfig:  base.TLSClientConfig,
+		HandshakeTimeout: r.config.Timeout,
+		Jar:              r.client.Jar,
+	}
+
+	if r.config.Verbose {
+		printRequestHead(r.stderr, req)
+	}
+
+	conn, resp, err := dialer.DialContext(r.ctx, req.URL.String(), req.Header)
+	if resp != nil && r.config.Verbose {
+		fmt.Fprintf(r.stderr, "< %s %s\n", resp.Proto, resp.Status)
+		for _, key := range slices.Sorted(maps.Keys(resp.Header)) {
+			for _, value := range resp.Header[key] {
+				fmt.Fprintf(r.stderr, "< %s: %s\n", key, value)
+			}
+		}
+		fmt.Fprintln(r.stderr, "<")
+	}
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+			return nil, fmt.Errorf("websocket handshake failed: the server returned %s", resp.Status)
+		}
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return conn, nil
+}
+
+// webSocketURL maps http and https URLs onto ws and wss
+func webSocketURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported WebSocket URL scheme %q", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// send writes the -d message, or each line of stdin, as a text message (or
+// binary with --ws-binary)
+func (s *wsSession) send(stdin io.Reader) error {
+	messageType := websocket.TextMessage
+	if s.r.config.WSBinary {
+		messageType = websocket.BinaryMessage
+	}
+
+	if data := s.r.config.Data; data != "" && data != "-" {
+		return s.write(messageType, []byte(data))
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if err := s.write(messageType, scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return nil
+}
+
+// write sends a message unless the closing handshake has started
+func (s *wsSession) write(messageType int, data []byte) error {
+	if s.closing.Load() {
+		return nil
+	}
+	if err := s.conn.WriteMessage(messageType, data); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// close starts the closing handshake with code, giving the server
+// wsCloseTimeout to answer before the read loop gives up
+func (s *wsSession) close(code int) {
+	if !s.closing.CompareAndSwap(false, true) {
+		return
+	}
+	s.logf("> close %d", code)
+	deadline := time.Now().Add(wsCloseTimeout)
+	s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""), deadline)
+	s.conn.SetReadDeadline(deadline)
+}
+
+// receive prints incoming messages until the connection closes. A close
+// with a code other than normal closure or going away is an error.
+func (s *wsSession) receive() error {
+	for count := 1; ; count++ {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			var netErr net.Error
+			switch {
+			case errors.As(err, &closeErr):
+				s.logf("< close %d %s", closeErr.Code, closeErr.Text)
+				if s.closing.Load() || closeErr.Code == websocket.CloseNormalClosure || closeErr.Code == websocket.CloseGoingAway {
+					return nil
+				}
+				return fmt.Errorf("websocket closed with code %d: %s", closeErr.Code, closeErr.Text)
+			case s.closing.Load() && errors.As(err, &netErr) && netErr.Timeout():
+				// The server never answered our close frame
+				return nil
+			}
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		// Messages that arrive while the close is acknowledged are dropped
+		limit := s.r.config.WSMessages
+		if limit > 0 && count > limit {
+			continue
+		}
+		if err := s.print(messageType, data); err != nil {
+			return err
+		}
+		if limit > 0 && count == limit {
+			s.close(s.r.config.WSCloseCode)
+		}
+	}
+}
+
+// print writes a message to stdout. Text messages get a newline, and JSON
+// ones are indented (and colored) like response bodies; binary messages are
+// written as they are.
+func (s *wsSession) print(messageType int, data []byte) error {
+	config := s.r.config
+	if messageType == websocket.TextMessage {
+		if config.PrettyPrint && json.Valid(data) {
+			var indented bytes.Buffer
+			if json.Indent(&indented, data, "", "  ") == nil {
+				data = indented.Bytes()
+			}
+		}
+		if config.Colorize && json.Valid(data) {
+			data = response.ColorizeJSON(data)
+		}
+		data = append(data, '\n')
+	}
+	if _, err := s.r.stdout.Write(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// logf reports frames and control messages on stderr with --verbose
+func (s *wsSession) logf(format string, args ...any) {
+	if s.r.config.Verbose {
+		s.logMu.Lock()
+		defer s.logMu.Unlock()
+		fmt.Fprintf(s.r.stderr, format+"\n", args...)
+	}
+}
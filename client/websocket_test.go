@@ -0,0 +1,204 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newWebSocketServer upgrades every request and hands the connection to
+// serve, after checking the handshake carried the client's headers
+func newWebSocketServer(t *testing.T, serve func(*websocket.Conn)) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" || r.Header.Get("X-Test") != "yes" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(conn)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func echo(conn *websocket.Conn) {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(messageType, append([]byte("echo: "), data...)); err != nil {
+			return
+		}
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	tests := []struct {
+		name   string
+		serve  func(*websocket.Conn)
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "Echo",
+			serve:  echo,
+			args:   []string{"-d", "hello"},
+			stdout: "echo: hello\n",
+		},
+		{
+			name: "Message count",
+			serve: func(conn *websocket.Conn) {
+				for _, message := range []string{"one", "two", "three"} {
+					conn.WriteMessage(websocket.TextMessage, []byte(message))
+				}
+				conn.ReadMessage()
+			},
+			args:   []string{"--ws-messages", "2", "-d", "start"},
+			stdout: "one\ntwo\n",
+		},
+		{
+			name: "Server closes normally",
+			serve: func(conn *websocket.Conn) {
+				conn.WriteMessage(websocket.TextMessage, []byte(`{"done":true}`))
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "bye"))
+				conn.ReadMessage()
+			},
+			args:   []string{"--ws-messages", "5", "--raw", "-d", "start"},
+			stdout: "{\"done\":true}\n",
+		},
+		{
+			name: "Server closes with an error",
+			serve: func(conn *websocket.Conn) {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "boom"))
+				conn.ReadMessage()
+			},
+			args:   []string{"--ws-messages", "1", "-d", "start"},
+			code:   exitFailure,
+			stderr: "websocket closed with code 1011: boom",
+		},
+		{
+			name:   "Verbose",
+			serve:  echo,
+			args:   []string{"-v", "-d", "hi"},
+			stdout: "echo: hi\n",
+			stderr: "< HTTP/1.1 101 Switching Protocols",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newWebSocketServer(t, tt.serve)
+			args := append([]string{"ws", "--bearer", "s3cret", "-H", "X-Test: yes"}, tt.args...)
+
+			var stdout, stderr strings.Builder
+			code := Run(append(args, server.URL), &stdout, &stderr)
+			if code != tt.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr %q)", tt.code, code, stderr.String())
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("Expected stdout %q, got %q", tt.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected %q in stderr, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestWebSocketStdin(t *testing.T) {
+	server := newWebSocketServer(t, echo)
+
+	r, stdout, _ := newTestRequester(t, Config{
+		URL:         strings.Replace(server.URL, "http://", "ws://", 1),
+		Headers:     []string{"X-Test: yes"},
+		BearerToken: "s3cret",
+		WSMessages:  2,
+		WSCloseCode: websocket.CloseNormalClosure,
+	})
+
+	if err := r.websocket(strings.NewReader("first\nsecond\n")); err != nil {
+		t.Fatalf("WebSocket session failed: %v", err)
+	}
+	if stdout.String() != "echo: first\necho: second\n" {
+		t.Errorf("Expected both lines to be echoed, got %q", stdout.String())
+	}
+}
+
+func TestWebSocketPing(t *testing.T) {
+	var pings atomic.Int32
+	server := newWebSocketServer(t, func(conn *websocket.Conn) {
+		conn.SetPingHandler(func(data string) error {
+			if pings.Add(1) == 2 {
+				conn.WriteMessage(websocket.TextMessage, []byte("enough"))
+			}
+			return nil
+		})
+		conn.ReadMessage()
+	})
+
+	r, stdout, stderr := newTestRequester(t, Config{
+		URL:         server.URL,
+		Headers:     []string{"X-Test: yes"},
+		BearerToken: "s3cret",
+		WSMessages:  1,
+		WSPing:      20 * time.Millisecond,
+		WSCloseCode: websocket.CloseNormalClosure,
+		Verbose:     true,
+	})
+
+	if err := r.websocket(strings.NewReader("")); err != nil {
+		t.Fatalf("WebSocket session failed: %v", err)
+	}
+	if stdout.String() != "enough\n" {
+		t.Errorf("Expected the message sent after two pings, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "> ping") {
+		t.Errorf("Expected pings to be logged, got %q", stderr.String())
+	}
+}
+
+func TestWebSocketHandshakeFailure(t *testing.T) {
+	server := newWebSocketServer(t, echo)
+
+	var stdout, stderr strings.Builder
+	if code := Run([]string{"ws", "-d", "hi", server.URL}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("Expected exit code %d, got %d", exitFailure, code)
+	}
+	if !strings.Contains(stderr.String(), "websocket handshake failed: the server returned 401 Unauthorized") {
+		t.Errorf("Expected handshake error, got %q", stderr.String())
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"http://a.test/socket", "ws://a.test/socket"},
+		{"https://a.test/socket?x=1", "wss://a.test/socket?x=1"},
+		{"wss://a.test", "wss://a.test"},
+	}
+
+	for _, tt := range tests {
+		if got, err := webSocketURL(tt.url); err != nil || got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q (%v)", tt.expected, tt.url, got, err)
+		}
+	}
+	if _, err := webSocketURL("ftp://a.test"); err == nil {
+		t.Error("Expected error for an ftp URL")
+	}
+}
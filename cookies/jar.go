@@ -0,0 +1,277 @@
+package cookies
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// now is replaced in tests to control the clock
+var now = time.Now
+
+// Jar is an http.CookieJar that remembers every cookie it's given so they
+// can be written to a file and loaded by a later run. Matching cookies to
+// requests is left to net/http/cookiejar.
+type Jar struct {
+	jar *cookiejar.Jar
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// entry is a stored cookie along with the URL whose response set it, which
+// is needed to replay it into a fresh jar with the same scope
+type entry struct {
+	URL      string     `json:"url"`
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain,omitempty"`
+	Path     string     `json:"path,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+	HttpOnly bool       `json:"http_only,omitempty"`
+	SameSite string     `json:"same_site,omitempty"`
+}
+
+// New returns an empty Jar
+func New() *Jar {
+	// cookiejar.New only fails for a bad public suffix list, and none is given
+	jar, _ := cookiejar.New(nil)
+	return &Jar{jar: jar, entries: make(map[string]entry)}
+}
+
+// Load returns a Jar holding the cookies saved in path by Save. Cookies
+// that have expired since are dropped.
+func Load(path string) (*Jar, error) {
+	j := New()
+	if err := j.LoadFile(path); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// LoadFile adds the cookies saved in path by Save to the jar, replacing
+// cookies it already holds with the same name and scope
+func (j *Jar) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read cookie file: %w", err)
+	}
+
+	var entries []entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse cookie file %s: %w", path, err)
+	}
+
+	for _, e := range entries {
+		u, err := url.Parse(e.URL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid URL %q in cookie file %s", e.URL, path)
+		}
+		j.SetCookies(u, []*http.Cookie{e.cookie()})
+	}
+	return nil
+}
+
+// Save writes the jar's unexpired cookies, session cookies included, to
+// path as JSON. The file is only readable by its owner since it usually
+// holds credentials.
+func (j *Jar) Save(path string) error {
+	j.mu.Lock()
+	entries := make([]entry, 0, len(j.entries))
+	current := now()
+	for _, e := range j.entries {
+		if e.Expires == nil || e.Expires.After(current) {
+			entries = append(entries, e)
+		}
+	}
+	j.mu.Unlock()
+
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].URL != entries[b].URL {
+			return entries[a].URL < entries[b].URL
+		}
+		return entries[a].Name < entries[b].Name
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cookies: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	return nil
+}
+
+// SetCookies implements http.CookieJar
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, cookie := range cookies {
+		e := newEntry(u, cookie)
+		key := e.key(u)
+		if cookie.MaxAge < 0 || (e.Expires != nil && !e.Expires.After(now())) {
+			delete(j.entries, key)
+			continue
+		}
+		if j.accepted(u, e) {
+			j.entries[key] = e
+		}
+	}
+}
+
+// accepted reports whether the wrapped jar kept the cookie in e, which it
+// doesn't when e.g. its Domain isn't the host that set it or a parent of it
+func (j *Jar) accepted(u *url.URL, e entry) bool {
+	probe := &url.URL{Scheme: "https", Host: u.Host, Path: e.Path}
+	if domain := strings.TrimPrefix(e.Domain, "."); domain != "" {
+		probe.Host = domain
+	}
+	if probe.Path == "" || probe.Path[0] != '/' {
+		probe.Path = defaultPath(u.Path)
+	}
+
+	for _, cookie := range j.jar.Cookies(probe) {
+		if cookie.Name == e.Name && cookie.Value == e.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// Cookies implements http.CookieJar
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// Stored returns the cookies the jar would send to u, as Cookies does, but
+// with the attributes they were set with
+func (j *Jar) Stored(u *url.URL) []*http.Cookie {
+	sent := j.jar.Cookies(u)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	keys := make([]string, 0, len(j.entries))
+	for key := range j.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stored := make([]*http.Cookie, len(sent))
+	for i, cookie := range sent {
+		stored[i] = cookie
+		for _, key := range keys {
+			if e := j.entries[key]; e.Name == cookie.Name && e.Value == cookie.Value && e.matches(u) {
+				stored[i] = e.cookie()
+				break
+			}
+		}
+	}
+	return stored
+}
+
+// matches reports whether the entry's domain covers u's host
+func (e entry) matches(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	domain := strings.ToLower(strings.TrimPrefix(e.Domain, "."))
+	if domain == "" {
+		setBy, err := url.Parse(e.URL)
+		return err == nil && strings.EqualFold(setBy.Hostname(), host)
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func newEntry(u *url.URL, cookie *http.Cookie) entry {
+	e := entry{
+		URL:      (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(),
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+	}
+	if !cookie.Expires.IsZero() {
+		expires := cookie.Expires.UTC()
+		e.Expires = &expires
+	}
+
+	// Max-Age is relative to when the cookie was set, so it's stored as the
+	// absolute time it works out to
+	if cookie.MaxAge > 0 {
+		expires := now().Add(time.Duration(cookie.MaxAge) * time.Second).UTC().Truncate(time.Second)
+		e.Expires = &expires
+	}
+
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		e.SameSite = "Lax"
+	case http.SameSiteStrictMode:
+		e.SameSite = "Strict"
+	case http.SameSiteNoneMode:
+		e.SameSite = "None"
+	}
+	return e
+}
+
+// key identifies a cookie the way a jar does: by name, domain and path. A
+// host-only cookie's domain is the host that set it.
+func (e entry) key(u *url.URL) string {
+	domain := strings.ToLower(strings.TrimPrefix(e.Domain, "."))
+	if domain == "" {
+		domain = u.Hostname()
+	}
+	path := e.Path
+	if path == "" {
+		path = defaultPath(u.Path)
+	}
+	return e.Name + ";" + domain + ";" + path
+}
+
+func (e entry) cookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     e.Name,
+		Value:    e.Value,
+		Domain:   e.Domain,
+		Path:     e.Path,
+		Secure:   e.Secure,
+		HttpOnly: e.HttpOnly,
+	}
+	if e.Expires != nil {
+		cookie.Expires = *e.Expires
+	}
+	switch e.SameSite {
+	case "Lax":
+		cookie.SameSite = http.SameSiteLaxMode
+	case "Strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "None":
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
+}
+
+// defaultPath is the cookie path a response for urlPath gets when it sets
+// none, per RFC 6265 section 5.1.4
+func defaultPath(urlPath string) string {
+	if urlPath == "" || urlPath[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(urlPath, "/")
+	if i == 0 {
+		return "/"
+	}
+	return urlPath[:i]
+}
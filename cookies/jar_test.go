@@ -0,0 +1,175 @@
+package cookies
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func cookieNames(cookies []*http.Cookie) map[string]string {
+	names := make(map[string]string)
+	for _, cookie := range cookies {
+		names[cookie.Name] = cookie.Value
+	}
+	return names
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	login := mustParse(t, "https://example.com/auth/login")
+
+	jar := New()
+	jar.SetCookies(login, []*http.Cookie{
+		{Name: "session", Value: "abc", Path: "/", HttpOnly: true},
+		{Name: "scoped", Value: "1"},
+		{Name: "remember", Value: "yes", Path: "/", MaxAge: 3600},
+		{Name: "shared", Value: "s", Domain: "example.com", Path: "/"},
+	})
+	if err := jar.Save(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("Expected cookie file mode 0600, got %o", perm)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		url      string
+		expected map[string]string
+	}{
+		{"https://example.com/users", map[string]string{"session": "abc", "remember": "yes", "shared": "s"}},
+		{"https://example.com/auth/refresh", map[string]string{"session": "abc", "remember": "yes", "shared": "s", "scoped": "1"}},
+		{"https://api.example.com/", map[string]string{"shared": "s"}},
+		{"https://other.com/", map[string]string{}},
+	}
+	for _, tt := range tests {
+		got := cookieNames(loaded.Cookies(mustParse(t, tt.url)))
+		if len(got) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.url, tt.expected, got)
+			continue
+		}
+		for name, value := range tt.expected {
+			if got[name] != value {
+				t.Errorf("%s: expected %s=%s, got %v", tt.url, name, value, got)
+			}
+		}
+	}
+}
+
+func TestSaveDropsExpiredAndDeletedCookies(t *testing.T) {
+	current := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	u := mustParse(t, "https://example.com/")
+	jar := New()
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "short", Value: "1", MaxAge: 60},
+		{Name: "gone", Value: "1"},
+		{Name: "kept", Value: "1"},
+	})
+	jar.SetCookies(u, []*http.Cookie{{Name: "gone", MaxAge: -1}})
+
+	current = current.Add(time.Hour)
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := jar.Save(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := cookieNames(loaded.Cookies(u))
+	if len(got) != 1 || got["kept"] != "1" {
+		t.Errorf("Expected only the kept cookie, got %v", got)
+	}
+}
+
+func TestSaveSkipsRejectedCookies(t *testing.T) {
+	jar := New()
+	jar.SetCookies(mustParse(t, "https://api.example.com/"), []*http.Cookie{
+		{Name: "kept", Value: "1", Domain: "example.com", Path: "/"},
+		{Name: "foreign", Value: "1", Domain: "other.com", Path: "/"},
+		{Name: "sibling", Value: "1", Domain: "www.example.com", Path: "/"},
+	})
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := jar.Save(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"foreign", "sibling"} {
+		if strings.Contains(string(data), name) {
+			t.Errorf("Expected the %s cookie the jar rejected not to be saved, got %s", name, data)
+		}
+	}
+	if !strings.Contains(string(data), `"kept"`) {
+		t.Errorf("Expected the accepted cookie to be saved, got %s", data)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalid, []byte("not json"), 0o600)
+	badURL := filepath.Join(dir, "bad-url.json")
+	os.WriteFile(badURL, []byte(`[{"url":"/relative","name":"a","value":"b"}]`), 0o600)
+
+	for _, path := range []string{filepath.Join(dir, "missing.json"), invalid, badURL} {
+		if _, err := Load(path); err == nil {
+			t.Errorf("Expected loading %s to fail", filepath.Base(path))
+		}
+	}
+}
+
+func TestStored(t *testing.T) {
+	jar := New()
+	jar.SetCookies(mustParse(t, "https://example.com/login"), []*http.Cookie{
+		{Name: "session", Value: "abc", Path: "/", HttpOnly: true, Secure: true},
+		{Name: "shared", Value: "s", Domain: "example.com", Path: "/"},
+		{Name: "admin", Value: "yes", Path: "/admin"},
+	})
+	jar.SetCookies(mustParse(t, "https://other.example.com/"), []*http.Cookie{
+		{Name: "session", Value: "other", Path: "/"},
+	})
+
+	stored := jar.Stored(mustParse(t, "https://example.com/home"))
+	if names := cookieNames(stored); len(names) != 2 || names["session"] != "abc" || names["shared"] != "s" {
+		t.Fatalf("Expected the session and shared cookies, got %v", names)
+	}
+	for _, cookie := range stored {
+		if cookie.Name == "session" && (!cookie.HttpOnly || !cookie.Secure || cookie.Path != "/") {
+			t.Errorf("Expected the session cookie's attributes, got %+v", cookie)
+		}
+		if cookie.Name == "shared" && cookie.Domain != "example.com" {
+			t.Errorf("Expected the shared cookie's domain, got %+v", cookie)
+		}
+	}
+}
@@ -4,4 +4,11 @@ go 1.23.0
 
 toolchain go1.24.6
 
-require golang.org/x/time v0.12.0
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/klauspost/compress v1.18.0
+	golang.org/x/time v0.12.0
+	google.golang.org/protobuf v1.36.11
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -1,347 +1,11 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
-	"context"
-	"flag"
-	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
-	"net/url"
 	"os"
-	"path/filepath"
-	"strings"
-	"time"
-	
-	"http-client/auth"
-	"http-client/ratelimit"
-	"http-client/response"
-)
-
-type Config struct {
-	Method         string
-	URL            string
-	Headers        []string
-	Query          []string
-	Data           string
-	Form           []string
-	Timeout        time.Duration
-	Username       string
-	Password       string
-	BearerToken    string
-	ClientID       string
-	ClientSecret   string
-	TokenURL       string
-	Scopes         []string
-	CustomHeader   string
-	CustomValue    string
-	PrettyPrint    bool
-	RateLimit      string
-}
-
-type HeaderList []string
-
-func (h *HeaderList) String() string {
-	return strings.Join(*h, ", ")
-}
-
-func (h *HeaderList) Set(value string) error {
-	*h = append(*h, value)
-	return nil
-}
-
-type QueryList []string
-
-func (q *QueryList) String() string {
-	return strings.Join(*q, ", ")
-}
-
-func (q *QueryList) Set(value string) error {
-	*q = append(*q, value)
-	return nil
-}
-
-type FormList []string
-
-func (f *FormList) String() string {
-	return strings.Join(*f, ", ")
-}
 
-func (f *FormList) Set(value string) error {
-	*f = append(*f, value)
-	return nil
-}
-
-type ScopeList []string
-
-func (s *ScopeList) String() string {
-	return strings.Join(*s, " ")
-}
-
-func (s *ScopeList) Set(value string) error {
-	*s = append(*s, value)
-	return nil
-}
+	"http-client/client"
+)
 
 func main() {
-	var config Config
-	var headers HeaderList
-	var queries QueryList
-	var forms FormList
-	var scopes ScopeList
-
-	flag.StringVar(&config.Method, "X", "GET", "HTTP method")
-	flag.StringVar(&config.Method, "method", "GET", "HTTP method")
-	flag.Var(&headers, "H", "Header in 'Key: Value' format")
-	flag.Var(&headers, "header", "Header in 'Key: Value' format")
-	flag.Var(&queries, "q", "Query parameter in 'key=value' format")
-	flag.Var(&queries, "query", "Query parameter in 'key=value' format")
-	flag.StringVar(&config.Data, "d", "", "Request data (string, @filename, or - for stdin)")
-	flag.StringVar(&config.Data, "data", "", "Request data (string, @filename, or - for stdin)")
-	flag.Var(&forms, "f", "Form data in 'key=value' or 'key=@filename' format")
-	flag.Var(&forms, "form", "Form data in 'key=value' or 'key=@filename' format")
-	flag.DurationVar(&config.Timeout, "t", 30*time.Second, "Request timeout")
-	flag.DurationVar(&config.Timeout, "timeout", 30*time.Second, "Request timeout")
-	
-	flag.StringVar(&config.Username, "u", "", "Username for basic authentication (use with --password)")
-	flag.StringVar(&config.Username, "user", "", "Username for basic authentication (use with --password)")
-	flag.StringVar(&config.Password, "p", "", "Password for basic authentication")
-	flag.StringVar(&config.Password, "password", "", "Password for basic authentication")
-	flag.StringVar(&config.BearerToken, "b", "", "Bearer token for authentication")
-	flag.StringVar(&config.BearerToken, "bearer", "", "Bearer token for authentication")
-	flag.StringVar(&config.ClientID, "client-id", "", "OAuth2 client ID for client credentials flow")
-	flag.StringVar(&config.ClientSecret, "client-secret", "", "OAuth2 client secret for client credentials flow")
-	flag.StringVar(&config.TokenURL, "token-url", "", "OAuth2 token endpoint URL")
-	flag.Var(&scopes, "scope", "OAuth2 scope (can be used multiple times)")
-	flag.StringVar(&config.CustomHeader, "auth-header", "", "Custom authentication header name")
-	flag.StringVar(&config.CustomValue, "auth-value", "", "Custom authentication header value")
-	flag.BoolVar(&config.PrettyPrint, "pretty", false, "Pretty-print JSON and XML responses")
-	flag.StringVar(&config.RateLimit, "rate", "", "Rate limit in format 'requests/duration' (e.g., '10/s', '100/30s')")
-	flag.StringVar(&config.RateLimit, "r", "", "Rate limit in format 'requests/duration' (e.g., '10/s', '100/30s')")
-
-	flag.Parse()
-
-	if flag.NArg() < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] URL\n", os.Args[0])
-		flag.PrintDefaults()
-		os.Exit(1)
-	}
-
-	config.URL = flag.Arg(0)
-	config.Headers = headers
-	config.Query = queries
-	config.Form = forms
-	config.Scopes = scopes
-
-	if err := makeRequest(config); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	os.Exit(client.Run(os.Args[1:], os.Stdout, os.Stderr))
 }
-
-func makeRequest(config Config) error {
-	// Initialize rate limiter if specified
-	rateLimiter, err := ratelimit.New(config.RateLimit)
-	if err != nil {
-		return fmt.Errorf("failed to create rate limiter: %w", err)
-	}
-
-	parsedURL, err := url.Parse(config.URL)
-	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
-	}
-
-	var body io.Reader
-	var contentType string
-
-	if len(config.Form) > 0 {
-		body, contentType, err = buildFormData(config.Form)
-		if err != nil {
-			return fmt.Errorf("failed to build form data: %w", err)
-		}
-	} else if config.Data != "" {
-		body, err = buildRequestBody(config.Data)
-		if err != nil {
-			return fmt.Errorf("failed to build request body: %w", err)
-		}
-	}
-
-	req, err := http.NewRequest(config.Method, parsedURL.String(), body)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
-	}
-
-	addHeaders(req, config.Headers)
-	addQueryParams(req, config.Query)
-	
-	authenticator, err := auth.NewAuthenticator(auth.Config{
-		Username:     config.Username,
-		Password:     config.Password,
-		BearerToken:  config.BearerToken,
-		ClientID:     config.ClientID,
-		ClientSecret: config.ClientSecret,
-		TokenURL:     config.TokenURL,
-		Scopes:       config.Scopes,
-		CustomHeader: config.CustomHeader,
-		CustomValue:  config.CustomValue,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create authenticator: %w", err)
-	}
-	
-	if authenticator != nil {
-		if err := authenticator.Apply(req); err != nil {
-			return fmt.Errorf("failed to apply authentication: %w", err)
-		}
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
-	defer cancel()
-	req = req.WithContext(ctx)
-
-	// Apply rate limiting
-	if rateLimiter.IsEnabled() {
-		if err := rateLimiter.Wait(ctx); err != nil {
-			return fmt.Errorf("rate limit wait failed: %w", err)
-		}
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	fmt.Printf("%s %s\n", resp.Proto, resp.Status)
-	for key, values := range resp.Header {
-		for _, value := range values {
-			fmt.Printf("%s: %s\n", key, value)
-		}
-	}
-	fmt.Println()
-
-	var formatter response.Formatter
-	if config.PrettyPrint {
-		formatter = response.NewPrettyFormatter()
-	} else {
-		formatter = response.NewRawFormatter()
-	}
-
-	formattedBody, err := formatter.Format(resp)
-	if err != nil {
-		return fmt.Errorf("failed to format response: %w", err)
-	}
-
-	fmt.Print(string(formattedBody))
-	return nil
-}
-
-func buildRequestBody(data string) (io.Reader, error) {
-	if data == "" {
-		return nil, nil
-	}
-
-	if data == "-" {
-		scanner := bufio.NewScanner(os.Stdin)
-		var lines []string
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
-		}
-		if err := scanner.Err(); err != nil {
-			return nil, fmt.Errorf("failed to read from stdin: %w", err)
-		}
-		return strings.NewReader(strings.Join(lines, "\n")), nil
-	}
-
-	if strings.HasPrefix(data, "@") {
-		filename := data[1:]
-		file, err := os.Open(filename)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
-		}
-		defer file.Close()
-
-		content, err := io.ReadAll(file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
-		}
-		return bytes.NewReader(content), nil
-	}
-
-	return strings.NewReader(data), nil
-}
-
-func buildFormData(forms []string) (io.Reader, string, error) {
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-
-	for _, form := range forms {
-		parts := strings.SplitN(form, "=", 2)
-		if len(parts) != 2 {
-			return nil, "", fmt.Errorf("invalid form data format: %s", form)
-		}
-
-		key := parts[0]
-		value := parts[1]
-
-		if strings.HasPrefix(value, "@") {
-			filename := value[1:]
-			file, err := os.Open(filename)
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to open file %s: %w", filename, err)
-			}
-			defer file.Close()
-
-			part, err := writer.CreateFormFile(key, filepath.Base(filename))
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to create form file: %w", err)
-			}
-
-			_, err = io.Copy(part, file)
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to copy file content: %w", err)
-			}
-		} else {
-			err := writer.WriteField(key, value)
-			if err != nil {
-				return nil, "", fmt.Errorf("failed to write form field: %w", err)
-			}
-		}
-	}
-
-	err := writer.Close()
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	return &buf, writer.FormDataContentType(), nil
-}
-
-func addHeaders(req *http.Request, headers []string) {
-	for _, header := range headers {
-		parts := strings.SplitN(header, ":", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			req.Header.Set(key, value)
-		}
-	}
-}
-
-func addQueryParams(req *http.Request, queries []string) {
-	q := req.URL.Query()
-	for _, query := range queries {
-		parts := strings.SplitN(query, "=", 2)
-		if len(parts) == 2 {
-			q.Add(parts[0], parts[1])
-		}
-	}
-	req.URL.RawQuery = q.Encode()
-}
\ No newline at end of file
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// now is replaced in tests to control the clock
+var now = time.Now
+
+// epochThreshold separates reset values given as Unix timestamps from those
+// given as seconds until the reset
+const epochThreshold = 1_000_000_000
+
+// ObserveHeaders tunes the limiter from a response's rate limit headers
+// (X-RateLimit-Remaining and X-RateLimit-Reset, or their unprefixed
+// RateLimit-* equivalents). The remaining requests are spread evenly until
+// the reset, and once none remain requests are held until the reset time.
+// A rate set with --rate is never exceeded. Responses without the headers
+// leave the limiter unchanged.
+func (rl *RateLimiter) ObserveHeaders(h http.Header) {
+	remaining, ok := headerInt(h, "Remaining")
+	if !ok {
+		return
+	}
+	reset, ok := headerInt(h, "Reset")
+	if !ok {
+		return
+	}
+
+	current := now()
+	resetAt := current.Add(time.Duration(reset) * time.Second)
+	if reset >= epochThreshold {
+		resetAt = time.Unix(reset, 0)
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.limiter == nil {
+		rl.limiter = rate.NewLimiter(rate.Inf, 1)
+	}
+	rl.enabled = true
+
+	if remaining <= 0 {
+		rl.resumeAt = resetAt
+		return
+	}
+	rl.resumeAt = time.Time{}
+
+	limit := rate.Inf
+	if window := resetAt.Sub(current); window > 0 {
+		limit = rate.Limit(float64(remaining) / window.Seconds())
+	}
+	if rl.configured > 0 && rl.configured < limit {
+		limit = rl.configured
+	}
+
+	rl.limiter.SetLimit(limit)
+	rl.limiter.SetBurst(1)
+}
+
+// headerInt reads X-RateLimit-<name>, falling back to RateLimit-<name>
+func headerInt(h http.Header, name string) (int64, bool) {
+	value := h.Get("X-RateLimit-" + name)
+	if value == "" {
+		value = h.Get("RateLimit-" + name)
+	}
+	if value == "" {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func setNow(t *testing.T, current time.Time) {
+	t.Helper()
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func rateLimitHeaders(remaining, reset string) http.Header {
+	h := http.Header{}
+	h.Set("X-RateLimit-Limit", "100")
+	h.Set("X-RateLimit-Remaining", remaining)
+	h.Set("X-RateLimit-Reset", reset)
+	return h
+}
+
+func TestObserveHeadersPacesRemainingRequests(t *testing.T) {
+	current := time.Unix(1_700_000_000, 0)
+	setNow(t, current)
+
+	tests := []struct {
+		name     string
+		headers  http.Header
+		expected rate.Limit
+	}{
+		{"Seconds until reset", rateLimitHeaders("10", "5"), 2},
+		{"Unix timestamp reset", rateLimitHeaders("30", strconv.FormatInt(current.Unix()+60, 10)), 0.5},
+		{"Unprefixed headers", http.Header{"Ratelimit-Remaining": {"20"}, "Ratelimit-Reset": {"10"}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, _ := New("")
+			limiter.ObserveHeaders(tt.headers)
+
+			if !limiter.IsEnabled() {
+				t.Fatal("Expected rate limit headers to enable the limiter")
+			}
+			if got := limiter.limiter.Limit(); got != tt.expected {
+				t.Errorf("Expected limit %v/s, got %v/s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestObserveHeadersKeepsConfiguredRate(t *testing.T) {
+	setNow(t, time.Unix(1_700_000_000, 0))
+
+	limiter, _ := New("1/s")
+	limiter.ObserveHeaders(rateLimitHeaders("100", "10"))
+
+	if got := limiter.limiter.Limit(); got != 1 {
+		t.Errorf("Expected configured 1/s to be kept, got %v/s", got)
+	}
+}
+
+func TestObserveHeadersWaitsForReset(t *testing.T) {
+	current := time.Unix(1_700_000_000, 0)
+	setNow(t, current)
+
+	limiter, _ := New("")
+	limiter.ObserveHeaders(rateLimitHeaders("0", "30"))
+
+	stats := limiter.Stats()
+	if resumeAt, _ := stats["resume_at"].(time.Time); !resumeAt.Equal(current.Add(30 * time.Second)) {
+		t.Errorf("Expected to resume at %v, got %v", current.Add(30*time.Second), stats["resume_at"])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Expected Wait to block until the reset")
+	}
+
+	// A later response with requests left lifts the hold
+	limiter.ObserveHeaders(rateLimitHeaders("5", "5"))
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("Expected Wait to proceed after the hold is lifted: %v", err)
+	}
+}
+
+func TestObserveHeadersIgnoresMissingHeaders(t *testing.T) {
+	limiter, _ := New("")
+	limiter.ObserveHeaders(http.Header{"X-Ratelimit-Limit": {"100"}})
+
+	if limiter.IsEnabled() {
+		t.Error("Expected limiter to stay disabled without remaining/reset headers")
+	}
+}
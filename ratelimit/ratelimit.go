@@ -16,6 +16,10 @@ type RateLimiter struct {
 	limiter *rate.Limiter
 	enabled bool
 	mu      sync.RWMutex
+
+	// configured is the --rate limit, which ObserveHeaders never exceeds
+	configured rate.Limit
+	resumeAt   time.Time
 }
 
 // Config holds rate limiting configuration
@@ -37,8 +41,9 @@ func New(rateStr string) (*RateLimiter, error) {
 
 	limiter := rate.NewLimiter(limit, burst)
 	return &RateLimiter{
-		limiter: limiter,
-		enabled: true,
+		limiter:    limiter,
+		enabled:    true,
+		configured: limit,
 	}, nil
 }
 
@@ -113,13 +118,24 @@ func (rl *RateLimiter) Allow() error {
 // Wait blocks until the request can proceed or context is cancelled
 func (rl *RateLimiter) Wait(ctx context.Context) error {
 	rl.mu.RLock()
-	defer rl.mu.RUnlock()
+	enabled, limiter, resumeAt := rl.enabled, rl.limiter, rl.resumeAt
+	rl.mu.RUnlock()
 
-	if !rl.enabled {
+	if !enabled {
 		return nil
 	}
 
-	return rl.limiter.Wait(ctx)
+	if wait := resumeAt.Sub(now()); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return limiter.Wait(ctx)
 }
 
 // SetRate updates the rate limit
@@ -145,6 +161,7 @@ func (rl *RateLimiter) SetRate(rateStr string) error {
 		rl.limiter.SetLimit(limit)
 		rl.limiter.SetBurst(burst)
 	}
+	rl.configured = limit
 	rl.enabled = true
 
 	return nil
@@ -171,5 +188,9 @@ func (rl *RateLimiter) Stats() map[string]any {
 		stats["tokens"] = rl.limiter.Tokens()
 	}
 
+	if !rl.resumeAt.IsZero() {
+		stats["resume_at"] = rl.resumeAt
+	}
+
 	return stats
 }
\ No newline at end of file
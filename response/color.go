@@ -0,0 +1,120 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ANSI colors for the parts of a JSON document
+const (
+	colorReset  = "\x1b[0m"
+	colorKey    = "\x1b[34m"
+	colorString = "\x1b[32m"
+	colorNumber = "\x1b[36m"
+	colorBool   = "\x1b[33m"
+	colorNull   = "\x1b[35m"
+)
+
+// ColorFormatter highlights the JSON syntax in what another formatter
+// produces. Bodies that aren't JSON are passed through unchanged.
+type ColorFormatter struct {
+	formatter Formatter
+}
+
+func NewColorFormatter(formatter Formatter) *ColorFormatter {
+	return &ColorFormatter{formatter: formatter}
+}
+
+func (cf *ColorFormatter) Format(resp *http.Response) ([]byte, error) {
+	body, err := cf.formatter.Format(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "json") || !json.Valid(body) {
+		return body, nil
+	}
+	return ColorizeJSON(body), nil
+}
+
+// ColorizeJSON wraps the keys, strings, numbers and literals of a valid JSON
+// document in ANSI colors, leaving its layout as it is
+func ColorizeJSON(data []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(data) * 2)
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"':
+			end := stringEnd(data, i)
+			color := colorString
+			if isKey(data, end) {
+				color = colorKey
+			}
+			out.WriteString(color)
+			out.Write(data[i:end])
+			out.WriteString(colorReset)
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(data) && strings.IndexByte("0123456789.eE+-", data[end]) >= 0 {
+				end++
+			}
+			out.WriteString(colorNumber)
+			out.Write(data[i:end])
+			out.WriteString(colorReset)
+			i = end
+		case bytes.HasPrefix(data[i:], []byte("true")), bytes.HasPrefix(data[i:], []byte("false")):
+			end := i + 4
+			if c == 'f' {
+				end++
+			}
+			out.WriteString(colorBool)
+			out.Write(data[i:end])
+			out.WriteString(colorReset)
+			i = end
+		case bytes.HasPrefix(data[i:], []byte("null")):
+			out.WriteString(colorNull)
+			out.WriteString("null")
+			out.WriteString(colorReset)
+			i += 4
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.Bytes()
+}
+
+// stringEnd returns the index just past the string starting at data[start]
+func stringEnd(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// isKey reports whether the string ending at end is an object key, i.e.
+// followed by a colon
+func isKey(data []byte, end int) bool {
+	for i := end; i < len(data); i++ {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
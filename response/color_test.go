@@ -0,0 +1,60 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestColorizeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Key and string", `{"a": "b"}`, "{" + colorKey + `"a"` + colorReset + ": " + colorString + `"b"` + colorReset + "}"},
+		{"Escaped quote", `["x\"y"]`, "[" + colorString + `"x\"y"` + colorReset + "]"},
+		{"Numbers", `[1, -2.5e3]`, "[" + colorNumber + "1" + colorReset + ", " + colorNumber + "-2.5e3" + colorReset + "]"},
+		{"Literals", `[true,false,null]`, "[" + colorBool + "true" + colorReset + "," + colorBool + "false" + colorReset + "," + colorNull + "null" + colorReset + "]"},
+		{"Key before newline", "{\n  \"k\"\n  : 1}", "{\n  " + colorKey + `"k"` + colorReset + "\n  : " + colorNumber + "1" + colorReset + "}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := string(ColorizeJSON([]byte(tt.input))); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestColorFormatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		colored     bool
+	}{
+		{"JSON", "application/json", `{"a": 1}`, true},
+		{"Vendor JSON", "application/vnd.api+json; charset=utf-8", `{"a": 1}`, true},
+		{"Invalid JSON", "application/json", `{"a": `, false},
+		{"Plain text", "text/plain", `{"a": 1}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{"Content-Type": {tt.contentType}},
+				Body:   io.NopCloser(strings.NewReader(tt.body)),
+			}
+			result, err := NewColorFormatter(NewRawFormatter()).Format(resp)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if colored := strings.Contains(string(result), "\x1b["); colored != tt.colored {
+				t.Errorf("Expected colored %t, got %q", tt.colored, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// MaskValue replaces every value matched by a MaskTransformer path
+const MaskValue = "***"
+
+// MaskTransformer redacts the values at JSONPath-like paths in JSON bodies,
+// such as "password", "$.user.ssn" or "items[*].token". A "*" segment
+// matches every key or element. Paths that match nothing are ignored, and
+// non-JSON bodies are left untouched.
+type MaskTransformer struct {
+	paths [][]string
+}
+
+func NewMaskTransformer(paths []string) (*MaskTransformer, error) {
+	mt := &MaskTransformer{}
+	for _, path := range paths {
+		segments, err := parseMaskPath(path)
+		if err != nil {
+			return nil, err
+		}
+		mt.paths = append(mt.paths, segments)
+	}
+	return mt, nil
+}
+
+// parseMaskPath splits "$.items[*].token" into ["items", "*", "token"]
+func parseMaskPath(path string) ([]string, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	trimmed = strings.ReplaceAll(trimmed, "[", ".")
+	trimmed = strings.ReplaceAll(trimmed, "]", "")
+
+	segments := strings.Split(trimmed, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid mask path %q (expected e.g. 'user.password' or 'items[*].token')", path)
+		}
+	}
+	return segments, nil
+}
+
+// Transform replaces the masked values in place, so the rest of the body
+// keeps its key order and layout. Newline-delimited JSON is masked record
+// by record.
+func (mt *MaskTransformer) Transform(contentType string, body []byte) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if len(mt.paths) == 0 || !strings.Contains(mediaType, "json") {
+		return body, nil
+	}
+
+	m := &masker{body: body, decoder: json.NewDecoder(bytes.NewReader(body))}
+	for {
+		if err := m.value(mt.paths); err == io.EOF {
+			break
+		} else if err != nil {
+			return body, nil
+		}
+	}
+	if len(m.masked) == 0 {
+		return body, nil
+	}
+
+	replacement, err := json.Marshal(MaskValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mask value: %w", err)
+	}
+	var buf bytes.Buffer
+	last := 0
+	for _, span := range m.masked {
+		buf.Write(body[last:span[0]])
+		buf.Write(replacement)
+		last = span[1]
+	}
+	buf.Write(body[last:])
+	return buf.Bytes(), nil
+}
+
+// masker walks a JSON stream, recording the byte ranges of the values the
+// mask paths match
+type masker struct {
+	body    []byte
+	decoder *json.Decoder
+	masked  [][2]int
+}
+
+// value reads the next value, where paths are the remaining segments of
+// the mask paths that led to it
+func (m *masker) value(paths [][]string) error {
+	start := m.valueStart()
+	for _, path := range paths {
+		if len(path) == 0 {
+			var raw json.RawMessage
+			if err := m.decoder.Decode(&raw); err != nil {
+				return err
+			}
+			m.masked = append(m.masked, [2]int{start, int(m.decoder.InputOffset())})
+			return nil
+		}
+	}
+	if len(paths) == 0 {
+		var raw json.RawMessage
+		return m.decoder.Decode(&raw)
+	}
+
+	token, err := m.decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		for m.decoder.More() {
+			key, err := m.decoder.Token()
+			if err != nil {
+				return err
+			}
+			name, _ := key.(string)
+			if err := m.value(descend(paths, name)); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; m.decoder.More(); i++ {
+			if err := m.value(descend(paths, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	_, err = m.decoder.Token()
+	return err
+}
+
+// valueStart returns the offset of the next value, skipping the white
+// space and separators the decoder hasn't consumed yet
+func (m *masker) valueStart() int {
+	offset := int(m.decoder.InputOffset())
+	for offset < len(m.body) && strings.IndexByte(" \t\r\n,:", m.body[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// descend returns the rest of the paths whose next segment matches key
+func descend(paths [][]string, key string) [][]string {
+	var next [][]string
+	for _, path := range paths {
+		if len(path) > 0 && (path[0] == "*" || path[0] == key) {
+			next = append(next, path[1:])
+		}
+	}
+	return next
+}
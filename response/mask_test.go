@@ -0,0 +1,97 @@
+package response
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskTransformer(t *testing.T) {
+	body := `{"token":"t0p","user":{"name":"ann","ssn":"123-45-6789"},"items":[{"id":1,"key":"k1"},{"id":2,"key":"k2"}]}`
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected string
+	}{
+		{"Top-level field", []string{"token"}, `{"token":"***","user":{"name":"ann","ssn":"123-45-6789"},"items":[{"id":1,"key":"k1"},{"id":2,"key":"k2"}]}`},
+		{"Nested field", []string{"$.user.ssn"}, `{"token":"t0p","user":{"name":"ann","ssn":"***"},"items":[{"id":1,"key":"k1"},{"id":2,"key":"k2"}]}`},
+		{"Every array element", []string{"items[*].key"}, `{"token":"t0p","user":{"name":"ann","ssn":"123-45-6789"},"items":[{"id":1,"key":"***"},{"id":2,"key":"***"}]}`},
+		{"One array element", []string{"items[1].key"}, `{"token":"t0p","user":{"name":"ann","ssn":"123-45-6789"},"items":[{"id":1,"key":"k1"},{"id":2,"key":"***"}]}`},
+		{"Whole object", []string{"user"}, `{"token":"t0p","user":"***","items":[{"id":1,"key":"k1"},{"id":2,"key":"k2"}]}`},
+		{"Missing path ignored", []string{"user.missing.deep", "nope"}, body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt, err := NewMaskTransformer(tt.paths)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := mt.Transform("application/json", []byte(body))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMaskTransformerKeepsIndentation(t *testing.T) {
+	mt, _ := NewMaskTransformer([]string{"secret"})
+	got, err := mt.Transform("application/json", []byte("{\n  \"id\": 12345678901234567890,\n  \"secret\": \"s\"\n}"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "{\n  \"id\": 12345678901234567890,\n  \"secret\": \"***\"\n}"
+	if string(got) != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestMaskTransformerNDJSON(t *testing.T) {
+	mt, _ := NewMaskTransformer([]string{"token"})
+	got, err := mt.Transform("application/x-ndjson", []byte("{\"id\":1,\"token\":\"a\"}\n{\"id\":2,\"token\":\"b\"}\n{\"id\":3}\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "{\"id\":1,\"token\":\"***\"}\n{\"id\":2,\"token\":\"***\"}\n{\"id\":3}\n"
+	if string(got) != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestMaskTransformerKeepsLayout(t *testing.T) {
+	mt, _ := NewMaskTransformer([]string{"b.secret"})
+	body := "{\"z\": 1, \"b\": {\"secret\": [1, 2],\n\t\"a\": \"<x>\"}, \"a\": 2}"
+	got, err := mt.Transform("application/json", []byte(body))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "{\"z\": 1, \"b\": {\"secret\": \"***\",\n\t\"a\": \"<x>\"}, \"a\": 2}"
+	if string(got) != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestMaskTransformerIgnoresNonJSON(t *testing.T) {
+	mt, _ := NewMaskTransformer([]string{"secret"})
+	for _, tt := range []struct{ contentType, body string }{
+		{"text/plain", `{"secret":"s"}`},
+		{"application/json", "not json"},
+	} {
+		got, err := mt.Transform(tt.contentType, []byte(tt.body))
+		if err != nil || string(got) != tt.body {
+			t.Errorf("Expected %q to be untouched, got %q (%v)", tt.body, got, err)
+		}
+	}
+}
+
+func TestMaskPathValidation(t *testing.T) {
+	for _, path := range []string{"", "a..b", "$."} {
+		if _, err := NewMaskTransformer([]string{path}); err == nil || !strings.Contains(err.Error(), "invalid mask path") {
+			t.Errorf("Expected path %q to be rejected, got: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package response
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+)
+
+// Names of the built-in newline transformers in the default registry
+const (
+	TrimTrailingNewline   = "trim-trailing-newline"
+	EnsureTrailingNewline = "ensure-trailing-newline"
+)
+
+func init() {
+	Register(TrimTrailingNewline, &NewlineTransformer{})
+	Register(EnsureTrailingNewline, &NewlineTransformer{Ensure: true})
+}
+
+// NewlineTransformer strips every trailing newline from textual bodies and,
+// with Ensure set, ends them with exactly one. Other bodies are untouched.
+type NewlineTransformer struct {
+	Ensure bool
+}
+
+func (nt *NewlineTransformer) Transform(contentType string, body []byte) ([]byte, error) {
+	if !IsText(contentType) {
+		return body, nil
+	}
+
+	trimmed := bytes.TrimRight(body, "\r\n")
+	if !nt.Ensure {
+		return trimmed, nil
+	}
+	return append(trimmed[:len(trimmed):len(trimmed)], '\n'), nil
+}
+
+// IsText reports whether a Content-Type describes human-readable text
+func IsText(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-www-form-urlencoded", "application/yaml", "application/x-ndjson":
+		return true
+	}
+	return false
+}
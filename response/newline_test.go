@@ -0,0 +1,41 @@
+package response
+
+import "testing"
+
+func TestNewlineTransformer(t *testing.T) {
+	tests := []struct {
+		name        string
+		ensure      bool
+		contentType string
+		body        string
+		expected    string
+	}{
+		{"Trim extra newline", false, "text/plain", "hello\n\n", "hello"},
+		{"Trim CRLF", false, "text/plain; charset=utf-8", "hello\r\n", "hello"},
+		{"Trim without newline", false, "text/plain", "hello", "hello"},
+		{"Ensure adds missing newline", true, "application/json", `{"a":1}`, "{\"a\":1}\n"},
+		{"Ensure collapses extras", true, "text/csv", "a,b\n\n\n", "a,b\n"},
+		{"Ensure keeps single newline", true, "text/plain", "hello\n", "hello\n"},
+		{"Binary untouched", true, "application/octet-stream", "bin\n\n", "bin\n\n"},
+		{"Missing type untouched", false, "", "hello\n", "hello\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := &NewlineTransformer{Ensure: tt.ensure}
+			got, err := transformer.Transform(tt.contentType, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewlineTransformersRegistered(t *testing.T) {
+	if _, err := Lookup(TrimTrailingNewline, EnsureTrailingNewline); err != nil {
+		t.Errorf("Expected built-in newline transformers to be registered: %v", err)
+	}
+}
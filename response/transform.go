@@ -0,0 +1,120 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Transformer post-processes a response body after it has been formatted.
+// contentType is the response's Content-Type header.
+type Transformer interface {
+	Transform(contentType string, body []byte) ([]byte, error)
+}
+
+// TransformerFunc adapts a plain function to the Transformer interface
+type TransformerFunc func(contentType string, body []byte) ([]byte, error)
+
+func (f TransformerFunc) Transform(contentType string, body []byte) ([]byte, error) {
+	return f(contentType, body)
+}
+
+// Registry maps names to transformers so they can be selected at runtime
+type Registry struct {
+	mu           sync.RWMutex
+	transformers map[string]Transformer
+}
+
+func NewRegistry() *Registry {
+	return &Registry{transformers: make(map[string]Transformer)}
+}
+
+// Register adds a transformer under name, failing if the name is taken
+func (r *Registry) Register(name string, t Transformer) error {
+	if name == "" {
+		return fmt.Errorf("transformer name is empty")
+	}
+	if t == nil {
+		return fmt.Errorf("transformer %q is nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.transformers[name]; exists {
+		return fmt.Errorf("transformer %q is already registered", name)
+	}
+	r.transformers[name] = t
+	return nil
+}
+
+// Lookup returns the transformers registered under names, in that order
+func (r *Registry) Lookup(names ...string) ([]Transformer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transformers := make([]Transformer, 0, len(names))
+	for _, name := range names {
+		t, ok := r.transformers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transformer %q", name)
+		}
+		transformers = append(transformers, t)
+	}
+	return transformers, nil
+}
+
+// Names returns the registered names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.transformers))
+	for name := range r.transformers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register adds a transformer to the default registry
+func Register(name string, t Transformer) error {
+	return defaultRegistry.Register(name, t)
+}
+
+// Lookup resolves names against the default registry
+func Lookup(names ...string) ([]Transformer, error) {
+	return defaultRegistry.Lookup(names...)
+}
+
+// TransformingFormatter runs a formatter and then each transformer in order,
+// feeding every step the previous step's output
+type TransformingFormatter struct {
+	formatter    Formatter
+	transformers []Transformer
+}
+
+func NewTransformingFormatter(formatter Formatter, transformers ...Transformer) *TransformingFormatter {
+	return &TransformingFormatter{
+		formatter:    formatter,
+		transformers: transformers,
+	}
+}
+
+func (tf *TransformingFormatter) Format(resp *http.Response) ([]byte, error) {
+	body, err := tf.formatter.Format(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	for i, t := range tf.transformers {
+		if body, err = t.Transform(contentType, body); err != nil {
+			return nil, fmt.Errorf("transformer %d failed: %w", i+1, err)
+		}
+	}
+	return body, nil
+}
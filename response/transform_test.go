@@ -0,0 +1,87 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newResponse(contentType, body string) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", contentType)
+	return &http.Response{Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestTransformersRunAfterFormatterInOrder(t *testing.T) {
+	registry := NewRegistry()
+
+	var seenType string
+	upper := TransformerFunc(func(contentType string, body []byte) ([]byte, error) {
+		seenType = contentType
+		return bytes.ToUpper(body), nil
+	})
+	suffix := TransformerFunc(func(contentType string, body []byte) ([]byte, error) {
+		return append(body, "!"...), nil
+	})
+
+	if err := registry.Register("upper", upper); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := registry.Register("suffix", suffix); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	transformers, err := registry.Lookup("upper", "suffix")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	formatter := NewTransformingFormatter(NewPrettyFormatter(), transformers...)
+	got, err := formatter.Format(newResponse("application/json", `{"a":"b"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "{\n  \"A\": \"B\"\n}!"
+	if string(got) != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if seenType != "application/json" {
+		t.Errorf("Expected transformer to see the Content-Type, got %q", seenType)
+	}
+}
+
+func TestTransformerError(t *testing.T) {
+	failing := TransformerFunc(func(contentType string, body []byte) ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+
+	formatter := NewTransformingFormatter(NewRawFormatter(), failing)
+	if _, err := formatter.Format(newResponse("text/plain", "hi")); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected transformer error, got: %v", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	noop := TransformerFunc(func(contentType string, body []byte) ([]byte, error) { return body, nil })
+
+	if err := registry.Register("noop", noop); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := registry.Register("noop", noop); err == nil {
+		t.Error("Expected duplicate name to be rejected")
+	}
+	if err := registry.Register("", noop); err == nil {
+		t.Error("Expected empty name to be rejected")
+	}
+	if _, err := registry.Lookup("missing"); err == nil {
+		t.Error("Expected unknown name to be rejected")
+	}
+	if names := registry.Names(); len(names) != 1 || names[0] != "noop" {
+		t.Errorf("Expected [noop], got %v", names)
+	}
+}
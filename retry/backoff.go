@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Jitter is how a retry delay is randomized, following
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type Jitter string
+
+const (
+	JitterNone  Jitter = "none"
+	JitterFull  Jitter = "full"
+	JitterEqual Jitter = "equal"
+)
+
+func (j *Jitter) String() string {
+	return string(*j)
+}
+
+func (j *Jitter) Set(value string) error {
+	switch Jitter(value) {
+	case JitterNone, JitterFull, JitterEqual:
+		*j = Jitter(value)
+		return nil
+	}
+	return fmt.Errorf("unknown jitter strategy %q (use none, full or equal)", value)
+}
+
+// Delay returns how long to wait before retry attempt (counting from
+// 1). The backoff doubles from base up to max; full jitter picks a delay in
+// [0, backoff) and equal jitter one in [backoff/2, backoff).
+func Delay(attempt int, base, max time.Duration, jitter Jitter, rnd *rand.Rand) time.Duration {
+	backoff := base
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	switch jitter {
+	case JitterFull:
+		return time.Duration(rnd.Int63n(int64(backoff)))
+	case JitterEqual:
+		half := backoff / 2
+		return half + time.Duration(rnd.Int63n(int64(backoff-half)))
+	default:
+		return backoff
+	}
+}
@@ -0,0 +1,64 @@
+package retry
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	base, max := 100*time.Millisecond, time.Second
+	backoffs := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+
+	tests := []struct {
+		jitter Jitter
+		lower  func(backoff time.Duration) time.Duration
+	}{
+		{JitterNone, func(backoff time.Duration) time.Duration { return backoff }},
+		{JitterFull, func(backoff time.Duration) time.Duration { return 0 }},
+		{JitterEqual, func(backoff time.Duration) time.Duration { return backoff / 2 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.jitter), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			for i, backoff := range backoffs {
+				attempt := i + 1
+				for n := 0; n < 100; n++ {
+					delay := Delay(attempt, base, max, tt.jitter, rnd)
+					if delay < tt.lower(backoff) || delay > backoff || (tt.jitter != JitterNone && delay == backoff) {
+						t.Fatalf("Attempt %d: delay %s outside [%s, %s)", attempt, delay, tt.lower(backoff), backoff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDelayIsRandomized(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	seen := make(map[time.Duration]bool)
+	for n := 0; n < 10; n++ {
+		seen[Delay(3, 100*time.Millisecond, time.Second, JitterFull, rnd)] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Expected full jitter to vary the delay")
+	}
+}
+
+func TestJitterValidation(t *testing.T) {
+	var jitter Jitter
+	if err := jitter.Set("sometimes"); err == nil {
+		t.Error("Expected unknown strategy to be rejected")
+	}
+	if err := jitter.Set("equal"); err != nil || jitter != JitterEqual {
+		t.Errorf("Expected equal to be accepted, got %q: %v", jitter, err)
+	}
+}
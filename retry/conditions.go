@@ -0,0 +1,78 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultConditions is the --retry-on default
+const DefaultConditions = "5xx,429,connection"
+
+// Conditions is the set of failures that are retried, parsed from a comma
+// separated list of status codes (429), status classes (5xx) and
+// "connection" for requests that never got a response
+type Conditions struct {
+	raw        string
+	statuses   map[int]bool
+	classes    map[int]bool
+	Connection bool
+}
+
+// ParseConditions parses a list like "5xx,429,connection"
+func ParseConditions(value string) (Conditions, error) {
+	c := Conditions{raw: value, statuses: map[int]bool{}, classes: map[int]bool{}}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		switch {
+		case item == "connection":
+			c.Connection = true
+		case len(item) == 3 && strings.HasSuffix(item, "xx") && item[0] >= '1' && item[0] <= '5':
+			c.classes[int(item[0]-'0')] = true
+		default:
+			code, err := strconv.Atoi(item)
+			if err != nil || code < 100 || code > 599 {
+				return Conditions{}, fmt.Errorf("unknown retry condition %q (use a status like 429, a class like 5xx, or connection)", item)
+			}
+			c.statuses[code] = true
+		}
+	}
+	return c, nil
+}
+
+func (c *Conditions) String() string {
+	return c.raw
+}
+
+func (c *Conditions) Set(value string) error {
+	parsed, err := ParseConditions(value)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// Status reports whether a response with this status code is retried
+func (c Conditions) Status(code int) bool {
+	return c.statuses[code] || c.classes[code/100]
+}
+
+// Error reports whether a failed request is retried: only failures to
+// connect or of the connection itself count, not timeouts or local errors
+func (c Conditions) Error(err error) bool {
+	if !c.Connection || err == nil {
+		return false
+	}
+
+	var opErr *net.OpError
+	return (errors.As(err, &opErr) && !opErr.Timeout()) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
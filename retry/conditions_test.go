@@ -0,0 +1,64 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestParseConditions(t *testing.T) {
+	c, err := ParseConditions(DefaultConditions)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	statuses := map[int]bool{200: false, 404: false, 429: true, 500: true, 503: true, 599: true}
+	for code, expected := range statuses {
+		if got := c.Status(code); got != expected {
+			t.Errorf("Status(%d): expected %t, got %t", code, expected, got)
+		}
+	}
+	if !c.Connection {
+		t.Error("Expected connection failures to be retried")
+	}
+
+	for _, invalid := range []string{"", "6xx", "42", "sometimes", "5xx,"} {
+		if _, err := ParseConditions(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestConditionsError(t *testing.T) {
+	c, _ := ParseConditions("connection")
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Refused", fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), true},
+		{"Reset", syscall.ECONNRESET, true},
+		{"Dial timeout", fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}), false},
+		{"Read timeout", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, false},
+		{"Dropped mid-body", io.ErrUnexpectedEOF, true},
+		{"Other errors", errors.New("unsupported protocol scheme"), false},
+		{"No error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Error(tt.err); got != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+
+	statusOnly, _ := ParseConditions("5xx")
+	if statusOnly.Error(syscall.ECONNREFUSED) {
+		t.Error("Expected connection failures to be ignored without the connection condition")
+	}
+}
@@ -0,0 +1,37 @@
+package retry
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"Seconds", "120", 2 * time.Minute, true},
+		{"HTTP date", now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{"Date in the past", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"Missing", "", 0, false},
+		{"Negative", "-5", 0, false},
+		{"Invalid", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.value != "" {
+				h.Set("Retry-After", tt.value)
+			}
+			got, ok := RetryAfter(h, now)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("Expected (%s, %t), got (%s, %t)", tt.expected, tt.ok, got, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// AcceptEncoding lists every content coding Decompress can decode
+const AcceptEncoding = "gzip, deflate, br, zstd"
+
+// decompressTransport decodes compressed responses. Unlike net/http's own
+// gzip handling it covers brotli and zstd too, and leaves Content-Encoding
+// and Content-Length in the headers so they still show what was sent.
+type decompressTransport struct {
+	base http.RoundTripper
+}
+
+// Decompress wraps base so response bodies in a Content-Encoding it knows
+// are decoded as they are read. Bodies in any other coding are left as is.
+func Decompress(base http.RoundTripper) http.RoundTripper {
+	return &decompressTransport{base: base}
+}
+
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	encodings, ok := parseEncodings(resp.Header.Get("Content-Encoding"))
+	if !ok || len(encodings) == 0 {
+		return resp, nil
+	}
+	resp.Body = &decodingBody{body: resp.Body, encodings: encodings}
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// parseEncodings splits a Content-Encoding value into the codings to undo,
+// dropping identity. ok is false when one of them isn't supported.
+func parseEncodings(value string) (encodings []string, ok bool) {
+	for _, coding := range strings.Split(value, ",") {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		switch coding {
+		case "", "identity":
+		case "gzip", "x-gzip", "deflate", "br", "zstd":
+			encodings = append(encodings, coding)
+		default:
+			return nil, false
+		}
+	}
+	return encodings, true
+}
+
+// decodingBody sets up its decoders on the first Read, so an empty body,
+// e.g. the answer to a HEAD request, reads as EOF rather than failing on a
+// missing gzip header
+type decodingBody struct {
+	body      io.ReadCloser
+	encodings []string
+	reader    io.Reader
+	closers   []func()
+	err       error
+}
+
+func (d *decodingBody) Read(p []byte) (int, error) {
+	if d.reader == nil && d.err == nil {
+		d.reader, d.err = d.decoders()
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.reader.Read(p)
+}
+
+// decoders chains a decoder per coding, undoing the last one applied first
+func (d *decodingBody) decoders() (io.Reader, error) {
+	var r io.Reader = d.body
+	for i := len(d.encodings) - 1; i >= 0; i-- {
+		switch d.encodings[i] {
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			r = gz
+		case "deflate":
+			r = newDeflateReader(r)
+		case "br":
+			r = brotli.NewReader(r)
+		case "zstd":
+			zr, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			d.closers = append(d.closers, zr.Close)
+			r = zr
+		}
+	}
+	return r, nil
+}
+
+func (d *decodingBody) Close() error {
+	for _, close := range d.closers {
+		close()
+	}
+	return d.body.Close()
+}
+
+// newDeflateReader reads "deflate" bodies, which are meant to be zlib
+// streams but are sent as raw deflate by some servers
+func newDeflateReader(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		if zr, err := zlib.NewReader(buffered); err == nil {
+			return zr
+		}
+	}
+	return flate.NewReader(buffered)
+}
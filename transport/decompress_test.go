@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = zw
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	plain := []byte(strings.Repeat("hello, compressed world\n", 50))
+	gzipped := compress(t, "gzip", plain)
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		expected []byte
+	}{
+		{"Gzip", "gzip", gzipped, plain},
+		{"Deflate", "deflate", compress(t, "deflate", plain), plain},
+		{"Raw deflate", "deflate", compress(t, "raw-deflate", plain), plain},
+		{"Brotli", "br", compress(t, "br", plain), plain},
+		{"Zstd", "zstd", compress(t, "zstd", plain), plain},
+		{"Stacked", "gzip, zstd", compress(t, "zstd", gzipped), plain},
+		{"Identity", "identity", plain, plain},
+		{"Unknown coding", "compress", []byte("raw"), []byte("raw")},
+		{"Empty body", "gzip", nil, []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", tt.encoding)
+				w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			req.Header.Set("Accept-Encoding", AcceptEncoding)
+			resp, err := Decompress(http.DefaultTransport).RoundTrip(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if !bytes.Equal(body, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, body)
+			}
+			if resp.Header.Get("Content-Encoding") != tt.encoding {
+				t.Errorf("Expected Content-Encoding %q to be kept, got %q", tt.encoding, resp.Header.Get("Content-Encoding"))
+			}
+		})
+	}
+}
+
+func TestDecompressCorruptBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzip"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := Decompress(http.DefaultTransport).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Error("Expected an error for a corrupt gzip body")
+	}
+}
@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Proxy returns the Transport.Proxy function for a --proxy URL, falling
+// back to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+// when it's empty. Hosts matching noProxy, a comma-separated list like
+// NO_PROXY, are always reached directly.
+func Proxy(proxyURL, noProxy string) (func(*http.Request) (*url.URL, error), error) {
+	proxy := http.ProxyFromEnvironment
+	if proxyURL != "" {
+		u, err := ParseProxyURL(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	if noProxy == "" {
+		return proxy, nil
+	}
+	bypass := strings.Split(noProxy, ",")
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), bypass) {
+			return nil, nil
+		}
+		return proxy(req)
+	}, nil
+}
+
+// ParseProxyURL parses an http, https, socks5 or socks5h proxy URL. A bare
+// host:port is taken as an HTTP proxy.
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https, socks5 or socks5h)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", rawURL)
+	}
+	return u, nil
+}
+
+// bypassProxy reports whether host matches a NO_PROXY style entry: "*"
+// for every host, an IP address or CIDR range, or a domain that also
+// covers its subdomains (with or without a leading dot)
+func bypassProxy(host string, entries []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case entry == "*":
+			return true
+		case ip != nil:
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+				return true
+			}
+			if entryIP := net.ParseIP(entry); entryIP != nil && entryIP.Equal(ip) {
+				return true
+			}
+		default:
+			domain := strings.TrimPrefix(entry, ".")
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
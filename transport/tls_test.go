@@ -0,0 +1,137 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKeyPair writes a self-signed certificate and its key as PEM files
+func writeKeyPair(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir)
+
+	combined := filepath.Join(dir, "combined.pem")
+	certPEM, _ := os.ReadFile(certFile)
+	keyPEM, _ := os.ReadFile(keyFile)
+	os.WriteFile(combined, append(certPEM, keyPEM...), 0600)
+
+	config, err := TLSConfig(TLSOptions{})
+	if err != nil || config != nil {
+		t.Errorf("Expected no config for zero options, got %v: %v", config, err)
+	}
+
+	config, err = TLSConfig(TLSOptions{
+		Insecure:     true,
+		CAFile:       certFile,
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		MinVersion:   "1.2",
+		MaxVersion:   "1.3",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.InsecureSkipVerify || config.RootCAs == nil || len(config.Certificates) != 1 {
+		t.Errorf("Expected insecure, CA pool and client certificate, got %+v", config)
+	}
+	if config.MinVersion != tls.VersionTLS12 || config.MaxVersion != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.2-1.3, got %x-%x", config.MinVersion, config.MaxVersion)
+	}
+	if len(config.CipherSuites) != 1 || config.CipherSuites[0] != tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("Unexpected cipher suites %v", config.CipherSuites)
+	}
+
+	config, err = TLSConfig(TLSOptions{CertFile: combined})
+	if err != nil || len(config.Certificates) != 1 {
+		t.Errorf("Expected the key to be read from the certificate file: %v", err)
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	os.WriteFile(notPEM, []byte("hello"), 0600)
+
+	tests := []struct {
+		name string
+		opts TLSOptions
+	}{
+		{"Missing CA file", TLSOptions{CAFile: filepath.Join(dir, "missing.pem")}},
+		{"CA file without certificates", TLSOptions{CAFile: notPEM}},
+		{"Key without certificate", TLSOptions{KeyFile: keyFile}},
+		{"Certificate without key", TLSOptions{CertFile: certFile}},
+		{"Unknown version", TLSOptions{MinVersion: "1.4"}},
+		{"Inverted versions", TLSOptions{MinVersion: "1.3", MaxVersion: "1.2"}},
+		{"Unknown cipher", TLSOptions{CipherSuites: []string{"TLS_MADE_UP"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TLSConfig(tt.opts); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]uint16{
+		"":       0,
+		"1.0":    tls.VersionTLS10,
+		"1.1":    tls.VersionTLS11,
+		"1.2":    tls.VersionTLS12,
+		"TLS1.3": tls.VersionTLS13,
+	}
+	for input, expected := range tests {
+		if got, err := ParseVersion(input); err != nil || got != expected {
+			t.Errorf("ParseVersion(%q): expected %x, got %x: %v", input, expected, got, err)
+		}
+	}
+}
+
+func TestParseCipherSuitesAcceptsInsecureByName(t *testing.T) {
+	ids, err := ParseCipherSuites([]string{"tls_rsa_with_aes_128_cbc_sha256"})
+	if err != nil || len(ids) != 1 || ids[0] != tls.TLS_RSA_WITH_AES_128_CBC_SHA256 {
+		t.Errorf("Expected an insecure suite to be accepted by name, got %v: %v", ids, err)
+	}
+}
@@ -0,0 +1,16 @@
+package transport
+
+import (
+	"context"
+	"net"
+)
+
+// UnixDialer returns a DialContext that connects to the Unix socket at path
+// whatever address is asked for, so the URL only supplies the Host header
+// and path
+func UnixDialer(path string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", path)
+	}
+}
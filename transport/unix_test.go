@@ -0,0 +1,32 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnixDialer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan struct{})
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+			close(accepted)
+		}
+	}()
+
+	conn, err := UnixDialer(path)(context.Background(), "tcp", "ignored.example:80")
+	if err != nil {
+		t.Fatalf("Failed to dial socket: %v", err)
+	}
+	conn.Close()
+	<-accepted
+}